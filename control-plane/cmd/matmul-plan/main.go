@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ml-raft-control-plane/internal/api"
+	"ml-raft-control-plane/internal/planner"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// submitTimeout bounds how long submitting the job may take
+const submitTimeout = 30 * time.Second
+
+func main() {
+	var spec planner.MatMulSpec
+	flag.StringVar(&spec.Name, "name", "", "job name, prefixing every task ID")
+	flag.IntVar(&spec.M, "m", 0, "rows of A and C")
+	flag.IntVar(&spec.K, "k", 0, "columns of A and rows of B")
+	flag.IntVar(&spec.N, "n", 0, "columns of B and C")
+	flag.IntVar(&spec.BlockSize, "block", 0, "edge length of a square block")
+	flag.StringVar(&spec.InputA, "a", "", "URI of A")
+	flag.StringVar(&spec.InputB, "b", "", "URI of B")
+	flag.StringVar(&spec.Output, "output", "", "URI prefix for partial products and C")
+	flag.Float64Var(&spec.Tolerance, "tolerance", 0, "allowed relative error of column-sum checksums (default 1e-6)")
+	maxAttempts := flag.Int("max-attempts", 0, "attempts per task before it dead-letters; 0 disables retries")
+	server := flag.String("server", "localhost:50051", "gRPC address of a control-plane node")
+	dryRun := flag.Bool("dry-run", false, "print the planned tasks instead of submitting them")
+	flag.Parse()
+
+	if *maxAttempts > 0 {
+		spec.RetryPolicy = &pb.RetryPolicy{MaxAttempts: int32(*maxAttempts)}
+	}
+
+	if err := run(spec, *server, *dryRun); err != nil {
+		log.Fatalf("matmul-plan: %v", err)
+	}
+}
+
+// run plans the job and either prints it or submits it
+func run(spec planner.MatMulSpec, server string, dryRun bool) error {
+	job, err := planner.PlanMatMul(spec)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		return printPlan(job)
+	}
+	if size := proto.Size(job); size > api.MaxBatchBytes {
+		return fmt.Errorf("plan of %d tasks is %d bytes, more than the %d a job may have; use a larger block size",
+			len(job.Tasks), size, api.MaxBatchBytes)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), submitTimeout)
+	defer cancel()
+
+	resp, err := submit(ctx, server, job)
+	if err != nil {
+		return err
+	}
+	// A follower in redirect mode names the leader instead of forwarding
+	if !resp.Success && resp.LeaderAddress != "" {
+		resp, err = submit(ctx, resp.LeaderAddress, job)
+		if err != nil {
+			return err
+		}
+	}
+	if !resp.Success {
+		return fmt.Errorf("job rejected: %s", resp.ErrorMessage)
+	}
+
+	fmt.Printf("submitted job %s with %d tasks\n", resp.JobId, len(resp.TaskIds))
+	return nil
+}
+
+// submit sends the job to the node at addr
+func submit(ctx context.Context, addr string, job *pb.SubmitJobRequest) (*pb.SubmitJobResponse, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	resp, err := pb.NewJobServiceClient(conn).SubmitJob(ctx, job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit job to %s: %w", addr, err)
+	}
+	return resp, nil
+}
+
+// printPlan writes one line per planned task to stdout
+func printPlan(job *pb.SubmitJobRequest) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK ID\tTYPE\tDEPENDS ON\tTASK DATA")
+	for _, task := range job.Tasks {
+		dependsOn := "-"
+		if len(task.DependsOn) > 0 {
+			dependsOn = strings.Join(task.DependsOn, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", task.TaskId, task.TaskType, dependsOn, task.TaskData)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d tasks in job %s\n", len(job.Tasks), job.Name)
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"ml-raft-control-plane/internal/api"
+	"ml-raft-control-plane/internal/raft"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// shutdownTimeout bounds how long in-flight RPCs may drain on shutdown
+	shutdownTimeout = 10 * time.Second
+
+	// publishInterval is how often an unpublished node retries publishing its metadata
+	publishInterval = 2 * time.Second
+)
+
+func main() {
+	configPath := flag.String("config", "config/node_config.json", "path to node configuration file")
+	flag.Parse()
+
+	if err := run(*configPath); err != nil {
+		log.Fatalf("raft-node: %v", err)
+	}
+}
+
+// run starts the Raft cluster and gRPC server and blocks until a shutdown signal arrives
+func run(configPath string) error {
+	// Handle signals for graceful shutdown, including while waiting to bootstrap
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Load configuration
+	nodeConfig, err := raft.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	clusterConfig, err := nodeConfig.ToClusterConfig()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	forwardMode, err := api.ParseForwardMode(nodeConfig.GRPC.LeaderForwarding)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Initialize Raft cluster
+	cluster, err := raft.NewRaftCluster(clusterConfig)
+	if err != nil {
+		return fmt.Errorf("failed to start raft cluster: %w", err)
+	}
+	log.Printf("Raft node %s started on %s", nodeConfig.NodeID, nodeConfig.BindAddress)
+
+	// Start gRPC server
+	listener, err := net.Listen("tcp", grpcListenAddress(nodeConfig))
+	if err != nil {
+		cluster.Shutdown()
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(api.MaxRecvMsgSize)}
+	if nodeConfig.GRPC.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(nodeConfig.GRPC.MaxConcurrentStreams)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	apiServer := api.NewServer(cluster, api.Config{
+		GRPCPort:    nodeConfig.GRPC.Port,
+		ForwardMode: forwardMode,
+	})
+	apiServer.Register(grpcServer)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("gRPC server listening on %s", listener.Addr())
+		serveErr <- grpcServer.Serve(listener)
+	}()
+
+	// Bootstrap once the expected peers are reachable and confirm their IDs
+	if err := cluster.Bootstrap(ctx, api.ProbePeer); err != nil {
+		stopGRPC(grpcServer)
+		apiServer.Close()
+		cluster.Shutdown()
+		return fmt.Errorf("failed to bootstrap cluster: %w", err)
+	}
+
+	// Publish this node's gRPC address, cloud and region to the cluster
+	go apiServer.PublishServerInfo(ctx, publishInterval)
+
+	// Leader-only background loops
+	go cluster.RunRetryLoop(ctx)
+	go cluster.RunFailureDetector(ctx)
+	go cluster.RunProgressFlusher(ctx)
+	go cluster.RunPlacementLoop(ctx)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		log.Printf("Received shutdown signal, shutting down")
+	case err := <-serveErr:
+		runErr = fmt.Errorf("gRPC server stopped: %w", err)
+	}
+
+	stop()
+	stopGRPC(grpcServer)
+	apiServer.Close()
+
+	if err := cluster.Shutdown(); err != nil && runErr == nil {
+		runErr = err
+	}
+
+	log.Printf("Raft node %s stopped", nodeConfig.NodeID)
+	return runErr
+}
+
+// grpcListenAddress returns the gRPC listen address on the same host as the Raft bind address
+func grpcListenAddress(nodeConfig *raft.NodeConfig) string {
+	host, _, err := net.SplitHostPort(nodeConfig.BindAddress)
+	if err != nil {
+		host = ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(nodeConfig.GRPC.Port))
+}
+
+// stopGRPC drains in-flight RPCs, forcing a stop if they take too long
+func stopGRPC(grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		log.Printf("gRPC graceful stop timed out, forcing stop")
+		grpcServer.Stop()
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"ml-raft-control-plane/internal/raft"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// peerProbeTimeout bounds a single identity probe against a peer
+const peerProbeTimeout = 3 * time.Second
+
+// RegisterServer commits control-plane server metadata to replicated state.
+// Followers always proxy this internal RPC to the leader, even in redirect mode.
+func (s *Server) RegisterServer(ctx context.Context, req *pb.RegisterServerRequest) (*pb.RegisterServerResponse, error) {
+	if req.Server == nil || req.Server.ServerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "server.server_id is required")
+	}
+	if req.Server.GrpcAddress == "" {
+		return nil, status.Error(codes.InvalidArgument, "server.grpc_address is required")
+	}
+
+	if !s.cluster.IsLeader() {
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewClusterServiceClient(conn).RegisterServer(forwardCtx, req)
+	}
+
+	entry := raft.RegisterServerEntry{
+		ServerID:      req.Server.ServerId,
+		RaftAddress:   req.Server.RaftAddress,
+		GRPCAddress:   req.Server.GrpcAddress,
+		CloudProvider: req.Server.CloudProvider,
+		Region:        req.Server.Region,
+		RegisteredAt:  time.Now().Unix(),
+	}
+
+	if err := s.apply(ctx, raft.LogEntryRegisterServer, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.RegisterServerResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// GetServerInfo returns this server's own metadata. It is answered locally and
+// used by peers to confirm server identity before bootstrapping.
+func (s *Server) GetServerInfo(ctx context.Context, req *pb.GetServerInfoRequest) (*pb.GetServerInfoResponse, error) {
+	return &pb.GetServerInfoResponse{
+		Server: s.cluster.LocalServerInfo(),
+	}, nil
+}
+
+// ProbePeer asks a peer's ClusterService for the server ID it reports for itself
+func ProbePeer(ctx context.Context, peer raft.Peer) (string, error) {
+	conn, err := grpc.NewClient(peer.GRPCAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	probeCtx, cancel := context.WithTimeout(ctx, peerProbeTimeout)
+	defer cancel()
+
+	resp, err := pb.NewClusterServiceClient(conn).GetServerInfo(probeCtx, &pb.GetServerInfoRequest{})
+	if err != nil {
+		return "", err
+	}
+	return resp.Server.GetServerId(), nil
+}
+
+// PublishServerInfo registers this node's metadata in replicated state,
+// retrying every interval until it is committed or ctx is cancelled
+func (s *Server) PublishServerInfo(ctx context.Context, interval time.Duration) {
+	local := s.cluster.LocalServerInfo()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if published, found := s.cluster.LookupServer(local.ServerId); found && sameServerInfo(published, local) {
+			return
+		}
+
+		if s.cluster.GetLeader() != "" {
+			publishCtx, cancel := context.WithTimeout(ctx, interval)
+			_, err := s.RegisterServer(publishCtx, &pb.RegisterServerRequest{Server: local})
+			cancel()
+			if err != nil {
+				log.Printf("Failed to publish server metadata: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sameServerInfo reports whether two server descriptions match, ignoring registration time
+func sameServerInfo(a, b *pb.ServerInfo) bool {
+	return a.ServerId == b.ServerId &&
+		a.RaftAddress == b.RaftAddress &&
+		a.GrpcAddress == b.GrpcAddress &&
+		a.CloudProvider == b.CloudProvider &&
+		a.Region == b.Region
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+)
+
+func TestPublishServerInfo_ResolvesLeaderFromFollower(t *testing.T) {
+	leader, follower := setupLeaderFollower(t, ForwardProxy)
+
+	info, found := follower.cluster.GetLeaderInfo()
+	if !found {
+		t.Fatal("follower could not resolve leader metadata")
+	}
+
+	want := leader.cluster.LocalServerInfo()
+	if !sameServerInfo(info, want) {
+		t.Errorf("expected leader metadata %v, got %v", want, info)
+	}
+
+	addr, err := follower.leaderGRPCAddress()
+	if err != nil {
+		t.Fatalf("leaderGRPCAddress() returned error: %v", err)
+	}
+	if addr != want.GrpcAddress {
+		t.Errorf("expected leader gRPC address %s, got %s", want.GrpcAddress, addr)
+	}
+
+	self, found := follower.cluster.LookupServer("follower")
+	if !found {
+		t.Fatal("follower metadata was not replicated")
+	}
+	if self.Region != "127.0.0.2" || self.CloudProvider != "local" {
+		t.Errorf("unexpected follower metadata: %v", self)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ml-raft-control-plane/internal/raft"
+
+	hashiraft "github.com/hashicorp/raft"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultApplyTimeout is used when the caller did not set a deadline
+const defaultApplyTimeout = 5 * time.Second
+
+// applyTimeout derives the Raft apply timeout from the request deadline
+func applyTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining > 0 {
+			return remaining
+		}
+	}
+	return defaultApplyTimeout
+}
+
+// toStatusError converts a cluster error into a gRPC status error
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, raft.ErrUnknownTask),
+		errors.Is(err, raft.ErrUnknownJob):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, raft.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, raft.ErrUnknownNode),
+		errors.Is(err, raft.ErrInvalidTransition),
+		errors.Is(err, raft.ErrStaleFencingToken),
+		errors.Is(err, raft.ErrLeaseActive),
+		errors.Is(err, raft.ErrAffinityMismatch),
+		errors.Is(err, raft.ErrStaleNodeFailure):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, raft.ErrInsufficientResources):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, raft.ErrUnknownStatus):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, hashiraft.ErrNotLeader),
+		errors.Is(err, hashiraft.ErrLeadershipLost),
+		errors.Is(err, hashiraft.ErrRaftShutdown):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, hashiraft.ErrEnqueueTimeout),
+		errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
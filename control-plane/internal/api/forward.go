@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ForwardMode controls how a follower handles write RPCs
+type ForwardMode string
+
+const (
+	// ForwardProxy transparently proxies write RPCs to the leader
+	ForwardProxy ForwardMode = "proxy"
+	// ForwardRedirect returns the leader's gRPC address to the caller
+	ForwardRedirect ForwardMode = "redirect"
+)
+
+// forwardedHeader marks requests proxied by a follower so they are never forwarded twice
+const forwardedHeader = "x-raft-forwarded"
+
+// ParseForwardMode parses a forwarding mode from configuration, defaulting to proxy
+func ParseForwardMode(mode string) (ForwardMode, error) {
+	switch ForwardMode(mode) {
+	case "", ForwardProxy:
+		return ForwardProxy, nil
+	case ForwardRedirect:
+		return ForwardRedirect, nil
+	default:
+		return "", fmt.Errorf("unknown leader forwarding mode %q", mode)
+	}
+}
+
+// leaderGRPCAddress returns the gRPC address of the current leader
+func (s *Server) leaderGRPCAddress() (string, error) {
+	if leader, found := s.cluster.GetLeaderInfo(); found && leader.GrpcAddress != "" {
+		return leader.GrpcAddress, nil
+	}
+
+	raftAddr := s.cluster.GetLeaderAddress()
+	if raftAddr == "" {
+		return "", status.Error(codes.Unavailable, "no leader elected")
+	}
+
+	// The leader has not published its metadata yet, so assume
+	// it serves gRPC on the same port as this node
+	host, _, err := net.SplitHostPort(raftAddr)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "invalid leader address %q: %v", raftAddr, err)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(s.config.GRPCPort)), nil
+}
+
+// leaderConn returns a client connection to the leader and the outgoing context
+// to use for the proxied call. The incoming deadline carries over unchanged.
+func (s *Server) leaderConn(ctx context.Context) (*grpc.ClientConn, context.Context, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(forwardedHeader)) > 0 {
+		return nil, nil, status.Error(codes.Unavailable, "forwarded request reached a node that is not the leader")
+	}
+
+	addr, err := s.leaderGRPCAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.forwardMu.Lock()
+	defer s.forwardMu.Unlock()
+
+	conn, exists := s.leaderConns[addr]
+	if !exists {
+		conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Unavailable, "failed to connect to leader at %s: %v", addr, err)
+		}
+		s.leaderConns[addr] = conn
+	}
+
+	return conn, metadata.AppendToOutgoingContext(ctx, forwardedHeader, "true"), nil
+}
+
+// closeLeaderConns closes all cached leader connections
+func (s *Server) closeLeaderConns() error {
+	s.forwardMu.Lock()
+	defer s.forwardMu.Unlock()
+
+	var firstErr error
+	for addr, conn := range s.leaderConns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close connection to %s: %w", addr, err)
+		}
+		delete(s.leaderConns, addr)
+	}
+	return firstErr
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestForward_ProxiesWritesToLeader(t *testing.T) {
+	leader, follower := setupLeaderFollower(t, ForwardProxy)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	submitted, err := follower.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() via follower returned error: %v", err)
+	}
+	if _, found := leader.cluster.GetFSM().GetTask(submitted.TaskId); !found {
+		t.Fatalf("task %s was not committed on the leader", submitted.TaskId)
+	}
+
+	hb, err := follower.Heartbeat(ctx, &pb.HeartbeatRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("Heartbeat() via follower returned error: %v", err)
+	}
+	if !hb.Acknowledged || hb.LeaderAddress == "" {
+		t.Errorf("expected acknowledged heartbeat with leader address, got %v", hb)
+	}
+
+	poll, err := follower.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() via follower returned error: %v", err)
+	}
+	if !poll.HasTask || poll.Task.TaskId != submitted.TaskId {
+		t.Fatalf("expected task %s, got %v", submitted.TaskId, poll)
+	}
+
+	report, err := follower.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       submitted.TaskId,
+		FinalStatus:  pb.TaskStatus_COMPLETED,
+		FencingToken: poll.Task.FencingToken,
+	})
+	if err != nil {
+		t.Fatalf("ReportTaskResult() via follower returned error: %v", err)
+	}
+	if !report.Acknowledged {
+		t.Error("expected result to be acknowledged")
+	}
+}
+
+func TestForward_RedirectReturnsLeaderAddress(t *testing.T) {
+	leader, follower := setupLeaderFollower(t, ForwardRedirect)
+	ctx := context.Background()
+
+	resp, err := follower.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() via follower returned error: %v", err)
+	}
+	if resp.Success || resp.TaskId != "" {
+		t.Errorf("expected follower not to create a task, got %v", resp)
+	}
+
+	want, err := leader.leaderGRPCAddress()
+	if err != nil {
+		t.Fatalf("leaderGRPCAddress() returned error: %v", err)
+	}
+	if resp.LeaderAddress != want {
+		t.Errorf("expected leader address %s, got %s", want, resp.LeaderAddress)
+	}
+
+	if tasks := leader.cluster.GetFSM().ListTasks(nil, 0); len(tasks) != 0 {
+		t.Errorf("expected no tasks on the leader, got %d", len(tasks))
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"ml-raft-control-plane/internal/raft"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the TaskService, JobService, NodeService and ClusterService gRPC APIs
+// on top of the Raft cluster and its Task Manifest FSM
+type Server struct {
+	pb.UnimplementedTaskServiceServer
+	pb.UnimplementedNodeServiceServer
+	pb.UnimplementedClusterServiceServer
+	pb.UnimplementedJobServiceServer
+
+	cluster *raft.RaftCluster
+	config  Config
+
+	// forwardMu guards the cached connections used to reach the leader
+	forwardMu   sync.Mutex
+	leaderConns map[string]*grpc.ClientConn
+}
+
+// Config holds gRPC API server configuration
+type Config struct {
+	// GRPCPort is the local gRPC port, assumed for a leader whose metadata is not yet known
+	GRPCPort int
+	// ForwardMode controls how followers handle write RPCs
+	ForwardMode ForwardMode
+}
+
+// NewServer creates a new gRPC API server backed by the given cluster
+func NewServer(cluster *raft.RaftCluster, config Config) *Server {
+	if config.ForwardMode == "" {
+		config.ForwardMode = ForwardProxy
+	}
+
+	return &Server{
+		cluster:     cluster,
+		config:      config,
+		leaderConns: make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Register registers all services with a gRPC server
+func (s *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterTaskServiceServer(grpcServer, s)
+	pb.RegisterNodeServiceServer(grpcServer, s)
+	pb.RegisterClusterServiceServer(grpcServer, s)
+	pb.RegisterJobServiceServer(grpcServer, s)
+}
+
+// Close releases connections held for leader forwarding
+func (s *Server) Close() error {
+	return s.closeLeaderConns()
+}
+
+// apply encodes a log entry and commits it through Raft, returning a gRPC status error on failure
+func (s *Server) apply(ctx context.Context, entryType raft.LogEntryType, entry interface{}) error {
+	data, err := raft.EncodeLogEntry(entryType, entry)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.cluster.Apply(data, applyTimeout(ctx)); err != nil {
+		return toStatusError(err)
+	}
+	return nil
+}
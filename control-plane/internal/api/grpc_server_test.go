@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"ml-raft-control-plane/internal/raft"
+
+	"google.golang.org/grpc"
+)
+
+// freePort reserves a local TCP port on the given host
+func freePort(t *testing.T, host string) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// newTestCluster starts a Raft node on host, bootstrapping a single-node cluster if requested.
+// It returns the cluster and its Raft bind address.
+func newTestCluster(t *testing.T, nodeID, host string, grpcPort int, bootstrap bool) (*raft.RaftCluster, string) {
+	t.Helper()
+	bindAddress := net.JoinHostPort(host, strconv.Itoa(freePort(t, host)))
+	bootstrapExpect := 0
+	if bootstrap {
+		bootstrapExpect = 1
+	}
+
+	cluster, err := raft.NewRaftCluster(&raft.ClusterConfig{
+		NodeID:            nodeID,
+		BindAddress:       bindAddress,
+		GRPCAddress:       net.JoinHostPort(host, strconv.Itoa(grpcPort)),
+		CloudProvider:     "local",
+		Region:            host,
+		DataDir:           t.TempDir(),
+		BootstrapExpect:   bootstrapExpect,
+		HeartbeatTimeout:  500 * time.Millisecond,
+		ElectionTimeout:   500 * time.Millisecond,
+		CommitTimeout:     50 * time.Millisecond,
+		SnapshotInterval:  120 * time.Second,
+		SnapshotThreshold: 8192,
+	})
+	if err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	t.Cleanup(func() { cluster.Shutdown() })
+
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("failed to bootstrap cluster: %v", err)
+	}
+	return cluster, bindAddress
+}
+
+// serveGRPC serves the API server on host:port until the test ends
+func serveGRPC(t *testing.T, s *Server, host string, port int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		t.Fatalf("failed to listen for gRPC: %v", err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(MaxRecvMsgSize))
+	s.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		s.Close()
+	})
+}
+
+// setupServer starts a single-node cluster and returns an API server backed by it
+func setupServer(t *testing.T) *Server {
+	t.Helper()
+	grpcPort := freePort(t, "127.0.0.1")
+	cluster, _ := newTestCluster(t, "test-node", "127.0.0.1", grpcPort, true)
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	s := NewServer(cluster, Config{GRPCPort: grpcPort})
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// setupLeaderFollower starts a two-node cluster on distinct loopback hosts and gRPC
+// ports, publishes both nodes' metadata, and returns the leader and follower API servers
+func setupLeaderFollower(t *testing.T, mode ForwardMode) (*Server, *Server) {
+	t.Helper()
+	leaderPort := freePort(t, "127.0.0.1")
+	followerPort := freePort(t, "127.0.0.2")
+
+	leaderCluster, _ := newTestCluster(t, "leader", "127.0.0.1", leaderPort, true)
+	if err := leaderCluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	followerCluster, followerAddr := newTestCluster(t, "follower", "127.0.0.2", followerPort, false)
+	if err := leaderCluster.AddVoter("follower", followerAddr, 10*time.Second); err != nil {
+		t.Fatalf("failed to add follower: %v", err)
+	}
+	if err := followerCluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("follower never learned the leader: %v", err)
+	}
+
+	leader := NewServer(leaderCluster, Config{GRPCPort: leaderPort, ForwardMode: mode})
+	follower := NewServer(followerCluster, Config{GRPCPort: followerPort, ForwardMode: mode})
+	serveGRPC(t, leader, "127.0.0.1", leaderPort)
+	serveGRPC(t, follower, "127.0.0.2", followerPort)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	leader.PublishServerInfo(ctx, 100*time.Millisecond)
+	follower.PublishServerInfo(ctx, 100*time.Millisecond)
+	waitFor(t, func() bool {
+		_, leaderFound := followerCluster.LookupServer("leader")
+		_, followerFound := followerCluster.LookupServer("follower")
+		return leaderFound && followerFound
+	})
+
+	return leader, follower
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
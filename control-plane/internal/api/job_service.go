@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"ml-raft-control-plane/internal/raft"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SubmitJob creates a job and all of its PENDING tasks in a single log
+// entry. Like SubmitTasks, it accepts at most MaxBatchBytes of tasks.
+func (s *Server) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*pb.SubmitJobResponse, error) {
+	if len(req.Tasks) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "a job needs at least one task")
+	}
+	if err := checkBatchSize(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := raft.SubmitJobEntry{
+		JobID:     uuid.NewString(),
+		Name:      req.Name,
+		CreatedAt: now.Unix(),
+	}
+	var err error
+	if entry.Tasks, err = newBatchEntries(req.Tasks, now); err != nil {
+		return nil, err
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.SubmitJobResponse{
+				Success:       false,
+				ErrorMessage:  "not the leader",
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewJobServiceClient(conn).SubmitJob(forwardCtx, req)
+	}
+
+	if err := s.checkDependencies(entry.Tasks); err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, raft.LogEntrySubmitJob, entry); err != nil {
+		return nil, err
+	}
+
+	taskIDs := make([]string, len(entry.Tasks))
+	for i, taskEntry := range entry.Tasks {
+		taskIDs[i] = taskEntry.TaskID
+	}
+	return &pb.SubmitJobResponse{
+		JobId:   entry.JobID,
+		TaskIds: taskIDs,
+		Success: true,
+	}, nil
+}
+
+// GetJob returns a job and its derived status from the local FSM
+func (s *Server) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.GetJobResponse, error) {
+	if req.JobId == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+
+	job, found := s.cluster.GetFSM().GetJob(req.JobId)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "job %s not found", req.JobId)
+	}
+
+	return &pb.GetJobResponse{
+		Job:   job,
+		Found: true,
+	}, nil
+}
+
+// ListJobs returns jobs from the local FSM ordered by creation time
+func (s *Server) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	return &pb.ListJobsResponse{
+		Jobs: s.cluster.GetFSM().ListJobs(req.StatusFilter, int(req.Limit)),
+	}, nil
+}
+
+// CancelJob cancels every task of a job that has not finished, as
+// CancelTask does for a single task
+func (s *Server) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.CancelJobResponse, error) {
+	if req.JobId == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.CancelJobResponse{
+				Acknowledged:  false,
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewJobServiceClient(conn).CancelJob(forwardCtx, req)
+	}
+
+	entry := raft.CancelJobEntry{
+		JobID:       req.JobId,
+		CancelledAt: time.Now().Unix(),
+	}
+	if err := s.apply(ctx, raft.LogEntryCancelJob, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.CancelJobResponse{
+		Acknowledged: true,
+	}, nil
+}
@@ -0,0 +1,298 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ml-raft-control-plane/internal/planner"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSubmitJob_TracksProgress(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+	heartbeat(t, s, "agent-1")
+
+	submitted, err := s.SubmitJob(ctx, &pb.SubmitJobRequest{
+		Name: "matmul-64",
+		Tasks: []*pb.SubmitTaskRequest{
+			{TaskType: "map"},
+			{TaskType: "map"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SubmitJob() returned error: %v", err)
+	}
+	if len(submitted.TaskIds) != 2 {
+		t.Fatalf("expected 2 task IDs, got %v", submitted.TaskIds)
+	}
+
+	job := getJob(t, s, submitted.JobId)
+	if job.Status != pb.JobStatus_JOB_PENDING || job.Progress.Pending != 2 {
+		t.Errorf("expected PENDING job with 2 pending tasks, got %s %v", job.Status, job.Progress)
+	}
+	task, _ := s.cluster.GetFSM().GetTask(submitted.TaskIds[0])
+	if task.JobId != submitted.JobId {
+		t.Errorf("expected task to reference job %s, got %q", submitted.JobId, task.JobId)
+	}
+
+	for i := 0; i < 2; i++ {
+		poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+		if err != nil || !poll.HasTask {
+			t.Fatalf("expected a task, got %v, %v", poll, err)
+		}
+		if i == 0 {
+			job := getJob(t, s, submitted.JobId)
+			if job.Status != pb.JobStatus_JOB_RUNNING || job.Progress.Running != 1 {
+				t.Errorf("expected RUNNING job with 1 running task, got %s %v", job.Status, job.Progress)
+			}
+		}
+		if _, err := s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+			TaskId:       poll.Task.TaskId,
+			FinalStatus:  pb.TaskStatus_COMPLETED,
+			FencingToken: poll.Task.FencingToken,
+		}); err != nil {
+			t.Fatalf("ReportTaskResult() returned error: %v", err)
+		}
+	}
+
+	job = getJob(t, s, submitted.JobId)
+	if job.Status != pb.JobStatus_JOB_SUCCEEDED || job.Progress.Completed != 2 || job.CompletedAt == 0 {
+		t.Errorf("expected SUCCEEDED job with 2 completed tasks, got %s %v", job.Status, job.Progress)
+	}
+
+	succeeded := pb.JobStatus_JOB_SUCCEEDED
+	list, err := s.ListJobs(ctx, &pb.ListJobsRequest{StatusFilter: &succeeded})
+	if err != nil {
+		t.Fatalf("ListJobs() returned error: %v", err)
+	}
+	if len(list.Jobs) != 1 || list.Jobs[0].JobId != submitted.JobId {
+		t.Errorf("expected the job in the SUCCEEDED list, got %v", list.Jobs)
+	}
+}
+
+func TestCancelJob_CancelsUnfinishedTasks(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+	heartbeat(t, s, "agent-1")
+
+	submitted, err := s.SubmitJob(ctx, &pb.SubmitJobRequest{
+		Tasks: []*pb.SubmitTaskRequest{{TaskType: "map"}, {TaskType: "map"}},
+	})
+	if err != nil {
+		t.Fatalf("SubmitJob() returned error: %v", err)
+	}
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil || !poll.HasTask {
+		t.Fatalf("expected a task, got %v, %v", poll, err)
+	}
+
+	if _, err := s.CancelJob(ctx, &pb.CancelJobRequest{JobId: submitted.JobId}); err != nil {
+		t.Fatalf("CancelJob() returned error: %v", err)
+	}
+
+	// The assigned task keeps its node until the agent acknowledges
+	job := getJob(t, s, submitted.JobId)
+	if job.Status != pb.JobStatus_JOB_CANCELLED || job.Progress.Cancelled != 1 || job.Progress.Running != 1 {
+		t.Errorf("expected CANCELLED job with 1 cancelled and 1 stopping task, got %s %v", job.Status, job.Progress)
+	}
+	resp, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{
+		NodeId:     "agent-1",
+		HeldLeases: []*pb.TaskLease{{TaskId: poll.Task.TaskId, FencingToken: poll.Task.FencingToken}},
+	})
+	if err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+	if len(resp.CancelledTaskIds) != 1 || len(resp.RevokedTaskIds) != 0 {
+		t.Errorf("expected the assigned task cancelled but not revoked, got %v and %v", resp.CancelledTaskIds, resp.RevokedTaskIds)
+	}
+
+	if _, err := s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       poll.Task.TaskId,
+		FinalStatus:  pb.TaskStatus_CANCELLED,
+		FencingToken: poll.Task.FencingToken,
+	}); err != nil {
+		t.Fatalf("ReportTaskResult() returned error: %v", err)
+	}
+	job = getJob(t, s, submitted.JobId)
+	if job.Progress.Cancelled != 2 {
+		t.Errorf("expected 2 cancelled tasks, got %v", job.Progress)
+	}
+	node, _ := s.cluster.GetFSM().GetNode("agent-1")
+	if node.ActiveTasks != 0 {
+		t.Errorf("expected agent-1 released, got %d active tasks", node.ActiveTasks)
+	}
+
+	_, err = s.CancelJob(ctx, &pb.CancelJobRequest{JobId: submitted.JobId})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition cancelling twice, got %v", err)
+	}
+	_, err = s.CancelJob(ctx, &pb.CancelJobRequest{JobId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown job, got %v", err)
+	}
+}
+
+func TestSubmitJob_ReduceWaitsForMaps(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+	heartbeat(t, s, "agent-1")
+
+	if _, err := s.SubmitJob(ctx, &pb.SubmitJobRequest{
+		Tasks: []*pb.SubmitTaskRequest{
+			{TaskId: "map-0", TaskType: "map"},
+			{TaskId: "reduce-0", TaskType: "reduce", DependsOn: []string{"map-0"}},
+		},
+	}); err != nil {
+		t.Fatalf("SubmitJob() returned error: %v", err)
+	}
+
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil || !poll.HasTask || poll.Task.TaskId != "map-0" {
+		t.Fatalf("expected map-0, got %v, %v", poll, err)
+	}
+	if blocked, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"}); err != nil || blocked.HasTask {
+		t.Fatalf("expected reduce-0 to wait for map-0, got %v, %v", blocked, err)
+	}
+
+	if _, err := s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       "map-0",
+		FinalStatus:  pb.TaskStatus_COMPLETED,
+		FencingToken: poll.Task.FencingToken,
+	}); err != nil {
+		t.Fatalf("ReportTaskResult() returned error: %v", err)
+	}
+
+	poll, err = s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil || !poll.HasTask || poll.Task.TaskId != "reduce-0" {
+		t.Fatalf("expected reduce-0 once map-0 completed, got %v, %v", poll, err)
+	}
+
+	// Client-chosen IDs must be unique
+	_, err = s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskId: "map-0", TaskType: "map"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists for a taken task ID, got %v", err)
+	}
+}
+
+func TestSubmitJob_MatMulPlan(t *testing.T) {
+	s := setupServer(t)
+
+	job, err := planner.PlanMatMul(planner.MatMulSpec{
+		Name: "mm", M: 4, K: 4, N: 4, BlockSize: 2,
+		InputA: "gs://bucket/a", InputB: "gs://bucket/b", Output: "gs://bucket/c",
+	})
+	if err != nil {
+		t.Fatalf("PlanMatMul() returned error: %v", err)
+	}
+	submitted, err := s.SubmitJob(context.Background(), job)
+	if err != nil {
+		t.Fatalf("SubmitJob() returned error: %v", err)
+	}
+
+	fsm := s.cluster.GetFSM()
+	if pending := fsm.PendingTasks(); len(pending) != 8 {
+		t.Errorf("expected the 8 map tasks PENDING, got %d", len(pending))
+	}
+	reduce, _ := fsm.GetTask("mm/reduce/1-1")
+	if reduce.Status != pb.TaskStatus_BLOCKED || reduce.JobId != submitted.JobId {
+		t.Errorf("expected reduce BLOCKED in job %s, got %s in %q", submitted.JobId, reduce.Status, reduce.JobId)
+	}
+}
+
+func TestSubmitJob_BatchSizeLimit(t *testing.T) {
+	leader, follower := setupLeaderFollower(t, ForwardProxy)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// A plan larger than gRPC's default 4 MiB receive limit reaches the leader through the follower
+	planned, err := planner.PlanMatMul(planner.MatMulSpec{
+		Name: "mm-22528", M: 22528, K: 22528, N: 22528, BlockSize: 1024,
+		InputA: "gs://bucket/a", InputB: "gs://bucket/b", Output: "gs://bucket/mm-22528",
+	})
+	if err != nil {
+		t.Fatalf("PlanMatMul() returned error: %v", err)
+	}
+	if size := proto.Size(planned); size <= 4<<20 || size > MaxBatchBytes {
+		t.Fatalf("expected a plan between 4 MiB and %d bytes, got %d bytes", MaxBatchBytes, size)
+	}
+	submitted, err := follower.SubmitJob(ctx, planned)
+	if err != nil {
+		t.Fatalf("SubmitJob() via follower returned error: %v", err)
+	}
+	if len(submitted.TaskIds) != len(planned.Tasks) {
+		t.Errorf("expected %d task IDs, got %d", len(planned.Tasks), len(submitted.TaskIds))
+	}
+
+	// One byte more than the limit is rejected before anything is committed
+	oversized := &pb.SubmitJobRequest{Name: "oversized", Tasks: []*pb.SubmitTaskRequest{{TaskId: "oversized", TaskType: "map"}}}
+	for padding := 0; proto.Size(oversized) != MaxBatchBytes+1; {
+		padding += MaxBatchBytes + 1 - proto.Size(oversized)
+		oversized.Tasks[0].TaskData = []byte(`"` + strings.Repeat("x", padding) + `"`)
+	}
+	for _, s := range []*Server{follower, leader} {
+		if _, err := s.SubmitJob(ctx, oversized); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument, got %v", err)
+		}
+	}
+	if _, found := leader.cluster.GetFSM().GetTask("oversized"); found {
+		t.Error("expected no task of an oversized job to exist")
+	}
+}
+
+func TestSubmitJob_InvalidArgument(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *pb.SubmitJobRequest
+	}{
+		{"no tasks", &pb.SubmitJobRequest{Name: "empty"}},
+		{"invalid task", &pb.SubmitJobRequest{Tasks: []*pb.SubmitTaskRequest{{TaskType: "map"}, {}}}},
+		{"dependency cycle", &pb.SubmitJobRequest{Tasks: []*pb.SubmitTaskRequest{
+			{TaskId: "a", TaskType: "map", DependsOn: []string{"c"}},
+			{TaskId: "b", TaskType: "map", DependsOn: []string{"a"}},
+			{TaskId: "c", TaskType: "reduce", DependsOn: []string{"b"}},
+		}}},
+		{"duplicate task IDs", &pb.SubmitJobRequest{Tasks: []*pb.SubmitTaskRequest{
+			{TaskId: "a", TaskType: "map"},
+			{TaskId: "a", TaskType: "map"},
+		}}},
+		{"task idempotency key", &pb.SubmitJobRequest{Tasks: []*pb.SubmitTaskRequest{{TaskType: "map", IdempotencyKey: "req-1"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SubmitJob(ctx, tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+
+	// Nothing from the rejected jobs was created
+	tasks, err := s.ListTasks(ctx, &pb.ListTasksRequest{})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if len(tasks.Tasks) != 0 {
+		t.Errorf("expected no tasks, got %d", len(tasks.Tasks))
+	}
+}
+
+// getJob fetches a job or fails the test
+func getJob(t *testing.T, s *Server, jobID string) *pb.Job {
+	t.Helper()
+	resp, err := s.GetJob(context.Background(), &pb.GetJobRequest{JobId: jobID})
+	if err != nil {
+		t.Fatalf("GetJob() returned error: %v", err)
+	}
+	return resp.Job
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"ml-raft-control-plane/internal/models"
+	"ml-raft-control-plane/internal/raft"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxAssignAttempts bounds how often a poll retries after concurrent polls
+// from the same node take the tasks placed on it
+const maxAssignAttempts = 3
+
+// Heartbeat records node liveness and resource usage through Raft consensus
+func (s *Server) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	if req.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "node_id is required")
+	}
+	if err := validateResources(req.Capacity, "capacity"); err != nil {
+		return nil, err
+	}
+	if err := validateResources(req.Allocatable, "allocatable"); err != nil {
+		return nil, err
+	}
+	if req.Allocatable != nil && req.Capacity == nil {
+		return nil, status.Error(codes.InvalidArgument, "allocatable requires capacity")
+	}
+	for key := range req.Labels {
+		if key == "" || models.IsBuiltinLabel(key) {
+			return nil, status.Errorf(codes.InvalidArgument, "label key %q is empty or reserved", key)
+		}
+	}
+
+	// Followers cannot commit heartbeats, so point the agent at the leader
+	if !s.cluster.IsLeader() {
+		leaderAddr, err := s.leaderGRPCAddress()
+		if err != nil {
+			return nil, err
+		}
+
+		if s.config.ForwardMode == ForwardRedirect {
+			return &pb.HeartbeatResponse{
+				Acknowledged:  false,
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := pb.NewNodeServiceClient(conn).Heartbeat(forwardCtx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp.LeaderAddress = leaderAddr
+		return resp, nil
+	}
+
+	now := time.Now()
+	entry := raft.NodeHeartbeatEntry{
+		NodeID:        req.NodeId,
+		CPUUsage:      req.CpuUsage,
+		MemoryUsage:   req.MemoryUsage,
+		ActiveTasks:   req.ActiveTasks,
+		Timestamp:     now.Unix(),
+		Capacity:      req.Capacity,
+		Allocatable:   req.Allocatable,
+		CloudProvider: req.CloudProvider,
+		Region:        req.Region,
+		Labels:        req.Labels,
+	}
+	if len(req.HeldLeases) > 0 {
+		entry.Leases = req.HeldLeases
+		entry.LeaseExpiresAt = now.Add(s.cluster.TaskLeaseDuration()).Unix()
+	}
+
+	if err := s.apply(ctx, raft.LogEntryNodeHeartbeat, entry); err != nil {
+		return nil, err
+	}
+
+	resp := &pb.HeartbeatResponse{
+		Acknowledged:   true,
+		LeaseExpiresAt: entry.LeaseExpiresAt,
+	}
+
+	// Tell the agent which of its tasks were expired, reclaimed, reassigned
+	// or cancelled
+	fsm := s.cluster.GetFSM()
+	for _, lease := range req.HeldLeases {
+		task, found := fsm.GetTask(lease.TaskId)
+		if !found || !models.HoldsLease(task, req.NodeId, lease.FencingToken) {
+			resp.RevokedTaskIds = append(resp.RevokedTaskIds, lease.TaskId)
+			if found && models.WasPreemptedFrom(task, req.NodeId) {
+				resp.PreemptedTaskIds = append(resp.PreemptedTaskIds, lease.TaskId)
+			}
+			continue
+		}
+		if task.Status == pb.TaskStatus_CANCELLING {
+			resp.CancelledTaskIds = append(resp.CancelledTaskIds, lease.TaskId)
+		}
+	}
+
+	return resp, nil
+}
+
+// PollTask assigns the calling node the oldest PENDING task the scheduler placed on it
+func (s *Server) PollTask(ctx context.Context, req *pb.PollTaskRequest) (*pb.PollTaskResponse, error) {
+	if req.NodeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "node_id is required")
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.PollTaskResponse{
+				HasTask:       false,
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewNodeServiceClient(conn).PollTask(forwardCtx, req)
+	}
+
+	fsm := s.cluster.GetFSM()
+	if _, found := fsm.GetNode(req.NodeId); !found {
+		return nil, status.Errorf(codes.FailedPrecondition, "node %s is not registered, send a heartbeat first", req.NodeId)
+	}
+
+	// Agents that poll without heartbeating still learn of cancellations
+	cancelled := fsm.CancellingTasks(req.NodeId)
+
+	now := time.Now()
+	for attempt := 0; attempt < maxAssignAttempts; attempt++ {
+		task, err := s.placedTaskFor(req.NodeId, now)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			break
+		}
+
+		entry := raft.AssignTaskEntry{
+			TaskID:         task.TaskId,
+			NodeID:         req.NodeId,
+			AssignedAt:     now.Unix(),
+			LeaseExpiresAt: now.Add(s.cluster.TaskLeaseDuration()).Unix(),
+		}
+		err = s.apply(ctx, raft.LogEntryAssignTask, entry)
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.FailedPrecondition, codes.ResourceExhausted:
+			// A concurrent poll from the same node took the task or its room first
+			continue
+		default:
+			return nil, err
+		}
+
+		assigned, found := fsm.GetTask(task.TaskId)
+		if !found {
+			return nil, status.Errorf(codes.Internal, "assigned task %s disappeared", task.TaskId)
+		}
+		return &pb.PollTaskResponse{
+			Task:             assigned,
+			HasTask:          true,
+			CancelledTaskIds: cancelled,
+		}, nil
+	}
+
+	return &pb.PollTaskResponse{HasTask: false, CancelledTaskIds: cancelled}, nil
+}
+
+// placedTaskFor returns the first PENDING task in dispatch order placed on
+// nodeID that fits it. When none is, it runs a placement round, or waits for
+// the one in progress, and looks again. It returns nil if nodeID gets no task
+// this poll.
+func (s *Server) placedTaskFor(nodeID string, now time.Time) (*pb.Task, error) {
+	fsm := s.cluster.GetFSM()
+	if task, found := fsm.NextPlacedTask(nodeID, now, s.cluster.PlacementTTL()); found {
+		return task, nil
+	}
+	if err := s.cluster.PlaceTasks(); err != nil {
+		return nil, toStatusError(err)
+	}
+	task, _ := fsm.NextPlacedTask(nodeID, now, s.cluster.PlacementTTL())
+	return task, nil
+}
+
+// ReportTaskResult records the final outcome of a task, or acknowledges the
+// cancellation of a CANCELLING task. The fencing token must match the
+// current assignment, so results from an agent whose lease expired are
+// rejected. A retry carrying the idempotency key of an applied report is
+// acknowledged again without effect.
+func (s *Server) ReportTaskResult(ctx context.Context, req *pb.ReportTaskResultRequest) (*pb.ReportTaskResultResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency_key must be at most %d bytes", maxIdempotencyKeyLength)
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.ReportTaskResultResponse{
+				Acknowledged:  false,
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewNodeServiceClient(conn).ReportTaskResult(forwardCtx, req)
+	}
+
+	fsm := s.cluster.GetFSM()
+	if _, found := fsm.GetTask(req.TaskId); !found {
+		return nil, status.Errorf(codes.NotFound, "task %s not found", req.TaskId)
+	}
+	// A retried result gets the outcome of the first attempt, even a rejection
+	if reported, err := fsm.ReportedResult(req.TaskId, req.IdempotencyKey); reported {
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		return &pb.ReportTaskResultResponse{
+			Acknowledged: true,
+		}, nil
+	}
+
+	switch req.FinalStatus {
+	case pb.TaskStatus_COMPLETED:
+		if req.ResultData != "" && !json.Valid([]byte(req.ResultData)) {
+			return nil, status.Error(codes.InvalidArgument, "result_data must be valid JSON")
+		}
+		entry := raft.CompleteTaskEntry{
+			TaskID:         req.TaskId,
+			CompletedAt:    time.Now().Unix(),
+			FencingToken:   req.FencingToken,
+			IdempotencyKey: req.IdempotencyKey,
+		}
+		if req.ResultData != "" {
+			entry.ResultData = json.RawMessage(req.ResultData)
+		}
+		if err := s.apply(ctx, raft.LogEntryCompleteTask, entry); err != nil {
+			return nil, err
+		}
+
+	case pb.TaskStatus_FAILED:
+		errorMessage := req.ErrorMessage
+		if errorMessage == "" {
+			errorMessage = req.ResultData
+		}
+		entry := raft.FailTaskEntry{
+			TaskID:         req.TaskId,
+			ErrorMessage:   errorMessage,
+			ErrorClass:     req.ErrorClass,
+			FailedAt:       time.Now().Unix(),
+			FencingToken:   req.FencingToken,
+			IdempotencyKey: req.IdempotencyKey,
+		}
+		if err := s.apply(ctx, raft.LogEntryFailTask, entry); err != nil {
+			return nil, err
+		}
+
+	case pb.TaskStatus_CANCELLED:
+		entry := raft.AcknowledgeCancelEntry{
+			TaskID:         req.TaskId,
+			FencingToken:   req.FencingToken,
+			CancelledAt:    time.Now().Unix(),
+			IdempotencyKey: req.IdempotencyKey,
+		}
+		if err := s.apply(ctx, raft.LogEntryAcknowledgeCancel, entry); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "final_status must be COMPLETED, FAILED or CANCELLED, got %s", req.FinalStatus)
+	}
+
+	return &pb.ReportTaskResultResponse{
+		Acknowledged: true,
+	}, nil
+}
+
+// ReportTaskProgress records how far an agent got with a task. The first
+// report moves an ASSIGNED task to RUNNING and is committed at once; later
+// reports are buffered and committed in batches, so GetTask may lag behind
+// the agent by up to the flush interval.
+func (s *Server) ReportTaskProgress(ctx context.Context, req *pb.ReportTaskProgressRequest) (*pb.ReportTaskProgressResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+	if math.IsNaN(req.PercentComplete) || req.PercentComplete < 0 || req.PercentComplete > 100 {
+		return nil, status.Errorf(codes.InvalidArgument, "percent_complete must be between 0 and 100, got %g", req.PercentComplete)
+	}
+	for name, value := range req.Metrics {
+		if name == "" {
+			return nil, status.Error(codes.InvalidArgument, "metric names must not be empty")
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, status.Errorf(codes.InvalidArgument, "metric %q must be finite", name)
+		}
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.ReportTaskProgressResponse{
+				Acknowledged:  false,
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewNodeServiceClient(conn).ReportTaskProgress(forwardCtx, req)
+	}
+
+	task, found := s.cluster.GetFSM().GetTask(req.TaskId)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "task %s not found", req.TaskId)
+	}
+	if !models.IsLeased(task) || task.FencingToken != req.FencingToken {
+		return nil, status.Errorf(codes.FailedPrecondition, "task %s is %s and not held under token %d",
+			req.TaskId, task.Status, req.FencingToken)
+	}
+
+	update := raft.ProgressUpdate{
+		TaskID:          req.TaskId,
+		FencingToken:    req.FencingToken,
+		PercentComplete: req.PercentComplete,
+		Stage:           req.Stage,
+		Metrics:         req.Metrics,
+		ReportedAt:      time.Now().Unix(),
+	}
+	if task.Status == pb.TaskStatus_ASSIGNED {
+		entry := raft.ReportProgressEntry{Updates: []raft.ProgressUpdate{update}}
+		if err := s.apply(ctx, raft.LogEntryReportProgress, entry); err != nil {
+			return nil, err
+		}
+	} else {
+		s.cluster.BufferProgress(update)
+	}
+
+	return &pb.ReportTaskProgressResponse{
+		Acknowledged: true,
+		Cancelling:   task.Status == pb.TaskStatus_CANCELLING,
+	}, nil
+}
@@ -0,0 +1,535 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHeartbeat_RegistersNode(t *testing.T) {
+	s := setupServer(t)
+
+	resp, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{
+		NodeId:      "agent-1",
+		CpuUsage:    12.5,
+		MemoryUsage: 40,
+	})
+	if err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+	if !resp.Acknowledged {
+		t.Error("expected heartbeat to be acknowledged by the leader")
+	}
+
+	node, ok := s.cluster.GetFSM().GetNode("agent-1")
+	if !ok {
+		t.Fatal("expected heartbeat to register node agent-1")
+	}
+	if node.CpuUsage != 12.5 {
+		t.Errorf("expected CPU usage 12.5, got %f", node.CpuUsage)
+	}
+}
+
+func TestPollTask_AssignsAndReports(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	submitted, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	heartbeat(t, s, "agent-1", "agent-2")
+
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if !poll.HasTask || poll.Task.TaskId != submitted.TaskId {
+		t.Fatalf("expected task %s, got %v", submitted.TaskId, poll)
+	}
+	if poll.Task.Status != pb.TaskStatus_ASSIGNED || poll.Task.AssignedNodeId != "agent-1" {
+		t.Errorf("expected task ASSIGNED to agent-1, got %s on %q", poll.Task.Status, poll.Task.AssignedNodeId)
+	}
+
+	empty, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-2"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if empty.HasTask {
+		t.Errorf("expected no task for second poll, got %s", empty.Task.TaskId)
+	}
+
+	_, err = s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       submitted.TaskId,
+		FinalStatus:  pb.TaskStatus_COMPLETED,
+		ResultData:   `{"checksum": "abc"}`,
+		FencingToken: poll.Task.FencingToken,
+	})
+	if err != nil {
+		t.Fatalf("ReportTaskResult() returned error: %v", err)
+	}
+
+	got, _ := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: submitted.TaskId})
+	if got.Task.Status != pb.TaskStatus_COMPLETED {
+		t.Errorf("expected task COMPLETED, got %s", got.Task.Status)
+	}
+}
+
+func TestPollTask_ConcurrentAgentsGetDistinctTasks(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	const numTasks = 5
+	for i := 0; i < numTasks; i++ {
+		if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+			t.Fatalf("SubmitTask() returned error: %v", err)
+		}
+	}
+
+	heartbeat(t, s, "agent-1", "agent-2", "agent-3")
+
+	var mu sync.Mutex
+	seen := make(map[string]string)
+	var wg sync.WaitGroup
+	for _, nodeID := range []string{"agent-1", "agent-2", "agent-3"} {
+		wg.Add(1)
+		go func(nodeID string) {
+			defer wg.Done()
+			for {
+				resp, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: nodeID})
+				if err != nil {
+					t.Errorf("PollTask() returned error: %v", err)
+					return
+				}
+				if !resp.HasTask {
+					return
+				}
+				mu.Lock()
+				if owner, dup := seen[resp.Task.TaskId]; dup {
+					t.Errorf("task %s handed to both %s and %s", resp.Task.TaskId, owner, nodeID)
+				}
+				seen[resp.Task.TaskId] = nodeID
+				mu.Unlock()
+			}
+		}(nodeID)
+	}
+	wg.Wait()
+
+	if len(seen) != numTasks {
+		t.Errorf("expected %d assigned tasks, got %d", numTasks, len(seen))
+	}
+}
+
+func TestPollTask_UnknownNode(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	_, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "never-heartbeated"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestReportTaskResult_InvalidTransition(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	submitted, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	// A PENDING task was never assigned, so it cannot complete
+	_, err = s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:      submitted.TaskId,
+		FinalStatus: pb.TaskStatus_COMPLETED,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestReportTaskResult_InvalidStatus(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	submitted, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	_, err = s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:      submitted.TaskId,
+		FinalStatus: pb.TaskStatus_RUNNING,
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestReportTaskProgress(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+	heartbeat(t, s, "agent-1")
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "train"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil || !poll.HasTask {
+		t.Fatalf("expected a task, got %v, %v", poll, err)
+	}
+
+	// The first report is committed at once
+	if _, err := s.ReportTaskProgress(ctx, &pb.ReportTaskProgressRequest{
+		TaskId:          poll.Task.TaskId,
+		FencingToken:    poll.Task.FencingToken,
+		PercentComplete: 10,
+		Stage:           "epoch 1/10",
+		Metrics:         map[string]float64{"loss": 2.3},
+	}); err != nil {
+		t.Fatalf("ReportTaskProgress() returned error: %v", err)
+	}
+	got, err := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: poll.Task.TaskId})
+	if err != nil {
+		t.Fatalf("GetTask() returned error: %v", err)
+	}
+	if got.Task.Status != pb.TaskStatus_RUNNING || got.Task.Progress.GetStage() != "epoch 1/10" ||
+		got.Task.Progress.Metrics["loss"] != 2.3 {
+		t.Errorf("expected RUNNING in epoch 1/10, got %s %v", got.Task.Status, got.Task.Progress)
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.ReportTaskProgressRequest
+		code codes.Code
+	}{
+		{"percent out of range", &pb.ReportTaskProgressRequest{TaskId: poll.Task.TaskId, FencingToken: poll.Task.FencingToken, PercentComplete: 101}, codes.InvalidArgument},
+		{"metric not finite", &pb.ReportTaskProgressRequest{TaskId: poll.Task.TaskId, FencingToken: poll.Task.FencingToken, Metrics: map[string]float64{"loss": math.NaN()}}, codes.InvalidArgument},
+		{"stale token", &pb.ReportTaskProgressRequest{TaskId: poll.Task.TaskId, FencingToken: poll.Task.FencingToken - 1}, codes.FailedPrecondition},
+		{"unknown task", &pb.ReportTaskProgressRequest{TaskId: "missing"}, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ReportTaskProgress(ctx, tt.req)
+			if status.Code(err) != tt.code {
+				t.Errorf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+
+	// Agents that only report progress still learn of a cancellation
+	if _, err := s.CancelTask(ctx, &pb.CancelTaskRequest{TaskId: poll.Task.TaskId}); err != nil {
+		t.Fatalf("CancelTask() returned error: %v", err)
+	}
+	resp, err := s.ReportTaskProgress(ctx, &pb.ReportTaskProgressRequest{
+		TaskId:          poll.Task.TaskId,
+		FencingToken:    poll.Task.FencingToken,
+		PercentComplete: 20,
+	})
+	if err != nil {
+		t.Fatalf("ReportTaskProgress() returned error: %v", err)
+	}
+	if !resp.Cancelling {
+		t.Error("expected the response to flag the cancellation")
+	}
+}
+
+func TestHeartbeat_RenewsLeasesAndReportsRevoked(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	heartbeat(t, s, "agent-1")
+
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if poll.Task.FencingToken == 0 || poll.Task.LeaseExpiresAt == 0 {
+		t.Fatalf("expected assignment to carry a lease, got token %d expiring at %d",
+			poll.Task.FencingToken, poll.Task.LeaseExpiresAt)
+	}
+
+	resp, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{
+		NodeId: "agent-1",
+		HeldLeases: []*pb.TaskLease{
+			{TaskId: poll.Task.TaskId, FencingToken: poll.Task.FencingToken},
+			{TaskId: "unknown-task", FencingToken: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+	if resp.LeaseExpiresAt < poll.Task.LeaseExpiresAt {
+		t.Errorf("expected lease renewed past %d, got %d", poll.Task.LeaseExpiresAt, resp.LeaseExpiresAt)
+	}
+	if len(resp.RevokedTaskIds) != 1 || resp.RevokedTaskIds[0] != "unknown-task" {
+		t.Errorf("expected only unknown-task revoked, got %v", resp.RevokedTaskIds)
+	}
+}
+
+func TestReportTaskResult_StaleFencingToken(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	heartbeat(t, s, "agent-1")
+
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+
+	_, err = s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       poll.Task.TaskId,
+		FinalStatus:  pb.TaskStatus_FAILED,
+		FencingToken: poll.Task.FencingToken - 1,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+
+	got, _ := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: poll.Task.TaskId})
+	if got.Task.Status != pb.TaskStatus_ASSIGNED {
+		t.Errorf("expected task to stay ASSIGNED, got %s", got.Task.Status)
+	}
+}
+
+func TestReportTaskResult_IdempotencyKey(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	heartbeat(t, s, "agent-1")
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+
+	req := &pb.ReportTaskResultRequest{
+		TaskId:         poll.Task.TaskId,
+		FinalStatus:    pb.TaskStatus_COMPLETED,
+		FencingToken:   poll.Task.FencingToken,
+		IdempotencyKey: "result-1",
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := s.ReportTaskResult(ctx, req)
+		if err != nil || !resp.Acknowledged {
+			t.Fatalf("attempt %d: expected acknowledgement, got %v, %v", i+1, resp, err)
+		}
+	}
+
+	// Without the key a repeated report is an invalid transition
+	req.IdempotencyKey = ""
+	if _, err := s.ReportTaskResult(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+}
+
+func TestReportTaskResult_IdempotencyKeyReplaysRejection(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	heartbeat(t, s, "agent-1")
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+
+	req := &pb.ReportTaskResultRequest{
+		TaskId:         poll.Task.TaskId,
+		FinalStatus:    pb.TaskStatus_COMPLETED,
+		FencingToken:   poll.Task.FencingToken + 1,
+		IdempotencyKey: "result-1",
+	}
+	if _, err := s.ReportTaskResult(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition for a stale token, got %v", err)
+	}
+
+	// The retry is rejected like the first attempt, not acknowledged
+	req.FencingToken = poll.Task.FencingToken
+	if resp, err := s.ReportTaskResult(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected the first rejection to be replayed, got %v, %v", resp, err)
+	}
+	got, _ := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: poll.Task.TaskId})
+	if got.Task.Status != pb.TaskStatus_ASSIGNED {
+		t.Errorf("expected task to stay ASSIGNED, got %s", got.Task.Status)
+	}
+}
+
+// heartbeat registers agents with the cluster so they can be assigned tasks
+func heartbeat(t *testing.T, s *Server, nodeIDs ...string) {
+	t.Helper()
+	for _, nodeID := range nodeIDs {
+		if _, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{NodeId: nodeID}); err != nil {
+			t.Fatalf("Heartbeat() returned error: %v", err)
+		}
+	}
+}
+
+func TestPollTask_PlacesOnSchedulerChoice(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	// agent-1 is busy with a task of its own; agent-2 claims to be busier but is idle
+	heartbeat(t, s, "agent-1")
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	if first, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"}); err != nil || !first.HasTask {
+		t.Fatalf("expected a task for agent-1, got %v, %v", first, err)
+	}
+	if _, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{NodeId: "agent-2", ActiveTasks: 3}); err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+	submitted, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	// The busier agent polls first, but the task is placed on the idle one
+	busy, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if busy.HasTask {
+		t.Fatalf("expected no task for the busier agent, got %s", busy.Task.TaskId)
+	}
+
+	placed, _ := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: submitted.TaskId})
+	if placed.Task.PlacedNodeId != "agent-2" || placed.Task.PlacedBy != "least_loaded" {
+		t.Errorf("expected task placed on agent-2 by least_loaded, got %q by %q",
+			placed.Task.PlacedNodeId, placed.Task.PlacedBy)
+	}
+
+	idle, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-2"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if !idle.HasTask || idle.Task.TaskId != submitted.TaskId || idle.Task.AssignedNodeId != "agent-2" {
+		t.Errorf("expected task %s assigned to agent-2, got %v", submitted.TaskId, idle)
+	}
+}
+
+func TestPollTask_SkipsNodesWithoutResources(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	submitted, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{
+		TaskType:  "matmul",
+		Resources: &pb.Resources{CpuCores: 8, MemoryMb: 64 * 1024},
+	})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	for nodeID, memoryMb := range map[string]int64{"small-vm": 16 * 1024, "big-vm": 128 * 1024} {
+		_, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{
+			NodeId:   nodeID,
+			Capacity: &pb.Resources{CpuCores: 16, MemoryMb: memoryMb},
+		})
+		if err != nil {
+			t.Fatalf("Heartbeat(%s) returned error: %v", nodeID, err)
+		}
+	}
+
+	small, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "small-vm"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if small.HasTask {
+		t.Fatalf("expected no task for small-vm, got %s", small.Task.TaskId)
+	}
+
+	big, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "big-vm"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if !big.HasTask || big.Task.TaskId != submitted.TaskId {
+		t.Fatalf("expected task %s on big-vm, got %v", submitted.TaskId, big)
+	}
+
+	node, _ := s.cluster.GetFSM().GetNode("big-vm")
+	if node.Allocated.GetMemoryMb() != 64*1024 {
+		t.Errorf("expected 64 GB allocated on big-vm, got %v", node.Allocated)
+	}
+}
+
+func TestPollTask_PreemptsLowerPriorityWork(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{NodeId: "agent-1", Capacity: &pb.Resources{CpuCores: 4}}); err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{
+		TaskType:      "matmul",
+		PriorityClass: "low",
+		Resources:     &pb.Resources{CpuCores: 4},
+	}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	batch, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil || !batch.HasTask {
+		t.Fatalf("expected the low-priority task, got %v, %v", batch, err)
+	}
+
+	urgent, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{
+		TaskType:      "matmul",
+		PriorityClass: "critical",
+		Resources:     &pb.Resources{CpuCores: 4},
+	})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if !poll.HasTask || poll.Task.TaskId != urgent.TaskId {
+		t.Fatalf("expected the critical task %s, got %v", urgent.TaskId, poll)
+	}
+
+	// The agent learns about the preemption on its next heartbeat
+	resp, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{
+		NodeId: "agent-1",
+		HeldLeases: []*pb.TaskLease{
+			{TaskId: batch.Task.TaskId, FencingToken: batch.Task.FencingToken},
+			{TaskId: urgent.TaskId, FencingToken: poll.Task.FencingToken},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+	if len(resp.PreemptedTaskIds) != 1 || resp.PreemptedTaskIds[0] != batch.Task.TaskId {
+		t.Errorf("expected %s preempted, got %v", batch.Task.TaskId, resp.PreemptedTaskIds)
+	}
+	if len(resp.RevokedTaskIds) != 1 || resp.RevokedTaskIds[0] != batch.Task.TaskId {
+		t.Errorf("expected %s revoked, got %v", batch.Task.TaskId, resp.RevokedTaskIds)
+	}
+
+	requeued, _ := s.cluster.GetFSM().GetTask(batch.Task.TaskId)
+	if requeued.Status != pb.TaskStatus_PENDING || requeued.Attempt != 0 {
+		t.Errorf("expected preempted task PENDING on attempt 0, got %s on attempt %d", requeued.Status, requeued.Attempt)
+	}
+}
@@ -0,0 +1,474 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ml-raft-control-plane/internal/models"
+	"ml-raft-control-plane/internal/raft"
+	"ml-raft-control-plane/internal/scheduler"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxTaskIDLength bounds client-supplied task IDs
+const maxTaskIDLength = 128
+
+// maxIdempotencyKeyLength bounds client-supplied idempotency keys
+const maxIdempotencyKeyLength = 128
+
+// MaxBatchBytes bounds the encoded size of one SubmitTasks or SubmitJob
+// request, and so the size of the log entry that creates its tasks and how
+// long applying it holds the FSM lock. About 18000 planned matmul tasks fit.
+const MaxBatchBytes = 8 << 20
+
+// MaxRecvMsgSize is the gRPC receive limit a server needs so that requests
+// up to MaxBatchBytes reach the handlers, which reject larger ones by name
+const MaxRecvMsgSize = MaxBatchBytes + 1<<20
+
+// maxReportedTaskErrors bounds how many invalid tasks of a batch are named
+// in the error returned for it
+const maxReportedTaskErrors = 10
+
+// SubmitTask creates a new PENDING task through Raft consensus. A task with
+// dependencies is BLOCKED until they all complete. A retry carrying the
+// idempotency key of an earlier submission returns the task it created.
+func (s *Server) SubmitTask(ctx context.Context, req *pb.SubmitTaskRequest) (*pb.SubmitTaskResponse, error) {
+	entry, err := newTaskEntry(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.SubmitTaskResponse{
+				Success:       false,
+				ErrorMessage:  "not the leader",
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewTaskServiceClient(conn).SubmitTask(forwardCtx, req)
+	}
+
+	fsm := s.cluster.GetFSM()
+	if entry.IdempotencyKey != "" {
+		if taskID, found := fsm.SubmittedTask(entry.IdempotencyKey); found {
+			return &pb.SubmitTaskResponse{
+				TaskId:  taskID,
+				Success: true,
+			}, nil
+		}
+	}
+
+	if err := s.checkDependencies([]raft.AddTaskEntry{entry}); err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, raft.LogEntryAddTask, entry); err != nil {
+		return nil, err
+	}
+
+	// A concurrent retry may have committed first, in which case this entry
+	// was a no-op and the key names the task that retry created
+	taskID := entry.TaskID
+	if entry.IdempotencyKey != "" {
+		if original, found := fsm.SubmittedTask(entry.IdempotencyKey); found {
+			taskID = original
+		}
+	}
+
+	return &pb.SubmitTaskResponse{
+		TaskId:  taskID,
+		Success: true,
+	}, nil
+}
+
+// SubmitTasks creates many tasks in a single log entry, so either every task
+// is created or none is. Tasks may depend on each other and on existing tasks.
+func (s *Server) SubmitTasks(ctx context.Context, req *pb.SubmitTasksRequest) (*pb.SubmitTasksResponse, error) {
+	if err := checkBatchSize(req); err != nil {
+		return nil, err
+	}
+
+	entry := raft.AddTasksEntry{}
+	var err error
+	if entry.Tasks, err = newBatchEntries(req.Tasks, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.SubmitTasksResponse{
+				Success:       false,
+				ErrorMessage:  "not the leader",
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewTaskServiceClient(conn).SubmitTasks(forwardCtx, req)
+	}
+
+	if err := s.checkDependencies(entry.Tasks); err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, raft.LogEntryAddTasks, entry); err != nil {
+		return nil, err
+	}
+
+	taskIDs := make([]string, len(entry.Tasks))
+	for i, taskEntry := range entry.Tasks {
+		taskIDs[i] = taskEntry.TaskID
+	}
+	return &pb.SubmitTasksResponse{
+		TaskIds: taskIDs,
+		Success: true,
+	}, nil
+}
+
+// checkBatchSize rejects a SubmitTasks or SubmitJob request larger than
+// MaxBatchBytes
+func checkBatchSize(req proto.Message) error {
+	if size := proto.Size(req); size > MaxBatchBytes {
+		return status.Errorf(codes.InvalidArgument, "at most %d bytes of tasks may be submitted at once, got %d", MaxBatchBytes, size)
+	}
+	return nil
+}
+
+// newBatchEntries validates every task of a batch submission and builds
+// their log entries. The error names each invalid task by its index, up to
+// maxReportedTaskErrors of them.
+func newBatchEntries(reqs []*pb.SubmitTaskRequest, now time.Time) ([]raft.AddTaskEntry, error) {
+	if len(reqs) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one task is required")
+	}
+
+	entries := make([]raft.AddTaskEntry, len(reqs))
+	var problems []string
+	invalid := 0
+	for i, req := range reqs {
+		var err error
+		if req.IdempotencyKey != "" {
+			err = status.Error(codes.InvalidArgument, "idempotency_key is not supported in batches")
+		} else {
+			entries[i], err = newTaskEntry(req, now)
+		}
+		if err == nil {
+			continue
+		}
+		invalid++
+		if len(problems) < maxReportedTaskErrors {
+			problems = append(problems, fmt.Sprintf("tasks[%d]: %s", i, status.Convert(err).Message()))
+		}
+	}
+
+	if invalid == 0 {
+		return entries, nil
+	}
+	if invalid > len(problems) {
+		problems = append(problems, fmt.Sprintf("and %d more", invalid-len(problems)))
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "%d of %d tasks are invalid: %s", invalid, len(reqs), strings.Join(problems, "; "))
+}
+
+// newTaskEntry validates a task submission and builds the log entry that
+// creates it, generating a task ID unless the request carries one
+func newTaskEntry(req *pb.SubmitTaskRequest, now time.Time) (raft.AddTaskEntry, error) {
+	if len(req.TaskId) > maxTaskIDLength {
+		return raft.AddTaskEntry{}, status.Errorf(codes.InvalidArgument, "task_id must be at most %d bytes", maxTaskIDLength)
+	}
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return raft.AddTaskEntry{}, status.Errorf(codes.InvalidArgument, "idempotency_key must be at most %d bytes", maxIdempotencyKeyLength)
+	}
+	if req.TaskType == "" {
+		return raft.AddTaskEntry{}, status.Error(codes.InvalidArgument, "task_type is required")
+	}
+	if len(req.TaskData) > 0 && !json.Valid(req.TaskData) {
+		return raft.AddTaskEntry{}, status.Error(codes.InvalidArgument, "task_data must be valid JSON")
+	}
+	if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+		return raft.AddTaskEntry{}, err
+	}
+	if err := validateResources(req.Resources, "resources"); err != nil {
+		return raft.AddTaskEntry{}, err
+	}
+	if err := validateAffinity(req.Affinity); err != nil {
+		return raft.AddTaskEntry{}, err
+	}
+	priority, err := resolvePriority(req)
+	if err != nil {
+		return raft.AddTaskEntry{}, err
+	}
+
+	for _, dependency := range req.DependsOn {
+		if dependency == "" {
+			return raft.AddTaskEntry{}, status.Error(codes.InvalidArgument, "depends_on must not contain empty task IDs")
+		}
+		if dependency == req.TaskId {
+			return raft.AddTaskEntry{}, status.Errorf(codes.InvalidArgument, "task %s depends on itself", req.TaskId)
+		}
+	}
+
+	entry := raft.AddTaskEntry{
+		TaskID:         req.TaskId,
+		TaskType:       req.TaskType,
+		CreatedAt:      now.Unix(),
+		RetryPolicy:    req.RetryPolicy,
+		Resources:      req.Resources,
+		Affinity:       req.Affinity,
+		Priority:       priority,
+		PriorityClass:  req.PriorityClass,
+		DependsOn:      req.DependsOn,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	if entry.TaskID == "" {
+		entry.TaskID = uuid.NewString()
+	}
+	if len(req.TaskData) > 0 {
+		entry.TaskData = json.RawMessage(req.TaskData)
+	}
+	return entry, nil
+}
+
+// checkDependencies rejects submissions whose dependencies name tasks that
+// neither exist nor are part of the submission, or form a cycle. It must run
+// on the leader, whose FSM knows every committed task.
+func (s *Server) checkDependencies(entries []raft.AddTaskEntry) error {
+	fsm := s.cluster.GetFSM()
+	graph := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		if _, duplicate := graph[entry.TaskID]; duplicate {
+			return status.Errorf(codes.InvalidArgument, "task_id %s is used twice", entry.TaskID)
+		}
+		graph[entry.TaskID] = entry.DependsOn
+	}
+
+	for _, entry := range entries {
+		for _, dependency := range entry.DependsOn {
+			if _, submitted := graph[dependency]; submitted {
+				continue
+			}
+			if _, found := fsm.GetTask(dependency); !found {
+				return status.Errorf(codes.InvalidArgument, "task %s depends on unknown task %s", entry.TaskID, dependency)
+			}
+		}
+	}
+
+	// Committed tasks never depend on new ones, so a cycle lies within the submission
+	if cycle := models.DependencyCycle(graph); cycle != nil {
+		return status.Errorf(codes.InvalidArgument, "dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// GetTask returns a single task from the local FSM
+func (s *Server) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.GetTaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+
+	task, found := s.cluster.GetFSM().GetTask(req.TaskId)
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "task %s not found", req.TaskId)
+	}
+
+	return &pb.GetTaskResponse{
+		Task:  task,
+		Found: true,
+	}, nil
+}
+
+// ListTasks returns tasks from the local FSM ordered by creation time and
+// explains why any PENDING task among them cannot be placed
+func (s *Server) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	fsm := s.cluster.GetFSM()
+	tasks := fsm.ListTasks(req.StatusFilter, int(req.Limit))
+	resp := &pb.ListTasksResponse{
+		Tasks: tasks,
+	}
+
+	var nodes []*pb.Node
+	for _, task := range tasks {
+		if task.Status != pb.TaskStatus_PENDING {
+			continue
+		}
+		if nodes == nil {
+			nodes = fsm.HealthyNodes()
+		}
+		if reason := scheduler.Explain(task, nodes); reason != "" {
+			if resp.Unschedulable == nil {
+				resp.Unschedulable = make(map[string]string)
+			}
+			resp.Unschedulable[task.TaskId] = reason
+		}
+	}
+
+	return resp, nil
+}
+
+// CancelTask cancels a task that has not finished. PENDING and BLOCKED tasks
+// are CANCELLED at once; ASSIGNED and RUNNING tasks are CANCELLING until the
+// agent, told through Heartbeat or PollTask, acknowledges with
+// ReportTaskResult.
+func (s *Server) CancelTask(ctx context.Context, req *pb.CancelTaskRequest) (*pb.CancelTaskResponse, error) {
+	if req.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id is required")
+	}
+
+	if !s.cluster.IsLeader() {
+		if s.config.ForwardMode == ForwardRedirect {
+			leaderAddr, err := s.leaderGRPCAddress()
+			if err != nil {
+				return nil, err
+			}
+			return &pb.CancelTaskResponse{
+				Acknowledged:  false,
+				LeaderAddress: leaderAddr,
+			}, nil
+		}
+
+		conn, forwardCtx, err := s.leaderConn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return pb.NewTaskServiceClient(conn).CancelTask(forwardCtx, req)
+	}
+
+	entry := raft.CancelTaskEntry{
+		TaskID:      req.TaskId,
+		CancelledAt: time.Now().Unix(),
+	}
+	if err := s.apply(ctx, raft.LogEntryCancelTask, entry); err != nil {
+		return nil, err
+	}
+
+	task, found := s.cluster.GetFSM().GetTask(req.TaskId)
+	if !found {
+		return nil, status.Errorf(codes.Internal, "cancelled task %s disappeared", req.TaskId)
+	}
+
+	return &pb.CancelTaskResponse{
+		Acknowledged: true,
+		Status:       task.Status,
+	}, nil
+}
+
+// validateRetryPolicy rejects retry policies that cannot be applied
+func validateRetryPolicy(policy *pb.RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 1 {
+		return status.Error(codes.InvalidArgument, "retry_policy.max_attempts must be at least 1")
+	}
+	if policy.InitialBackoffMs < 0 || policy.MaxBackoffMs < 0 {
+		return status.Error(codes.InvalidArgument, "retry_policy backoffs must not be negative")
+	}
+	if policy.BackoffMultiplier != 0 && policy.BackoffMultiplier < 1 {
+		return status.Error(codes.InvalidArgument, "retry_policy.backoff_multiplier must be at least 1")
+	}
+	return nil
+}
+
+// validateResources rejects negative resource amounts
+func validateResources(resources *pb.Resources, field string) error {
+	if resources == nil {
+		return nil
+	}
+	if resources.CpuCores < 0 || resources.MemoryMb < 0 || resources.Accelerators < 0 {
+		return status.Errorf(codes.InvalidArgument, "%s must not be negative", field)
+	}
+	if resources.AcceleratorType != "" && resources.Accelerators == 0 {
+		return status.Errorf(codes.InvalidArgument, "%s.accelerator_type requires accelerators", field)
+	}
+	return nil
+}
+
+// validateAffinity rejects malformed label expressions
+func validateAffinity(affinity *pb.Affinity) error {
+	if affinity == nil {
+		return nil
+	}
+
+	for _, exprs := range [][]*pb.LabelExpression{affinity.Required, affinity.RequiredAnti} {
+		for _, expr := range exprs {
+			if err := validateLabelExpression(expr); err != nil {
+				return err
+			}
+		}
+	}
+	for _, prefs := range [][]*pb.WeightedLabelExpression{affinity.Preferred, affinity.PreferredAnti} {
+		for _, pref := range prefs {
+			if err := validateLabelExpression(pref.GetExpression()); err != nil {
+				return err
+			}
+			if pref.Weight <= 0 {
+				return status.Error(codes.InvalidArgument, "affinity preference weight must be positive")
+			}
+		}
+	}
+	return nil
+}
+
+// validateLabelExpression checks that an expression has a key and values matching its operator
+func validateLabelExpression(expr *pb.LabelExpression) error {
+	if expr == nil || expr.Key == "" {
+		return status.Error(codes.InvalidArgument, "affinity expression key is required")
+	}
+
+	switch expr.Operator {
+	case pb.LabelOperator_IN, pb.LabelOperator_NOT_IN:
+		if len(expr.Values) == 0 {
+			return status.Errorf(codes.InvalidArgument, "affinity expression %s requires values", models.DescribeExpression(expr))
+		}
+	case pb.LabelOperator_EXISTS, pb.LabelOperator_DOES_NOT_EXIST:
+		if len(expr.Values) > 0 {
+			return status.Errorf(codes.InvalidArgument, "affinity expression %s takes no values", models.DescribeExpression(expr))
+		}
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown affinity operator %d", expr.Operator)
+	}
+	return nil
+}
+
+// resolvePriority returns the priority requested either explicitly or through a priority class
+func resolvePriority(req *pb.SubmitTaskRequest) (int32, error) {
+	if req.PriorityClass == "" {
+		return req.Priority, nil
+	}
+	if req.Priority != 0 {
+		return 0, status.Error(codes.InvalidArgument, "priority and priority_class are mutually exclusive")
+	}
+
+	priority, ok := models.PriorityClasses[req.PriorityClass]
+	if !ok {
+		return 0, status.Errorf(codes.InvalidArgument, "unknown priority_class %q", req.PriorityClass)
+	}
+	return priority, nil
+}
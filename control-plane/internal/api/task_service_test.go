@@ -0,0 +1,350 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubmitTask_GetTask(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	resp, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{
+		TaskType: "matmul",
+		TaskData: []byte(`{"block": [0, 0]}`),
+	})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	if resp.TaskId == "" || !resp.Success {
+		t.Fatalf("unexpected SubmitTask response: %v", resp)
+	}
+
+	got, err := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: resp.TaskId})
+	if err != nil {
+		t.Fatalf("GetTask() returned error: %v", err)
+	}
+	if got.Task.Status != pb.TaskStatus_PENDING {
+		t.Errorf("expected task status PENDING, got %s", got.Task.Status)
+	}
+	if got.Task.TaskType != "matmul" {
+		t.Errorf("expected task type matmul, got %s", got.Task.TaskType)
+	}
+	if got.Task.CreatedAt == 0 {
+		t.Error("expected created_at to be stamped")
+	}
+}
+
+func TestSubmitTask_InvalidArgument(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *pb.SubmitTaskRequest
+	}{
+		{"missing task type", &pb.SubmitTaskRequest{}},
+		{"invalid task data", &pb.SubmitTaskRequest{TaskType: "matmul", TaskData: []byte("not json")}},
+		{"negative resources", &pb.SubmitTaskRequest{TaskType: "matmul", Resources: &pb.Resources{MemoryMb: -1}}},
+		{"accelerator type without count", &pb.SubmitTaskRequest{TaskType: "matmul", Resources: &pb.Resources{AcceleratorType: "nvidia-a100"}}},
+		{"unknown priority class", &pb.SubmitTaskRequest{TaskType: "matmul", PriorityClass: "urgent"}},
+		{"priority and priority class", &pb.SubmitTaskRequest{TaskType: "matmul", Priority: 5, PriorityClass: "high"}},
+		{"unknown dependency", &pb.SubmitTaskRequest{TaskType: "reduce", DependsOn: []string{"missing"}}},
+		{"self dependency", &pb.SubmitTaskRequest{TaskId: "reduce", TaskType: "reduce", DependsOn: []string{"reduce"}}},
+		{"idempotency key too long", &pb.SubmitTaskRequest{TaskType: "matmul", IdempotencyKey: strings.Repeat("k", maxIdempotencyKeyLength+1)}},
+		{"affinity without values", &pb.SubmitTaskRequest{TaskType: "matmul", Affinity: &pb.Affinity{
+			Required: []*pb.LabelExpression{{Key: "cloud", Operator: pb.LabelOperator_IN}},
+		}}},
+		{"affinity without weight", &pb.SubmitTaskRequest{TaskType: "matmul", Affinity: &pb.Affinity{
+			Preferred: []*pb.WeightedLabelExpression{{Expression: &pb.LabelExpression{Key: "gpu", Operator: pb.LabelOperator_EXISTS}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SubmitTask(ctx, tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSubmitTask_IdempotencyKey(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	first, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul", IdempotencyKey: "req-1"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	retry, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul", IdempotencyKey: "req-1"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	if retry.TaskId != first.TaskId {
+		t.Errorf("expected the retry to return %s, got %s", first.TaskId, retry.TaskId)
+	}
+
+	other, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul", IdempotencyKey: "req-2"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	if other.TaskId == first.TaskId {
+		t.Error("expected a different key to create a new task")
+	}
+
+	all, err := s.ListTasks(ctx, &pb.ListTasksRequest{})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if len(all.Tasks) != 2 {
+		t.Errorf("expected 2 tasks, got %d", len(all.Tasks))
+	}
+}
+
+func TestSubmitTasks(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	resp, err := s.SubmitTasks(ctx, &pb.SubmitTasksRequest{Tasks: []*pb.SubmitTaskRequest{
+		{TaskId: "reduce", TaskType: "reduce", DependsOn: []string{"map"}},
+		{TaskId: "map", TaskType: "map"},
+		{TaskType: "eval"},
+	}})
+	if err != nil {
+		t.Fatalf("SubmitTasks() returned error: %v", err)
+	}
+	if !resp.Success || len(resp.TaskIds) != 3 || resp.TaskIds[0] != "reduce" || resp.TaskIds[1] != "map" || resp.TaskIds[2] == "" {
+		t.Fatalf("unexpected SubmitTasks response: %v", resp)
+	}
+
+	got, err := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: "reduce"})
+	if err != nil {
+		t.Fatalf("GetTask() returned error: %v", err)
+	}
+	if got.Task.Status != pb.TaskStatus_BLOCKED {
+		t.Errorf("expected reduce to be BLOCKED, got %s", got.Task.Status)
+	}
+
+	// A task that already exists rejects the whole batch
+	_, err = s.SubmitTasks(ctx, &pb.SubmitTasksRequest{Tasks: []*pb.SubmitTaskRequest{
+		{TaskId: "fresh", TaskType: "map"},
+		{TaskId: "map", TaskType: "map"},
+	}})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists, got %v", err)
+	}
+	if _, err := s.GetTask(ctx, &pb.GetTaskRequest{TaskId: "fresh"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected no task of a rejected batch to exist, got %v", err)
+	}
+}
+
+func TestSubmitTasks_InvalidArgument(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	tooLarge := []*pb.SubmitTaskRequest{{TaskType: "map", TaskData: []byte(`"` + strings.Repeat("x", MaxBatchBytes) + `"`)}}
+	manyInvalid := make([]*pb.SubmitTaskRequest, maxReportedTaskErrors+3)
+	for i := range manyInvalid {
+		manyInvalid[i] = &pb.SubmitTaskRequest{}
+	}
+
+	tests := []struct {
+		name    string
+		tasks   []*pb.SubmitTaskRequest
+		message string
+	}{
+		{"no tasks", nil, "at least one task is required"},
+		{"too many bytes", tooLarge, "at most 8388608 bytes of tasks may be submitted at once"},
+		{"invalid tasks", []*pb.SubmitTaskRequest{
+			{TaskType: "map"},
+			{},
+			{TaskType: "map", TaskData: []byte("not json")},
+			{TaskType: "map", IdempotencyKey: "req-1"},
+		}, "3 of 4 tasks are invalid: tasks[1]: task_type is required; tasks[2]: task_data must be valid JSON; " +
+			"tasks[3]: idempotency_key is not supported in batches"},
+		{"more invalid tasks than reported", manyInvalid, "and 3 more"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.SubmitTasks(ctx, &pb.SubmitTasksRequest{Tasks: tt.tasks})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("expected InvalidArgument, got %v", err)
+			}
+			if msg := status.Convert(err).Message(); !strings.Contains(msg, tt.message) {
+				t.Errorf("expected %q in %q", tt.message, msg)
+			}
+		})
+	}
+}
+
+func TestGetTask_NotFound(t *testing.T) {
+	s := setupServer(t)
+
+	_, err := s.GetTask(context.Background(), &pb.GetTaskRequest{TaskId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestCancelTask(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+	heartbeat(t, s, "agent-1")
+
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskId: "running", TaskType: "train"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil || !poll.HasTask {
+		t.Fatalf("expected a task, got %v, %v", poll, err)
+	}
+	running := poll.Task
+	if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskId: "queued", TaskType: "train"}); err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	resp, err := s.CancelTask(ctx, &pb.CancelTaskRequest{TaskId: "queued"})
+	if err != nil {
+		t.Fatalf("CancelTask() returned error: %v", err)
+	}
+	if resp.Status != pb.TaskStatus_CANCELLED {
+		t.Errorf("expected the PENDING task CANCELLED, got %s", resp.Status)
+	}
+
+	resp, err = s.CancelTask(ctx, &pb.CancelTaskRequest{TaskId: running.TaskId})
+	if err != nil {
+		t.Fatalf("CancelTask() returned error: %v", err)
+	}
+	if resp.Status != pb.TaskStatus_CANCELLING {
+		t.Errorf("expected the assigned task CANCELLING, got %s", resp.Status)
+	}
+
+	// The agent learns of the cancellation when it polls
+	next, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-1"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if next.HasTask || len(next.CancelledTaskIds) != 1 || next.CancelledTaskIds[0] != running.TaskId {
+		t.Errorf("expected no task and %s cancelled, got %v", running.TaskId, next)
+	}
+
+	if _, err := s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       running.TaskId,
+		FinalStatus:  pb.TaskStatus_COMPLETED,
+		FencingToken: running.FencingToken,
+	}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition completing a cancelling task, got %v", err)
+	}
+	if _, err := s.ReportTaskResult(ctx, &pb.ReportTaskResultRequest{
+		TaskId:       running.TaskId,
+		FinalStatus:  pb.TaskStatus_CANCELLED,
+		FencingToken: running.FencingToken,
+	}); err != nil {
+		t.Fatalf("ReportTaskResult() returned error: %v", err)
+	}
+
+	task, _ := s.cluster.GetFSM().GetTask(running.TaskId)
+	if task.Status != pb.TaskStatus_CANCELLED {
+		t.Errorf("expected CANCELLED after acknowledgement, got %s", task.Status)
+	}
+	node, _ := s.cluster.GetFSM().GetNode("agent-1")
+	if node.ActiveTasks != 0 {
+		t.Errorf("expected agent-1 released, got %d active tasks", node.ActiveTasks)
+	}
+
+	_, err = s.CancelTask(ctx, &pb.CancelTaskRequest{TaskId: running.TaskId})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition cancelling a finished task, got %v", err)
+	}
+	_, err = s.CancelTask(ctx, &pb.CancelTaskRequest{TaskId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown task, got %v", err)
+	}
+}
+
+func TestListTasks_FilterAndLimit(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"}); err != nil {
+			t.Fatalf("SubmitTask() returned error: %v", err)
+		}
+	}
+
+	all, err := s.ListTasks(ctx, &pb.ListTasksRequest{})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if len(all.Tasks) != 3 {
+		t.Errorf("expected 3 tasks, got %d", len(all.Tasks))
+	}
+
+	limited, err := s.ListTasks(ctx, &pb.ListTasksRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if len(limited.Tasks) != 2 {
+		t.Errorf("expected 2 tasks, got %d", len(limited.Tasks))
+	}
+
+	completed := pb.TaskStatus_COMPLETED
+	filtered, err := s.ListTasks(ctx, &pb.ListTasksRequest{StatusFilter: &completed})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if len(filtered.Tasks) != 0 {
+		t.Errorf("expected no COMPLETED tasks, got %d", len(filtered.Tasks))
+	}
+}
+
+func TestListTasks_ExplainsUnschedulable(t *testing.T) {
+	s := setupServer(t)
+	ctx := context.Background()
+
+	if _, err := s.Heartbeat(ctx, &pb.HeartbeatRequest{NodeId: "agent-aws", CloudProvider: "aws"}); err != nil {
+		t.Fatalf("Heartbeat() returned error: %v", err)
+	}
+
+	gcpOnly, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{
+		TaskType: "matmul",
+		Affinity: &pb.Affinity{
+			Required: []*pb.LabelExpression{{Key: "cloud", Operator: pb.LabelOperator_IN, Values: []string{"gcp"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+	anywhere, err := s.SubmitTask(ctx, &pb.SubmitTaskRequest{TaskType: "matmul"})
+	if err != nil {
+		t.Fatalf("SubmitTask() returned error: %v", err)
+	}
+
+	resp, err := s.ListTasks(ctx, &pb.ListTasksRequest{})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	if want := "0/1 nodes available: 1 does not match cloud in [gcp]"; resp.Unschedulable[gcpOnly.TaskId] != want {
+		t.Errorf("expected %q for the gcp-only task, got %q", want, resp.Unschedulable[gcpOnly.TaskId])
+	}
+	if reason, found := resp.Unschedulable[anywhere.TaskId]; found {
+		t.Errorf("expected unconstrained task to be schedulable, got %q", reason)
+	}
+
+	poll, err := s.PollTask(ctx, &pb.PollTaskRequest{NodeId: "agent-aws"})
+	if err != nil {
+		t.Fatalf("PollTask() returned error: %v", err)
+	}
+	if !poll.HasTask || poll.Task.TaskId != anywhere.TaskId {
+		t.Errorf("expected agent-aws to skip the gcp-only task and get %s, got %v", anywhere.TaskId, poll)
+	}
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// Built-in node label keys derived from node metadata
+const (
+	LabelCloud  = "cloud"
+	LabelRegion = "region"
+)
+
+// IsBuiltinLabel reports whether a label key is reserved for node metadata
+func IsBuiltinLabel(key string) bool {
+	return key == LabelCloud || key == LabelRegion
+}
+
+// NodeLabels returns a node's free-form labels together with the built-in
+// cloud and region labels
+func NodeLabels(node *pb.Node) map[string]string {
+	labels := make(map[string]string, len(node.Labels)+2)
+	for key, value := range node.Labels {
+		labels[key] = value
+	}
+	if node.CloudProvider != "" {
+		labels[LabelCloud] = node.CloudProvider
+	}
+	if node.Region != "" {
+		labels[LabelRegion] = node.Region
+	}
+	return labels
+}
+
+// MatchesExpression reports whether a set of labels satisfies an expression
+func MatchesExpression(labels map[string]string, expr *pb.LabelExpression) bool {
+	value, exists := labels[expr.Key]
+	switch expr.Operator {
+	case pb.LabelOperator_IN:
+		return exists && containsString(expr.Values, value)
+	case pb.LabelOperator_NOT_IN:
+		return !exists || !containsString(expr.Values, value)
+	case pb.LabelOperator_EXISTS:
+		return exists
+	case pb.LabelOperator_DOES_NOT_EXIST:
+		return !exists
+	default:
+		return false
+	}
+}
+
+// AffinityMismatch describes the first required affinity or anti-affinity
+// expression a node violates, or returns "" if the node is acceptable
+func AffinityMismatch(node *pb.Node, affinity *pb.Affinity) string {
+	if affinity == nil {
+		return ""
+	}
+
+	labels := NodeLabels(node)
+	for _, expr := range affinity.Required {
+		if !MatchesExpression(labels, expr) {
+			return "does not match " + DescribeExpression(expr)
+		}
+	}
+	for _, expr := range affinity.RequiredAnti {
+		if MatchesExpression(labels, expr) {
+			return "matches anti-affinity " + DescribeExpression(expr)
+		}
+	}
+	return ""
+}
+
+// PreferenceScore sums the weights of the preferred expressions a node
+// matches, minus those of the preferred anti-affinity expressions
+func PreferenceScore(node *pb.Node, affinity *pb.Affinity) int64 {
+	if affinity == nil {
+		return 0
+	}
+
+	labels := NodeLabels(node)
+	var score int64
+	for _, pref := range affinity.Preferred {
+		if MatchesExpression(labels, pref.Expression) {
+			score += int64(pref.Weight)
+		}
+	}
+	for _, pref := range affinity.PreferredAnti {
+		if MatchesExpression(labels, pref.Expression) {
+			score -= int64(pref.Weight)
+		}
+	}
+	return score
+}
+
+// UnfitReason explains why a task cannot run on a node, or returns "" if it can
+func UnfitReason(node *pb.Node, task *pb.Task) string {
+	if !Fits(node, task.Resources) {
+		return "insufficient resources"
+	}
+	return AffinityMismatch(node, task.Affinity)
+}
+
+// DescribeExpression renders an expression such as `cloud in [gcp]`
+func DescribeExpression(expr *pb.LabelExpression) string {
+	op := strings.ToLower(strings.ReplaceAll(expr.Operator.String(), "_", " "))
+	if len(expr.Values) == 0 {
+		return fmt.Sprintf("%s %s", expr.Key, op)
+	}
+	return fmt.Sprintf("%s %s [%s]", expr.Key, op, strings.Join(expr.Values, ", "))
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNodeTopology records the cloud, region and labels a node advertises.
+// Empty values keep what was previously recorded.
+func (tm *TaskManifest) SetNodeTopology(nodeID, cloudProvider, region string, labels map[string]string) bool {
+	node, exists := tm.Nodes[nodeID]
+	if !exists {
+		return false
+	}
+
+	if cloudProvider != "" {
+		node.CloudProvider = cloudProvider
+	}
+	if region != "" {
+		node.Region = region
+	}
+	if len(labels) > 0 {
+		node.Labels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			node.Labels[key] = value
+		}
+	}
+	return true
+}
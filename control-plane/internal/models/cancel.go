@@ -0,0 +1,94 @@
+package models
+
+import (
+	"sort"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// cancellingIndex keeps the IDs of CANCELLING tasks by the node holding
+// them, so heartbeats and polls never scan the whole manifest
+type cancellingIndex map[string]map[string]bool
+
+// insert adds a task to the index of its node
+func (idx *cancellingIndex) insert(task *pb.Task) {
+	if *idx == nil {
+		*idx = make(cancellingIndex)
+	}
+	if (*idx)[task.AssignedNodeId] == nil {
+		(*idx)[task.AssignedNodeId] = make(map[string]bool)
+	}
+	(*idx)[task.AssignedNodeId][task.TaskId] = true
+}
+
+// remove drops a task from the index of its node
+func (idx *cancellingIndex) remove(task *pb.Task) {
+	taskIDs := (*idx)[task.AssignedNodeId]
+	delete(taskIDs, task.TaskId)
+	if len(taskIDs) == 0 {
+		delete(*idx, task.AssignedNodeId)
+	}
+}
+
+// CancellingTasks returns the IDs of the CANCELLING tasks assigned to a
+// node, ordered by task ID
+func (tm *TaskManifest) CancellingTasks(nodeID string) []string {
+	taskIDs := tm.cancelling[nodeID]
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	cancelling := make([]string, 0, len(taskIDs))
+	for taskID := range taskIDs {
+		cancelling = append(cancelling, taskID)
+	}
+	sort.Strings(cancelling)
+	return cancelling
+}
+
+// IsCancellable reports whether a task may still be cancelled
+func IsCancellable(task *pb.Task) bool {
+	return task.Status != pb.TaskStatus_CANCELLING &&
+		(CanTransition(task.Status, pb.TaskStatus_CANCELLED) || CanTransition(task.Status, pb.TaskStatus_CANCELLING))
+}
+
+// CancelTask cancels a task that has not finished and returns its new
+// status. Tasks held by a node move to CANCELLING and keep the node's slot
+// until the agent acknowledges; all others move straight to CANCELLED.
+func (tm *TaskManifest) CancelTask(taskID string, cancelledAt int64) (pb.TaskStatus, bool) {
+	task, exists := tm.Tasks[taskID]
+	if !exists {
+		return pb.TaskStatus_PENDING, false
+	}
+
+	if task.Status == pb.TaskStatus_ASSIGNED || task.Status == pb.TaskStatus_RUNNING {
+		tm.setStatus(task, pb.TaskStatus_CANCELLING)
+		return task.Status, true
+	}
+	tm.finishCancel(task, cancelledAt)
+	return task.Status, true
+}
+
+// AcknowledgeCancel moves a CANCELLING task to CANCELLED once its agent
+// stopped working on it, releasing the node's slot and resources
+func (tm *TaskManifest) AcknowledgeCancel(taskID string, cancelledAt int64) bool {
+	task, exists := tm.Tasks[taskID]
+	if !exists {
+		return false
+	}
+	tm.finishCancel(task, cancelledAt)
+	return true
+}
+
+// finishCancel moves a task to CANCELLED and fails the tasks depending on it
+func (tm *TaskManifest) finishCancel(task *pb.Task, cancelledAt int64) {
+	if IsLeased(task) {
+		tm.releaseNode(task)
+	}
+	tm.setStatus(task, pb.TaskStatus_CANCELLED)
+	task.CompletedAt = cancelledAt
+	task.LeaseExpiresAt = 0
+	task.PlacedNodeId = ""
+	task.PlacedAt = 0
+	task.PlacedBy = ""
+	tm.finishDependencies(task, cancelledAt)
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"sort"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// ErrorClassDependencyFailed is recorded in the last failure of a BLOCKED
+// task whose dependency failed for good. Such failures are never retried.
+const ErrorClassDependencyFailed = "DEPENDENCY_FAILED"
+
+// BlocksDependents reports whether a task will never complete, so tasks
+// depending on it can never run
+func BlocksDependents(task *pb.Task) bool {
+	return FailedForGood(task) || task.Status == pb.TaskStatus_CANCELLED
+}
+
+// addDependencies records the reverse edges of a task's dependencies so the
+// task is found when one of them finishes
+func (tm *TaskManifest) addDependencies(task *pb.Task) {
+	if len(task.DependsOn) == 0 {
+		return
+	}
+	if tm.dependents == nil {
+		tm.dependents = make(map[string][]string)
+	}
+	for _, dependency := range task.DependsOn {
+		tm.dependents[dependency] = append(tm.dependents[dependency], task.TaskId)
+	}
+}
+
+// resolveBlocked moves a BLOCKED task to PENDING once every dependency
+// COMPLETED, or fails it as soon as one never will. Dependencies that do
+// not exist yet keep it BLOCKED.
+func (tm *TaskManifest) resolveBlocked(task *pb.Task, at int64) {
+	if task.Status != pb.TaskStatus_BLOCKED {
+		return
+	}
+
+	ready := true
+	for _, dependencyID := range task.DependsOn {
+		dependency, exists := tm.Tasks[dependencyID]
+		if !exists {
+			ready = false
+			continue
+		}
+		if BlocksDependents(dependency) {
+			tm.failDependent(task, dependencyID, at)
+			return
+		}
+		if dependency.Status != pb.TaskStatus_COMPLETED {
+			ready = false
+		}
+	}
+	if ready {
+		tm.setStatus(task, pb.TaskStatus_PENDING)
+	}
+}
+
+// finishDependencies re-evaluates the BLOCKED tasks depending on a task that
+// just completed, failed for good or was cancelled
+func (tm *TaskManifest) finishDependencies(task *pb.Task, at int64) {
+	// Edges rebuilt from a snapshot are in a different order than edges
+	// added from the log, so sort them to fail cascades identically
+	dependents := append([]string(nil), tm.dependents[task.TaskId]...)
+	sort.Strings(dependents)
+	for _, dependentID := range dependents {
+		if dependent, exists := tm.Tasks[dependentID]; exists {
+			tm.resolveBlocked(dependent, at)
+		}
+	}
+}
+
+// failDependent fails a BLOCKED task because dependencyID will never
+// complete, and in turn fails everything downstream of it
+func (tm *TaskManifest) failDependent(task *pb.Task, dependencyID string, failedAt int64) {
+	task.LastFailure = &pb.TaskFailure{
+		ErrorMessage: "dependency " + dependencyID + " did not complete",
+		FailedAt:     failedAt,
+		ErrorClass:   ErrorClassDependencyFailed,
+	}
+	task.CompletedAt = failedAt
+	tm.setStatus(task, pb.TaskStatus_FAILED)
+	tm.finishDependencies(task, failedAt)
+}
+
+// DependencyCycle returns the task IDs along a cycle in a dependency graph,
+// given as task ID -> IDs it depends on, or nil if the graph is acyclic.
+// The cycle starts and ends with the same ID.
+func DependencyCycle(graph map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(graph))
+	var path []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case done:
+			return nil
+		case visiting:
+			for i, onPath := range path {
+				if onPath == id {
+					return append(append([]string(nil), path[i:]...), id)
+				}
+			}
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dependency := range graph[id] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+
+	// Visit in a fixed order so the same cycle is reported every time
+	ids := make([]string, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if cycle := visit(id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// IdempotencyRetention is how long a request key is remembered
+	IdempotencyRetention = 24 * time.Hour
+
+	// MaxIdempotencyKeys bounds the number of remembered request keys; the
+	// oldest are forgotten first
+	MaxIdempotencyKeys = 100000
+)
+
+// IdempotencyRecord remembers the task a client-supplied request key applied
+// to and, if the request was rejected, the error it got
+type IdempotencyRecord struct {
+	Key        string `json:"key"`
+	TaskID     string `json:"task_id"`
+	RecordedAt int64  `json:"recorded_at"`
+	Rejection  string `json:"rejection,omitempty"` // Kind of error the request was rejected with
+	Message    string `json:"message,omitempty"`   // Message of that error
+}
+
+// LookupIdempotencyKey returns the record of a request key, if it is still remembered
+func (tm *TaskManifest) LookupIdempotencyKey(key string) (*IdempotencyRecord, bool) {
+	record, exists := tm.idempotencyIndex[key]
+	return record, exists
+}
+
+// RecordIdempotencyKey remembers that a request key applied to a task,
+// first forgetting keys older than the retention or beyond the limit
+func (tm *TaskManifest) RecordIdempotencyKey(key, taskID string, recordedAt int64) {
+	tm.recordIdempotency(&IdempotencyRecord{Key: key, TaskID: taskID, RecordedAt: recordedAt})
+}
+
+// RecordRejectedKey remembers that a request key was rejected for a task
+// with an error of the given kind, so retries get the same error
+func (tm *TaskManifest) RecordRejectedKey(key, taskID, rejection, message string, recordedAt int64) {
+	tm.recordIdempotency(&IdempotencyRecord{
+		Key:        key,
+		TaskID:     taskID,
+		RecordedAt: recordedAt,
+		Rejection:  rejection,
+		Message:    message,
+	})
+}
+
+// recordIdempotency adds a record, first forgetting keys older than the
+// retention or beyond the limit
+func (tm *TaskManifest) recordIdempotency(record *IdempotencyRecord) {
+	cutoff := record.RecordedAt - int64(IdempotencyRetention.Seconds())
+	expired := 0
+	for _, record := range tm.Idempotency {
+		if record.RecordedAt > cutoff && len(tm.Idempotency)-expired < MaxIdempotencyKeys {
+			break
+		}
+		delete(tm.idempotencyIndex, record.Key)
+		expired++
+	}
+	tm.Idempotency = tm.Idempotency[expired:]
+
+	if tm.idempotencyIndex == nil {
+		tm.idempotencyIndex = make(map[string]*IdempotencyRecord)
+	}
+	tm.Idempotency = append(tm.Idempotency, record)
+	tm.idempotencyIndex[record.Key] = record
+}
+
+// ReindexIdempotency rebuilds the key lookup after Idempotency was replaced
+// wholesale, such as when restoring a snapshot
+func (tm *TaskManifest) ReindexIdempotency() {
+	tm.idempotencyIndex = make(map[string]*IdempotencyRecord, len(tm.Idempotency))
+	for _, record := range tm.Idempotency {
+		tm.idempotencyIndex[record.Key] = record
+	}
+}
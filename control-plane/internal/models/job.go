@@ -0,0 +1,113 @@
+package models
+
+import (
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// AddJob adds a job together with its tasks, stamping each task with the job ID
+func (tm *TaskManifest) AddJob(job *pb.Job, tasks []*pb.Task) {
+	job.TaskIds = make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		task.JobId = job.JobId
+		tm.AddTask(task)
+		job.TaskIds = append(job.TaskIds, task.TaskId)
+	}
+	tm.Jobs[job.JobId] = job
+
+	// Tasks may depend on siblings added after them
+	for _, task := range tasks {
+		tm.resolveBlocked(task, job.CreatedAt)
+	}
+}
+
+// GetJob retrieves a job by ID. Its status and progress are not filled in;
+// see SummarizeJob.
+func (tm *TaskManifest) GetJob(jobID string) (*pb.Job, bool) {
+	job, exists := tm.Jobs[jobID]
+	return job, exists
+}
+
+// CancelJob cancels every unfinished task of a job as CancelTask does and
+// returns the IDs of the tasks it cancelled
+func (tm *TaskManifest) CancelJob(jobID string, cancelledAt int64) []string {
+	job, exists := tm.Jobs[jobID]
+	if !exists {
+		return nil
+	}
+
+	var cancelled []string
+	for _, taskID := range job.TaskIds {
+		task, exists := tm.Tasks[taskID]
+		if !exists || !IsCancellable(task) {
+			continue
+		}
+		tm.CancelTask(taskID, cancelledAt)
+		cancelled = append(cancelled, taskID)
+	}
+	job.CancelledAt = cancelledAt
+	return cancelled
+}
+
+// FailedForGood reports whether a task failed and will not be retried
+func FailedForGood(task *pb.Task) bool {
+	if task.Status == pb.TaskStatus_DEAD_LETTER {
+		return true
+	}
+	return task.Status == pb.TaskStatus_FAILED &&
+		(task.RetryPolicy == nil || task.LastFailure.GetErrorClass() == ErrorClassDependencyFailed)
+}
+
+// SummarizeJob fills in a job's progress, status and completion time from
+// the current state of its tasks
+func SummarizeJob(job *pb.Job, tasks map[string]*pb.Task) {
+	progress := &pb.JobProgress{Total: int32(len(job.TaskIds))}
+	started := false
+	var lastCompleted, firstFailed int64
+	for _, taskID := range job.TaskIds {
+		task, exists := tasks[taskID]
+		if !exists {
+			continue
+		}
+		if task.Attempt > 0 {
+			started = true
+		}
+
+		switch {
+		case task.Status == pb.TaskStatus_COMPLETED:
+			progress.Completed++
+			if task.CompletedAt > lastCompleted {
+				lastCompleted = task.CompletedAt
+			}
+		case task.Status == pb.TaskStatus_CANCELLED:
+			progress.Cancelled++
+		case FailedForGood(task):
+			progress.Failed++
+			if firstFailed == 0 || task.CompletedAt < firstFailed {
+				firstFailed = task.CompletedAt
+			}
+		case IsLeased(task):
+			progress.Running++
+		default:
+			progress.Pending++
+		}
+	}
+	job.Progress = progress
+
+	switch {
+	case job.CancelledAt != 0:
+		job.Status = pb.JobStatus_JOB_CANCELLED
+		job.CompletedAt = job.CancelledAt
+	case progress.Failed > 0:
+		job.Status = pb.JobStatus_JOB_FAILED
+		job.CompletedAt = firstFailed
+	case progress.Completed == progress.Total:
+		job.Status = pb.JobStatus_JOB_SUCCEEDED
+		job.CompletedAt = lastCompleted
+	case started:
+		job.Status = pb.JobStatus_JOB_RUNNING
+		job.CompletedAt = 0
+	default:
+		job.Status = pb.JobStatus_JOB_PENDING
+		job.CompletedAt = 0
+	}
+}
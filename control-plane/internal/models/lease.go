@@ -0,0 +1,61 @@
+package models
+
+import (
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// IsLeased reports whether a task is currently held by a node. CANCELLING
+// tasks stay leased until the agent acknowledges the cancellation.
+func IsLeased(task *pb.Task) bool {
+	return task.Status == pb.TaskStatus_ASSIGNED || task.Status == pb.TaskStatus_RUNNING ||
+		task.Status == pb.TaskStatus_CANCELLING
+}
+
+// HoldsLease reports whether nodeID holds the current assignment of task
+// identified by fencingToken
+func HoldsLease(task *pb.Task, nodeID string, fencingToken uint64) bool {
+	return IsLeased(task) && task.AssignedNodeId == nodeID && task.FencingToken == fencingToken
+}
+
+// NextFencingToken returns the fencing token of an assignment committed at
+// logIndex. That is the log index unless an earlier command of the same or a
+// later-numbered entry already took it, so tokens stay unique and increasing
+// when several assignments share a batch.
+func (tm *TaskManifest) NextFencingToken(logIndex uint64) uint64 {
+	tm.LastFencingToken = max(logIndex, tm.LastFencingToken+1)
+	return tm.LastFencingToken
+}
+
+// GrantLease records the fencing token and expiry of a new assignment
+func (tm *TaskManifest) GrantLease(taskID string, fencingToken uint64, expiresAt int64) bool {
+	if task, exists := tm.Tasks[taskID]; exists {
+		task.FencingToken = fencingToken
+		task.LeaseExpiresAt = expiresAt
+		return true
+	}
+	return false
+}
+
+// RenewLease extends a lease if nodeID still holds the assignment
+func (tm *TaskManifest) RenewLease(taskID, nodeID string, fencingToken uint64, expiresAt int64) bool {
+	task, exists := tm.Tasks[taskID]
+	if !exists || !HoldsLease(task, nodeID, fencingToken) {
+		return false
+	}
+	if expiresAt > task.LeaseExpiresAt {
+		task.LeaseExpiresAt = expiresAt
+	}
+	return true
+}
+
+// GetExpiredLeases returns leased tasks whose lease lapsed at or before now.
+// Tasks assigned without a lease never expire.
+func (tm *TaskManifest) GetExpiredLeases(now int64) []*pb.Task {
+	var expired []*pb.Task
+	for _, task := range tm.Tasks {
+		if IsLeased(task) && task.LeaseExpiresAt > 0 && task.LeaseExpiresAt <= now {
+			expired = append(expired, task)
+		}
+	}
+	return expired
+}
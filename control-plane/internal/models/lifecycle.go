@@ -0,0 +1,51 @@
+package models
+
+import (
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// taskTransitions lists the statuses each task status may move to.
+// COMPLETED, DEAD_LETTER and CANCELLED are terminal; FAILED is terminal for
+// tasks without a retry policy.
+var taskTransitions = map[pb.TaskStatus][]pb.TaskStatus{
+	pb.TaskStatus_PENDING: {
+		pb.TaskStatus_ASSIGNED,
+		pb.TaskStatus_CANCELLED,
+	},
+	pb.TaskStatus_ASSIGNED: {
+		pb.TaskStatus_RUNNING,
+		pb.TaskStatus_COMPLETED,
+		pb.TaskStatus_FAILED,
+		pb.TaskStatus_PENDING, // Reclaimed from a lost node
+		pb.TaskStatus_CANCELLING,
+	},
+	pb.TaskStatus_RUNNING: {
+		pb.TaskStatus_COMPLETED,
+		pb.TaskStatus_FAILED,
+		pb.TaskStatus_PENDING, // Reclaimed from a lost node
+		pb.TaskStatus_CANCELLING,
+	},
+	pb.TaskStatus_CANCELLING: {
+		pb.TaskStatus_CANCELLED, // Acknowledged by the agent, or its lease lapsed
+	},
+	pb.TaskStatus_BLOCKED: {
+		pb.TaskStatus_PENDING, // Dependencies completed
+		pb.TaskStatus_FAILED,  // A dependency failed
+		pb.TaskStatus_CANCELLED,
+	},
+	pb.TaskStatus_FAILED: {
+		pb.TaskStatus_PENDING,     // Retried
+		pb.TaskStatus_DEAD_LETTER, // Retries exhausted
+		pb.TaskStatus_CANCELLED,
+	},
+}
+
+// CanTransition reports whether a task may move from one status to another
+func CanTransition(from, to pb.TaskStatus) bool {
+	for _, allowed := range taskTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"sort"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// PriorityClasses maps the named priority classes accepted at submission
+// to their integer priorities
+var PriorityClasses = map[string]int32{
+	"low":      -100,
+	"normal":   0,
+	"high":     100,
+	"critical": 1000,
+}
+
+// pendingKey orders PENDING tasks for dispatch: highest priority first,
+// then oldest first, then by task ID so the order is total
+type pendingKey struct {
+	priority  int32
+	createdAt int64
+	taskID    string
+}
+
+// keyOf returns the dispatch key of a task
+func keyOf(task *pb.Task) pendingKey {
+	return pendingKey{priority: task.Priority, createdAt: task.CreatedAt, taskID: task.TaskId}
+}
+
+// less reports whether a is dispatched before b
+func (a pendingKey) less(b pendingKey) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	if a.createdAt != b.createdAt {
+		return a.createdAt < b.createdAt
+	}
+	return a.taskID < b.taskID
+}
+
+// pendingIndex keeps the IDs of PENDING tasks in dispatch order so polls
+// never scan the whole manifest
+type pendingIndex struct {
+	keys []pendingKey
+}
+
+// insert adds a task to the index
+func (idx *pendingIndex) insert(task *pb.Task) {
+	key := keyOf(task)
+	i := sort.Search(len(idx.keys), func(i int) bool { return !idx.keys[i].less(key) })
+	if i < len(idx.keys) && idx.keys[i] == key {
+		return
+	}
+	idx.keys = append(idx.keys, pendingKey{})
+	copy(idx.keys[i+1:], idx.keys[i:])
+	idx.keys[i] = key
+}
+
+// remove drops a task from the index
+func (idx *pendingIndex) remove(task *pb.Task) {
+	key := keyOf(task)
+	i := sort.Search(len(idx.keys), func(i int) bool { return !idx.keys[i].less(key) })
+	if i < len(idx.keys) && idx.keys[i] == key {
+		idx.keys = append(idx.keys[:i], idx.keys[i+1:]...)
+	}
+}
+
+// setStatus changes a task's status, keeping the PENDING and CANCELLING
+// indexes in sync
+func (tm *TaskManifest) setStatus(task *pb.Task, status pb.TaskStatus) {
+	if task.Status == status {
+		return
+	}
+	switch task.Status {
+	case pb.TaskStatus_PENDING:
+		tm.pending.remove(task)
+	case pb.TaskStatus_CANCELLING:
+		tm.cancelling.remove(task)
+	}
+	task.Status = status
+	switch status {
+	case pb.TaskStatus_PENDING:
+		tm.pending.insert(task)
+	case pb.TaskStatus_CANCELLING:
+		tm.cancelling.insert(task)
+	}
+}
+
+// ForEachPending calls fn for each PENDING task in dispatch order until fn
+// returns false
+func (tm *TaskManifest) ForEachPending(fn func(task *pb.Task) bool) {
+	for _, key := range tm.pending.keys {
+		if !fn(tm.Tasks[key.taskID]) {
+			return
+		}
+	}
+}
+
+// ReindexTasks rebuilds the PENDING and CANCELLING indexes and the
+// dependency edges after Tasks was replaced wholesale, such as when
+// restoring a snapshot
+func (tm *TaskManifest) ReindexTasks() {
+	tm.pending = pendingIndex{}
+	tm.cancelling = nil
+	tm.dependents = nil
+	for _, task := range tm.Tasks {
+		switch task.Status {
+		case pb.TaskStatus_PENDING:
+			tm.pending.insert(task)
+		case pb.TaskStatus_CANCELLING:
+			tm.cancelling.insert(task)
+		}
+		tm.addDependencies(task)
+	}
+}
+
+// ErrorClassPreempted is recorded in a task's last preemption when it was
+// evicted to make room for higher-priority work
+const ErrorClassPreempted = "PREEMPTED"
+
+// PreemptTask moves an ASSIGNED or RUNNING task back to PENDING to make room
+// for preemptorID. The attempt is given back, so preemption never counts
+// against the task's retry policy, and it is recorded in LastPreemption so
+// the last real failure is kept.
+func (tm *TaskManifest) PreemptTask(taskID, preemptorID string, preemptedAt int64) bool {
+	task, exists := tm.Tasks[taskID]
+	if !exists {
+		return false
+	}
+
+	task.LastPreemption = &pb.TaskFailure{
+		ErrorMessage: "preempted by task " + preemptorID,
+		FailedAt:     preemptedAt,
+		NodeId:       task.AssignedNodeId,
+		Attempt:      task.Attempt,
+		ErrorClass:   ErrorClassPreempted,
+	}
+	tm.RequeueTask(taskID)
+	if task.Attempt > 0 {
+		task.Attempt--
+	}
+	return true
+}
+
+// WasPreemptedFrom reports whether a task's most recent attempt was on nodeID
+// and ended in preemption. The attempt was given back, so a task that has
+// not been assigned since sits one attempt below its preemption.
+func WasPreemptedFrom(task *pb.Task, nodeID string) bool {
+	return task.LastPreemption != nil &&
+		task.LastPreemption.NodeId == nodeID &&
+		task.LastPreemption.Attempt == task.Attempt+1
+}
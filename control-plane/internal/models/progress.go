@@ -0,0 +1,19 @@
+package models
+
+import (
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// RecordProgress stores the latest progress of a leased task. An ASSIGNED
+// task moves to RUNNING with its first report.
+func (tm *TaskManifest) RecordProgress(taskID string, progress *pb.TaskProgress) bool {
+	task, exists := tm.Tasks[taskID]
+	if !exists || !IsLeased(task) {
+		return false
+	}
+	if task.Status == pb.TaskStatus_ASSIGNED {
+		tm.setStatus(task, pb.TaskStatus_RUNNING)
+	}
+	task.Progress = progress
+	return true
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// AddResources returns the sum of two resource amounts. The accelerator
+// type of a is kept, falling back to that of b.
+func AddResources(a, b *pb.Resources) *pb.Resources {
+	sum := &pb.Resources{
+		CpuCores:        a.GetCpuCores() + b.GetCpuCores(),
+		MemoryMb:        a.GetMemoryMb() + b.GetMemoryMb(),
+		Accelerators:    a.GetAccelerators() + b.GetAccelerators(),
+		AcceleratorType: a.GetAcceleratorType(),
+	}
+	if sum.AcceleratorType == "" {
+		sum.AcceleratorType = b.GetAcceleratorType()
+	}
+	return sum
+}
+
+// SubtractResources returns a minus b, never going below zero
+func SubtractResources(a, b *pb.Resources) *pb.Resources {
+	diff := &pb.Resources{
+		CpuCores:        max(a.GetCpuCores()-b.GetCpuCores(), 0),
+		MemoryMb:        max(a.GetMemoryMb()-b.GetMemoryMb(), 0),
+		Accelerators:    max(a.GetAccelerators()-b.GetAccelerators(), 0),
+		AcceleratorType: a.GetAcceleratorType(),
+	}
+	return diff
+}
+
+// FreeResources returns what is left of a node's allocatable resources, or
+// nil if the node does not advertise any and is treated as unlimited
+func FreeResources(node *pb.Node) *pb.Resources {
+	if node.Allocatable == nil {
+		return nil
+	}
+	return SubtractResources(node.Allocatable, node.Allocated)
+}
+
+// Fits reports whether a node has enough unallocated resources for a request
+func Fits(node *pb.Node, request *pb.Resources) bool {
+	free := FreeResources(node)
+	if free == nil || request == nil {
+		return true
+	}
+
+	if request.CpuCores > free.CpuCores || request.MemoryMb > free.MemoryMb {
+		return false
+	}
+	if request.Accelerators > 0 {
+		if request.Accelerators > free.Accelerators {
+			return false
+		}
+		if request.AcceleratorType != "" && request.AcceleratorType != free.AcceleratorType {
+			return false
+		}
+	}
+	return true
+}
+
+// SetNodeResources records the capacity a node advertises. Allocatable
+// defaults to the full capacity.
+func (tm *TaskManifest) SetNodeResources(nodeID string, capacity, allocatable *pb.Resources) bool {
+	node, exists := tm.Nodes[nodeID]
+	if !exists {
+		return false
+	}
+
+	if allocatable == nil {
+		allocatable = capacity
+	}
+	node.Capacity = proto.Clone(capacity).(*pb.Resources)
+	node.Allocatable = proto.Clone(allocatable).(*pb.Resources)
+	return true
+}
+
+// releaseNode frees the slot and resources a leased task holds on its node
+func (tm *TaskManifest) releaseNode(task *pb.Task) {
+	node, exists := tm.Nodes[task.AssignedNodeId]
+	if !exists {
+		return
+	}
+	if node.ActiveTasks > 0 {
+		node.ActiveTasks--
+	}
+	if node.Allocated != nil {
+		node.Allocated = SubtractResources(node.Allocated, task.Resources)
+	}
+}
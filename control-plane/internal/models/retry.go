@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// defaultBackoffMultiplier is used when a retry policy does not set one
+const defaultBackoffMultiplier = 2.0
+
+// RetryBackoff returns how long to wait after the given failed attempt
+// before the task may be retried
+func RetryBackoff(policy *pb.RetryPolicy, attempt int32) time.Duration {
+	if policy == nil || policy.InitialBackoffMs <= 0 {
+		return 0
+	}
+
+	multiplier := policy.BackoffMultiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	backoff := float64(policy.InitialBackoffMs)
+	for i := int32(1); i < attempt; i++ {
+		backoff *= multiplier
+		if policy.MaxBackoffMs > 0 && backoff >= float64(policy.MaxBackoffMs) {
+			break
+		}
+	}
+	if policy.MaxBackoffMs > 0 && backoff > float64(policy.MaxBackoffMs) {
+		backoff = float64(policy.MaxBackoffMs)
+	}
+
+	return time.Duration(backoff) * time.Millisecond
+}
+
+// IsRetryable reports whether a failure may be retried under a policy
+func IsRetryable(policy *pb.RetryPolicy, failure *pb.TaskFailure) bool {
+	if policy == nil || failure == nil {
+		return false
+	}
+	if failure.Attempt >= policy.MaxAttempts || failure.ErrorClass == ErrorClassDependencyFailed {
+		return false
+	}
+	if len(policy.RetryableErrors) == 0 {
+		return true
+	}
+	for _, class := range policy.RetryableErrors {
+		if class == failure.ErrorClass {
+			return true
+		}
+	}
+	return false
+}
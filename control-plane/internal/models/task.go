@@ -1,28 +1,61 @@
 package models
 
 import (
-	"time"
+	"sort"
 
 	pb "ml-raft-control-plane/pkg/proto"
 )
 
 // TaskManifest represents the global state of all tasks (FSM state)
 type TaskManifest struct {
-	Tasks map[string]*pb.Task // task_id -> Task
-	Nodes map[string]*pb.Node // node_id -> Node
+	Tasks   map[string]*pb.Task       // task_id -> Task
+	Nodes   map[string]*pb.Node       // node_id -> Node
+	Servers map[string]*pb.ServerInfo // server_id -> control-plane ServerInfo
+	Jobs    map[string]*pb.Job        // job_id -> Job
+
+	Idempotency      []*IdempotencyRecord // Remembered request keys, oldest first
+	LastFencingToken uint64               // Fencing token of the latest assignment
+
+	pending          pendingIndex                  // PENDING tasks in dispatch order
+	cancelling       cancellingIndex               // node_id -> IDs of its CANCELLING tasks
+	dependents       map[string][]string           // task_id -> IDs of the tasks depending on it
+	idempotencyIndex map[string]*IdempotencyRecord // key -> record in Idempotency
 }
 
 // NewTaskManifest creates empty task manifest
 func NewTaskManifest() *TaskManifest {
 	return &TaskManifest{
-		Tasks: make(map[string]*pb.Task),
-		Nodes: make(map[string]*pb.Node),
+		Tasks:   make(map[string]*pb.Task),
+		Nodes:   make(map[string]*pb.Node),
+		Servers: make(map[string]*pb.ServerInfo),
+		Jobs:    make(map[string]*pb.Job),
 	}
 }
 
-// AddTask adds a new task to the manifest
+// AddTask adds a new task to the manifest. A PENDING task with dependencies
+// is BLOCKED until all of them complete.
 func (tm *TaskManifest) AddTask(task *pb.Task) {
+	if existing, exists := tm.Tasks[task.TaskId]; exists {
+		switch existing.Status {
+		case pb.TaskStatus_PENDING:
+			tm.pending.remove(existing)
+		case pb.TaskStatus_CANCELLING:
+			tm.cancelling.remove(existing)
+		}
+	}
+	if task.Status == pb.TaskStatus_PENDING && len(task.DependsOn) > 0 {
+		task.Status = pb.TaskStatus_BLOCKED
+	}
 	tm.Tasks[task.TaskId] = task
+	switch task.Status {
+	case pb.TaskStatus_PENDING:
+		tm.pending.insert(task)
+	case pb.TaskStatus_CANCELLING:
+		tm.cancelling.insert(task)
+	}
+
+	tm.addDependencies(task)
+	tm.resolveBlocked(task, task.CreatedAt)
 }
 
 // GetTask retrieves a task by ID
@@ -34,62 +67,110 @@ func (tm *TaskManifest) GetTask(taskID string) (*pb.Task, bool) {
 // UpdateTaskStatus updates task status
 func (tm *TaskManifest) UpdateTaskStatus(taskID string, status pb.TaskStatus) bool {
 	if task, exists := tm.Tasks[taskID]; exists {
-		task.Status = status
+		tm.setStatus(task, status)
 		return true
 	}
 	return false
 }
 
-// AssignTask assigns task to a node
-func (tm *TaskManifest) AssignTask(taskID, nodeID string) bool {
+// AssignTask assigns task to a node at the given time
+func (tm *TaskManifest) AssignTask(taskID, nodeID string, assignedAt int64) bool {
 	if task, exists := tm.Tasks[taskID]; exists {
 		task.AssignedNodeId = nodeID
-		task.Status = pb.TaskStatus_ASSIGNED
-		task.StartedAt = time.Now().Unix()
+		tm.setStatus(task, pb.TaskStatus_ASSIGNED)
+		task.StartedAt = assignedAt
+		task.Attempt++
+		task.Progress = nil
 
-		// Increment node's active task count
+		// Increment node's active task count and allocate the requested resources
 		if node, nodeExists := tm.Nodes[nodeID]; nodeExists {
 			node.ActiveTasks++
+			if task.Resources != nil {
+				node.Allocated = AddResources(node.Allocated, task.Resources)
+			}
 		}
 		return true
 	}
 	return false
 }
 
-// CompleteTask marks task as completed
-func (tm *TaskManifest) CompleteTask(taskID, resultData string) bool {
+// CompleteTask marks task as completed at the given time
+func (tm *TaskManifest) CompleteTask(taskID, resultData string, completedAt int64) bool {
 	if task, exists := tm.Tasks[taskID]; exists {
-		task.Status = pb.TaskStatus_COMPLETED
-		task.CompletedAt = time.Now().Unix()
+		tm.setStatus(task, pb.TaskStatus_COMPLETED)
+		task.CompletedAt = completedAt
 		task.ResultData = resultData
 
-		// Decrement node's active task count
-		if node, nodeExists := tm.Nodes[task.AssignedNodeId]; nodeExists && node.ActiveTasks > 0 {
-			node.ActiveTasks--
-		}
+		// Release the node's slot and resources
+		tm.releaseNode(task)
+		tm.finishDependencies(task, completedAt)
 		return true
 	}
 	return false
 }
 
-// FailTask marks task as failed
-func (tm *TaskManifest) FailTask(taskID, errorMessage string) bool {
+// FailTask marks task as failed at the given time and records the failure
+// against the node and attempt that produced it
+func (tm *TaskManifest) FailTask(taskID, errorMessage, errorClass string, failedAt int64) bool {
 	if task, exists := tm.Tasks[taskID]; exists {
-		task.Status = pb.TaskStatus_FAILED
-		task.CompletedAt = time.Now().Unix()
-		task.ResultData = errorMessage
+		tm.setStatus(task, pb.TaskStatus_FAILED)
+		task.CompletedAt = failedAt
+		task.LastFailure = &pb.TaskFailure{
+			ErrorMessage: errorMessage,
+			FailedAt:     failedAt,
+			NodeId:       task.AssignedNodeId,
+			Attempt:      task.Attempt,
+			ErrorClass:   errorClass,
+		}
 
-		// Decrement node's active task count
-		if node, nodeExists := tm.Nodes[task.AssignedNodeId]; nodeExists && node.ActiveTasks > 0 {
-			node.ActiveTasks--
+		// Release the node's slot and resources
+		tm.releaseNode(task)
+		if BlocksDependents(task) {
+			tm.finishDependencies(task, failedAt)
 		}
 		return true
 	}
 	return false
 }
 
-// UpdateNodeHeartbeat updates node status
-func (tm *TaskManifest) UpdateNodeHeartbeat(nodeID string, cpuUsage, memUsage float64, activeTasks int32) {
+// RequeueTask moves a task back to PENDING so it can be assigned again,
+// releasing the node slot and resources if the task was still held by a node
+func (tm *TaskManifest) RequeueTask(taskID string) bool {
+	task, exists := tm.Tasks[taskID]
+	if !exists {
+		return false
+	}
+
+	if task.Status == pb.TaskStatus_ASSIGNED || task.Status == pb.TaskStatus_RUNNING {
+		tm.releaseNode(task)
+	}
+
+	tm.setStatus(task, pb.TaskStatus_PENDING)
+	task.AssignedNodeId = ""
+	task.StartedAt = 0
+	task.LeaseExpiresAt = 0
+	task.PlacedNodeId = ""
+	task.PlacedAt = 0
+	task.PlacedBy = ""
+	return true
+}
+
+// DeadLetterTask marks a task as permanently failed
+func (tm *TaskManifest) DeadLetterTask(taskID, reason string, deadLetteredAt int64) bool {
+	if task, exists := tm.Tasks[taskID]; exists {
+		tm.setStatus(task, pb.TaskStatus_DEAD_LETTER)
+		task.CompletedAt = deadLetteredAt
+		task.DeadLetterReason = reason
+		tm.finishDependencies(task, deadLetteredAt)
+		return true
+	}
+	return false
+}
+
+// UpdateNodeHeartbeat updates node status from a heartbeat sent at the given
+// time. The agent's task count is kept for diagnostics only; ActiveTasks is
+// counted on assignment and release, so a lagging heartbeat cannot undo it.
+func (tm *TaskManifest) UpdateNodeHeartbeat(nodeID string, cpuUsage, memUsage float64, activeTasks int32, timestamp int64) {
 	node, exists := tm.Nodes[nodeID]
 	if !exists {
 		// Create new node
@@ -100,10 +181,10 @@ func (tm *TaskManifest) UpdateNodeHeartbeat(nodeID string, cpuUsage, memUsage fl
 		tm.Nodes[nodeID] = node
 	}
 
-	node.LastHeartbeat = time.Now().Unix()
+	node.LastHeartbeat = timestamp
 	node.CpuUsage = cpuUsage
 	node.MemoryUsage = memUsage
-	node.ActiveTasks = activeTasks
+	node.ReportedActiveTasks = activeTasks
 	node.Status = pb.NodeStatus_HEALTHY
 }
 
@@ -116,14 +197,72 @@ func (tm *TaskManifest) MarkNodeUnhealthy(nodeID string) bool {
 	return false
 }
 
-// GetPendingTasks returns all pending tasks
-func (tm *TaskManifest) GetPendingTasks() []*pb.Task {
-	var pending []*pb.Task
+// ReclaimNodeTasks moves every ASSIGNED or RUNNING task owned by a node back
+// to PENDING, finishes cancelling its CANCELLING tasks, and returns their IDs
+func (tm *TaskManifest) ReclaimNodeTasks(nodeID string, reclaimedAt int64) []string {
+	var reclaimed []string
+	for taskID, task := range tm.Tasks {
+		if task.AssignedNodeId == nodeID && IsLeased(task) {
+			reclaimed = append(reclaimed, taskID)
+		}
+	}
+
+	// Cancellations fail dependents, so process them in a fixed order
+	sort.Strings(reclaimed)
+	for _, taskID := range reclaimed {
+		task := tm.Tasks[taskID]
+		if task.Status == pb.TaskStatus_CANCELLING {
+			// No agent is left to acknowledge the cancellation
+			tm.finishCancel(task, reclaimedAt)
+			continue
+		}
+		tm.RequeueTask(taskID)
+	}
+	return reclaimed
+}
+
+// PlaceTask records the node a scheduler chose for a PENDING task
+func (tm *TaskManifest) PlaceTask(taskID, nodeID, scheduler string, placedAt int64) bool {
+	if task, exists := tm.Tasks[taskID]; exists {
+		task.PlacedNodeId = nodeID
+		task.PlacedAt = placedAt
+		task.PlacedBy = scheduler
+		return true
+	}
+	return false
+}
+
+// UnplaceTask drops the placement of a PENDING task so it can be placed again
+func (tm *TaskManifest) UnplaceTask(taskID string) bool {
+	task, exists := tm.Tasks[taskID]
+	if !exists || task.Status != pb.TaskStatus_PENDING || task.PlacedNodeId == "" {
+		return false
+	}
+	task.PlacedNodeId = ""
+	task.PlacedAt = 0
+	task.PlacedBy = ""
+	return true
+}
+
+// ClearNodePlacements drops the placement of every PENDING task placed on a
+// node so the tasks can be placed elsewhere
+func (tm *TaskManifest) ClearNodePlacements(nodeID string) {
 	for _, task := range tm.Tasks {
-		if task.Status == pb.TaskStatus_PENDING {
-			pending = append(pending, task)
+		if task.Status == pb.TaskStatus_PENDING && task.PlacedNodeId == nodeID {
+			task.PlacedNodeId = ""
+			task.PlacedAt = 0
+			task.PlacedBy = ""
 		}
 	}
+}
+
+// GetPendingTasks returns all pending tasks in dispatch order: highest
+// priority first, then oldest first
+func (tm *TaskManifest) GetPendingTasks() []*pb.Task {
+	pending := make([]*pb.Task, 0, len(tm.pending.keys))
+	for _, key := range tm.pending.keys {
+		pending = append(pending, tm.Tasks[key.taskID])
+	}
 	return pending
 }
 
@@ -167,25 +306,8 @@ func (tm *TaskManifest) GetAllNodes() []*pb.Node {
 	return nodes
 }
 
-// SelectLeastLoadedNode returns node with fewest active tasks
-func (tm *TaskManifest) SelectLeastLoadedNode() *pb.Node {
-	healthy := tm.GetHealthyNodes()
-	if len(healthy) == 0 {
-		return nil
-	}
-
-	minNode := healthy[0]
-	for _, node := range healthy[1:] {
-		if node.ActiveTasks < minNode.ActiveTasks {
-			minNode = node
-		}
-	}
-	return minNode
-}
-
-// CheckStaleNodes checks for nodes that haven't sent heartbeat recently
-func (tm *TaskManifest) CheckStaleNodes(timeoutSeconds int64) []string {
-	now := time.Now().Unix()
+// CheckStaleNodes checks for nodes that haven't sent heartbeat within timeoutSeconds of now
+func (tm *TaskManifest) CheckStaleNodes(now, timeoutSeconds int64) []string {
 	var staleNodes []string
 
 	for nodeID, node := range tm.Nodes {
@@ -205,3 +327,14 @@ func (tm *TaskManifest) GetNodeTaskCount(nodeID string) int32 {
 	}
 	return 0
 }
+
+// RegisterServer records or replaces control-plane server metadata
+func (tm *TaskManifest) RegisterServer(server *pb.ServerInfo) {
+	tm.Servers[server.ServerId] = server
+}
+
+// GetServer retrieves control-plane server metadata by Raft server ID
+func (tm *TaskManifest) GetServer(serverID string) (*pb.ServerInfo, bool) {
+	server, exists := tm.Servers[serverID]
+	return server, exists
+}
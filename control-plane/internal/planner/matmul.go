@@ -0,0 +1,195 @@
+// Package planner turns high-level workloads into jobs of dependent tasks.
+// Planning never reads the data a job works on, so tasks name the checksums
+// agents must verify but carry no expected values; agents compute them,
+// report them in result_data and check them against each other.
+package planner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// Task types of the tasks generated for a matrix multiplication
+const (
+	TaskTypeMatMulMap    = "matmul_map"
+	TaskTypeMatMulReduce = "matmul_reduce"
+)
+
+// Checksum schemes agents apply to matmul blocks. Every partial product is
+// hashed by the map task that wrote it and re-hashed by the reduce task that
+// reads it. Column sums implement algorithm-based fault tolerance: the column
+// sums of C(i,j) must equal the sum over k of the column sums of
+// A(i,k)×B(k,j), which each map task computes from its inputs. The expected
+// digests and sums come from the map tasks' result_data, not from the plan.
+const (
+	DigestSHA256        = "sha256"
+	ChecksumColumnSums  = "column_sums"
+	defaultSumTolerance = 1e-6
+)
+
+// MatMulSpec describes C = A×B, where A is M×K and B is K×N, computed in
+// square blocks of BlockSize
+type MatMulSpec struct {
+	Name      string // Job name; prefixes every task ID, so it must be unique
+	M, K, N   int
+	BlockSize int
+	InputA    string // URI of A
+	InputB    string // URI of B
+	Output    string // URI prefix for partial products and blocks of C
+
+	RetryPolicy *pb.RetryPolicy // Applied to every task; optional
+	Tolerance   float64         // Allowed relative error of column sums; defaults to 1e-6
+}
+
+// Range is a half-open [start, end) range of matrix rows or columns
+type Range [2]int
+
+// BlockRef locates one block of a matrix stored at URI
+type BlockRef struct {
+	URI  string `json:"uri"`
+	Rows Range  `json:"rows"`
+	Cols Range  `json:"cols"`
+}
+
+// Verify tells an agent how to check the data it reads and writes
+type Verify struct {
+	Digest    string  `json:"digest"`              // Hash reported in result_data and checked on read
+	Checksum  string  `json:"checksum"`            // Numerical checksum of the block
+	Tolerance float64 `json:"tolerance,omitempty"` // Allowed relative error of the numerical checksum
+}
+
+// MapTaskData is the task_data of a map task computing A(i,k)×B(k,j)
+type MapTaskData struct {
+	Stage  string   `json:"stage"`
+	Block  [3]int   `json:"block"` // i, j, k
+	A      BlockRef `json:"a"`
+	B      BlockRef `json:"b"`
+	Output string   `json:"output"`
+	Verify Verify   `json:"verify"`
+}
+
+// ReduceInput is one partial product summed by a reduce task
+type ReduceInput struct {
+	TaskID string `json:"task_id"` // Map task whose result_data carries the digest and column sums
+	URI    string `json:"uri"`
+}
+
+// ReduceTaskData is the task_data of a reduce task summing the partial
+// products of C(i,j)
+type ReduceTaskData struct {
+	Stage  string        `json:"stage"`
+	Block  [2]int        `json:"block"` // i, j
+	Rows   Range         `json:"rows"`
+	Cols   Range         `json:"cols"`
+	Inputs []ReduceInput `json:"inputs"`
+	Output string        `json:"output"`
+	Verify Verify        `json:"verify"`
+}
+
+// Validate reports the first problem with a spec
+func (s MatMulSpec) Validate() error {
+	switch {
+	case s.Name == "":
+		return errors.New("name is required")
+	case s.M <= 0 || s.K <= 0 || s.N <= 0:
+		return fmt.Errorf("matrix dimensions must be positive, got %dx%d × %dx%d", s.M, s.K, s.K, s.N)
+	case s.BlockSize <= 0:
+		return fmt.Errorf("block size must be positive, got %d", s.BlockSize)
+	case s.InputA == "" || s.InputB == "":
+		return errors.New("input URIs of A and B are required")
+	case s.Output == "":
+		return errors.New("output URI is required")
+	case s.Tolerance < 0:
+		return fmt.Errorf("tolerance must not be negative, got %g", s.Tolerance)
+	}
+	return nil
+}
+
+// PlanMatMul returns the job computing a matrix multiplication: one map
+// task per block product A(i,k)×B(k,j) and one reduce task per block C(i,j)
+// that depends on the map tasks it sums. Tasks are ordered maps first.
+func PlanMatMul(spec MatMulSpec) (*pb.SubmitJobRequest, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid matmul spec: %w", err)
+	}
+	tolerance := spec.Tolerance
+	if tolerance == 0 {
+		tolerance = defaultSumTolerance
+	}
+
+	rows := blocks(spec.M, spec.BlockSize)
+	inner := blocks(spec.K, spec.BlockSize)
+	cols := blocks(spec.N, spec.BlockSize)
+	output := strings.TrimSuffix(spec.Output, "/")
+
+	job := &pb.SubmitJobRequest{Name: spec.Name}
+	var reduces []*pb.SubmitTaskRequest
+	for i, rowRange := range rows {
+		for j, colRange := range cols {
+			reduce := ReduceTaskData{
+				Stage:  "reduce",
+				Block:  [2]int{i, j},
+				Rows:   rowRange,
+				Cols:   colRange,
+				Output: fmt.Sprintf("%s/c/%d-%d", output, i, j),
+				Verify: Verify{Digest: DigestSHA256, Checksum: ChecksumColumnSums, Tolerance: tolerance},
+			}
+
+			var mapIDs []string
+			for k, innerRange := range inner {
+				mapData := MapTaskData{
+					Stage:  "map",
+					Block:  [3]int{i, j, k},
+					A:      BlockRef{URI: spec.InputA, Rows: rowRange, Cols: innerRange},
+					B:      BlockRef{URI: spec.InputB, Rows: innerRange, Cols: colRange},
+					Output: fmt.Sprintf("%s/partials/%d-%d/%d", output, i, j, k),
+					Verify: Verify{Digest: DigestSHA256, Checksum: ChecksumColumnSums},
+				}
+				mapTask, err := newTask(fmt.Sprintf("%s/map/%d-%d-%d", spec.Name, i, j, k), TaskTypeMatMulMap, mapData, nil, spec.RetryPolicy)
+				if err != nil {
+					return nil, err
+				}
+				job.Tasks = append(job.Tasks, mapTask)
+				mapIDs = append(mapIDs, mapTask.TaskId)
+				reduce.Inputs = append(reduce.Inputs, ReduceInput{TaskID: mapTask.TaskId, URI: mapData.Output})
+			}
+
+			reduceTask, err := newTask(fmt.Sprintf("%s/reduce/%d-%d", spec.Name, i, j), TaskTypeMatMulReduce, reduce, mapIDs, spec.RetryPolicy)
+			if err != nil {
+				return nil, err
+			}
+			reduces = append(reduces, reduceTask)
+		}
+	}
+
+	job.Tasks = append(job.Tasks, reduces...)
+	return job, nil
+}
+
+// blocks splits [0, size) into ranges of blockSize; the last may be shorter
+func blocks(size, blockSize int) []Range {
+	ranges := make([]Range, 0, (size+blockSize-1)/blockSize)
+	for start := 0; start < size; start += blockSize {
+		ranges = append(ranges, Range{start, min(start+blockSize, size)})
+	}
+	return ranges
+}
+
+// newTask builds the submission of one planned task
+func newTask(taskID, taskType string, data interface{}, dependsOn []string, retryPolicy *pb.RetryPolicy) (*pb.SubmitTaskRequest, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task_data of %s: %w", taskID, err)
+	}
+	return &pb.SubmitTaskRequest{
+		TaskId:      taskID,
+		TaskType:    taskType,
+		TaskData:    encoded,
+		RetryPolicy: retryPolicy,
+		DependsOn:   dependsOn,
+	}, nil
+}
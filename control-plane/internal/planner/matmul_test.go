@@ -0,0 +1,95 @@
+package planner
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestPlanMatMul(t *testing.T) {
+	// A is 3x4 and B is 4x2, so C has 2x1 blocks, each summing 2 partial products
+	job, err := PlanMatMul(MatMulSpec{
+		Name:      "mm",
+		M:         3,
+		K:         4,
+		N:         2,
+		BlockSize: 2,
+		InputA:    "gs://bucket/a",
+		InputB:    "s3://bucket/b",
+		Output:    "gs://bucket/out/",
+	})
+	if err != nil {
+		t.Fatalf("PlanMatMul() returned error: %v", err)
+	}
+
+	var ids []string
+	for _, task := range job.Tasks {
+		ids = append(ids, task.TaskId)
+	}
+	want := []string{"mm/map/0-0-0", "mm/map/0-0-1", "mm/map/1-0-0", "mm/map/1-0-1", "mm/reduce/0-0", "mm/reduce/1-0"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected tasks %v, got %v", want, ids)
+	}
+
+	var edge MapTaskData
+	if err := json.Unmarshal(job.Tasks[3].TaskData, &edge); err != nil {
+		t.Fatalf("map task_data is not valid JSON: %v", err)
+	}
+	if edge.A.Rows != (Range{2, 3}) || edge.A.Cols != (Range{2, 4}) || edge.B.Rows != (Range{2, 4}) {
+		t.Errorf("unexpected ranges of the ragged edge block: A %v B %v", edge.A, edge.B)
+	}
+	if edge.Output != "gs://bucket/out/partials/1-0/1" {
+		t.Errorf("unexpected map output %q", edge.Output)
+	}
+
+	reduce := job.Tasks[5]
+	if reduce.TaskType != TaskTypeMatMulReduce || !reflect.DeepEqual(reduce.DependsOn, []string{"mm/map/1-0-0", "mm/map/1-0-1"}) {
+		t.Errorf("expected reduce to depend on its maps, got %s %v", reduce.TaskType, reduce.DependsOn)
+	}
+	var reduceData ReduceTaskData
+	if err := json.Unmarshal(reduce.TaskData, &reduceData); err != nil {
+		t.Fatalf("reduce task_data is not valid JSON: %v", err)
+	}
+	if len(reduceData.Inputs) != 2 || reduceData.Inputs[1].URI != "gs://bucket/out/partials/1-0/1" {
+		t.Errorf("unexpected reduce inputs %v", reduceData.Inputs)
+	}
+	if reduceData.Verify.Checksum != ChecksumColumnSums || reduceData.Verify.Tolerance != defaultSumTolerance {
+		t.Errorf("unexpected reduce verification %v", reduceData.Verify)
+	}
+}
+
+func TestPlanMatMul_InvalidSpec(t *testing.T) {
+	valid := MatMulSpec{Name: "mm", M: 4, K: 4, N: 4, BlockSize: 2, InputA: "a", InputB: "b", Output: "out"}
+
+	tests := []struct {
+		name   string
+		modify func(*MatMulSpec)
+	}{
+		{"missing name", func(s *MatMulSpec) { s.Name = "" }},
+		{"zero dimension", func(s *MatMulSpec) { s.K = 0 }},
+		{"zero block size", func(s *MatMulSpec) { s.BlockSize = 0 }},
+		{"missing input", func(s *MatMulSpec) { s.InputB = "" }},
+		{"missing output", func(s *MatMulSpec) { s.Output = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := valid
+			tt.modify(&spec)
+			if _, err := PlanMatMul(spec); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	valid.RetryPolicy = &pb.RetryPolicy{MaxAttempts: 3}
+	job, err := PlanMatMul(valid)
+	if err != nil {
+		t.Fatalf("PlanMatMul() returned error: %v", err)
+	}
+	if len(job.Tasks) != 8+4 || job.Tasks[0].RetryPolicy.GetMaxAttempts() != 3 {
+		t.Errorf("expected 12 tasks with the retry policy, got %d", len(job.Tasks))
+	}
+}
@@ -0,0 +1,185 @@
+package raft
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// applyRequest is a command waiting in the apply queue for its batch
+type applyRequest struct {
+	data     []byte
+	timeout  time.Duration
+	queuedAt time.Time
+	done     chan applyResult
+}
+
+// applyResult is what one caller of the apply queue gets back
+type applyResult struct {
+	response interface{}
+	err      error
+}
+
+// enqueueApply hands a command to the apply queue and waits for its own
+// result. The timeout bounds how long the command waits to be queued; once
+// queued, the caller gets the outcome of its command even across Shutdown.
+func (rc *RaftCluster) enqueueApply(data []byte, timeout time.Duration) (interface{}, error) {
+	req := &applyRequest{data: data, timeout: timeout, queuedAt: time.Now(), done: make(chan applyResult, 1)}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// Shutdown waits for the read lock to be released before it closes the queue
+	rc.applyMu.RLock()
+	if rc.applyClosed {
+		rc.applyMu.RUnlock()
+		return nil, fmt.Errorf("failed to apply log entry: %w", raft.ErrRaftShutdown)
+	}
+	select {
+	case rc.applyQueue <- req:
+	case <-expired:
+		rc.applyMu.RUnlock()
+		return nil, fmt.Errorf("failed to apply log entry: %w", raft.ErrEnqueueTimeout)
+	}
+	rc.applyMu.RUnlock()
+
+	result := <-req.done
+	return result.response, result.err
+}
+
+// runApplyQueue groups queued commands into batches and submits each batch
+// without waiting for the previous one to commit, so concurrent callers are
+// not serialized behind the commit latency. Once Shutdown closes the queue
+// it submits what is left and returns.
+func (rc *RaftCluster) runApplyQueue() {
+	defer close(rc.applyDone)
+	for first := range rc.applyQueue {
+		rc.submitBatch(rc.collectBatch(first))
+	}
+}
+
+// closeApplyQueue stops the apply queue from taking commands and waits until
+// every queued command has been submitted to Raft
+func (rc *RaftCluster) closeApplyQueue() {
+	rc.applyMu.Lock()
+	if !rc.applyClosed {
+		rc.applyClosed = true
+		close(rc.applyQueue)
+	}
+	rc.applyMu.Unlock()
+	<-rc.applyDone
+}
+
+// collectBatch adds commands to a batch until it holds ApplyBatchSize of
+// them or the first command has lingered for ApplyLinger. Without a linger
+// it only takes commands that are already waiting. A closed queue ends the
+// batch at once.
+func (rc *RaftCluster) collectBatch(first *applyRequest) []*applyRequest {
+	batch := []*applyRequest{first}
+
+	var expired <-chan time.Time
+	if rc.config.ApplyLinger > 0 {
+		timer := time.NewTimer(rc.config.ApplyLinger)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for len(batch) < rc.config.ApplyBatchSize {
+		if expired == nil {
+			select {
+			case req, ok := <-rc.applyQueue:
+				if !ok {
+					return batch
+				}
+				batch = append(batch, req)
+			default:
+				return batch
+			}
+			continue
+		}
+
+		select {
+		case req, ok := <-rc.applyQueue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, req)
+		case <-expired:
+			return batch
+		}
+	}
+	return batch
+}
+
+// submitBatch submits a batch to Raft and delivers every caller's result
+// once it commits. A lone command is submitted as is.
+func (rc *RaftCluster) submitBatch(batch []*applyRequest) {
+	if len(batch) == 1 {
+		future := rc.raft.Apply(batch[0].data, batch[0].timeout)
+		go func() {
+			if err := future.Error(); err != nil {
+				batch[0].done <- applyResult{err: fmt.Errorf("failed to apply log entry: %w", err)}
+				return
+			}
+			response, err := fsmResponse(future.Response())
+			batch[0].done <- applyResult{response: response, err: err}
+		}()
+		return
+	}
+
+	entry := BatchEntry{Commands: make([]json.RawMessage, len(batch)), QueuedAt: make([]int64, len(batch))}
+	for i, req := range batch {
+		entry.Commands[i] = req.data
+		entry.QueuedAt[i] = req.queuedAt.UnixNano()
+	}
+	data, err := EncodeLogEntry(LogEntryBatch, entry)
+	if err != nil {
+		failBatch(batch, err)
+		return
+	}
+
+	future := rc.raft.Apply(data, batchTimeout(batch))
+	go func() {
+		if err := future.Error(); err != nil {
+			failBatch(batch, fmt.Errorf("failed to apply log entry: %w", err))
+			return
+		}
+		if _, err := fsmResponse(future.Response()); err != nil {
+			failBatch(batch, err)
+			return
+		}
+		responses, ok := future.Response().([]interface{})
+		if !ok || len(responses) != len(batch) {
+			failBatch(batch, fmt.Errorf("FSM apply error: got %T for a batch of %d commands", future.Response(), len(batch)))
+			return
+		}
+		for i, req := range batch {
+			response, err := fsmResponse(responses[i])
+			req.done <- applyResult{response: response, err: err}
+		}
+	}()
+}
+
+// batchTimeout returns the longest timeout of a batch's commands, so no
+// caller is given up on sooner than it asked. No timeout counts as longest.
+func batchTimeout(batch []*applyRequest) time.Duration {
+	timeout := batch[0].timeout
+	for _, req := range batch {
+		if timeout > 0 && (req.timeout <= 0 || req.timeout > timeout) {
+			timeout = req.timeout
+		}
+	}
+	return timeout
+}
+
+// failBatch delivers the same error to every caller of a batch
+func failBatch(batch []*applyRequest, err error) {
+	for _, req := range batch {
+		req.done <- applyResult{err: err}
+	}
+}
@@ -0,0 +1,286 @@
+package raft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+func TestFSM_Apply_Batch(t *testing.T) {
+	fsm := setupFSM(t)
+
+	commands := make([]json.RawMessage, 0, 3)
+	for _, command := range []struct {
+		entryType LogEntryType
+		data      interface{}
+	}{
+		{LogEntryAddTask, AddTaskEntry{TaskID: "task-1"}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: "task-1"}},
+		{LogEntryBatch, BatchEntry{}},
+		{LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"}},
+	} {
+		data, err := EncodeLogEntry(command.entryType, command.data)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		commands = append(commands, data)
+	}
+
+	data, err := EncodeLogEntry(LogEntryBatch, BatchEntry{Commands: commands})
+	if err != nil {
+		t.Fatalf("failed to encode log entry: %v", err)
+	}
+	responses, ok := fsm.Apply(&raft.Log{Index: 5, Data: data}).([]interface{})
+	if !ok || len(responses) != 4 {
+		t.Fatalf("expected 4 responses, got %v", responses)
+	}
+
+	// Every command gets its own response and a failed one does not stop the rest
+	if responses[0] != nil || responses[3] != nil {
+		t.Errorf("expected the first and last commands to succeed, got %v and %v", responses[0], responses[3])
+	}
+	if err, _ := responses[1].(error); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for the duplicate task, got %v", responses[1])
+	}
+	if _, isErr := responses[2].(error); !isErr {
+		t.Errorf("expected a nested batch to be rejected, got %v", responses[2])
+	}
+	if _, found := fsm.GetNode("node-1"); !found {
+		t.Error("expected node-1 to be registered")
+	}
+}
+
+func TestFSM_Apply_BatchGivesEachAssignmentItsOwnToken(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	for _, taskID := range []string{"task-1", "task-2", "task-3"} {
+		applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	}
+
+	var batch BatchEntry
+	for i, taskID := range []string{"task-1", "task-2"} {
+		data, err := EncodeLogEntry(LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: "node-1"})
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		batch.Commands = append(batch.Commands, data)
+		batch.QueuedAt = append(batch.QueuedAt, time.Unix(1000+int64(i), 0).UnixNano())
+	}
+	data, err := EncodeLogEntry(LogEntryBatch, batch)
+	if err != nil {
+		t.Fatalf("failed to encode log entry: %v", err)
+	}
+	if responses := fsm.Apply(&raft.Log{Index: 5, Data: data, AppendedAt: time.Unix(2000, 0)}); fmt.Sprint(responses) != "[<nil> <nil>]" {
+		t.Fatalf("expected both assignments to succeed, got %v", responses)
+	}
+	// The next entry's index is already taken by the batch's second assignment
+	if err := applyAt(t, fsm, 6, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-3", NodeID: "node-1"}); err != nil {
+		t.Fatalf("failed to assign task-3: %v", err)
+	}
+
+	first, _ := fsm.GetTask("task-1")
+	second, _ := fsm.GetTask("task-2")
+	third, _ := fsm.GetTask("task-3")
+	if first.FencingToken != 5 || second.FencingToken != 6 || third.FencingToken != 7 {
+		t.Errorf("expected fencing tokens 5, 6 and 7, got %d, %d and %d", first.FencingToken, second.FencingToken, third.FencingToken)
+	}
+	if first.StartedAt != 1000 || second.StartedAt != 1001 {
+		t.Errorf("expected each assignment at the time it was queued, got %d and %d", first.StartedAt, second.StartedAt)
+	}
+
+	// Tokens stay increasing after a snapshot is restored
+	restored := setupFSM(t)
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Persist(&mockSnapshotSink{writer: &buf}); err != nil {
+		t.Fatalf("Persist() returned error: %v", err)
+	}
+	if err := restored.Restore(io.NopCloser(&buf)); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+	if restored.GetManifest().LastFencingToken != 7 {
+		t.Errorf("expected the last fencing token to be restored, got %d", restored.GetManifest().LastFencingToken)
+	}
+}
+
+func TestApplyQueue_GroupsConcurrentCommands(t *testing.T) {
+	cluster := setupCluster(t, 1, nil, func(config *ClusterConfig) {
+		config.ApplyBatchSize = 16
+		config.ApplyLinger = 200 * time.Millisecond
+	})
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+	before := cluster.raft.LastIndex()
+
+	// Eight callers submit at once; the last two add the same task
+	const callers = 8
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		taskID := fmt.Sprintf("task-%d", i)
+		if i == callers-1 {
+			taskID = fmt.Sprintf("task-%d", i-1)
+		}
+		data, err := EncodeLogEntry(LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = cluster.Apply(data, 5*time.Second)
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		failed++
+		if !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("caller %d: expected nil or ErrAlreadyExists, got %v", i, err)
+		}
+	}
+	if failed != 1 {
+		t.Errorf("expected exactly one caller to fail, got %d: %v", failed, errs)
+	}
+	if tasks := cluster.GetFSM().ListTasks(nil, 0); len(tasks) != callers-1 {
+		t.Errorf("expected %d tasks, got %d", callers-1, len(tasks))
+	}
+	if entries := cluster.raft.LastIndex() - before; entries >= callers {
+		t.Errorf("expected the commands to share log entries, got %d entries", entries)
+	}
+}
+
+func TestApplyQueue_ShutdownResolvesWaitingCallers(t *testing.T) {
+	cluster := setupCluster(t, 1, nil, func(config *ClusterConfig) {
+		config.ApplyBatchSize = 16
+		config.ApplyLinger = time.Hour
+	})
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	data, err := EncodeLogEntry(LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	if err != nil {
+		t.Fatalf("failed to encode log entry: %v", err)
+	}
+	result := make(chan error, 1)
+	go func() { result <- cluster.Apply(data, 5*time.Second) }()
+
+	// The lingering command is submitted before Raft shuts down, so its
+	// caller gets Raft's outcome rather than a blanket error
+	time.Sleep(50 * time.Millisecond)
+	cluster.Shutdown()
+	select {
+	case err := <-result:
+		_, committed := cluster.GetFSM().GetTask("task-1")
+		if err == nil && !committed {
+			t.Error("expected the task to exist when Apply() succeeded")
+		}
+		if err != nil && !errors.Is(err, raft.ErrRaftShutdown) && !errors.Is(err, raft.ErrLeadershipLost) {
+			t.Errorf("expected Raft's own shutdown error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("caller still waiting after shutdown")
+	}
+
+	// Commands arriving after shutdown are refused
+	if err := cluster.Apply(data, 5*time.Second); !errors.Is(err, raft.ErrRaftShutdown) {
+		t.Errorf("expected ErrRaftShutdown after shutdown, got %v", err)
+	}
+}
+
+func TestBatchTimeout_UsesLongest(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts []time.Duration
+		want     time.Duration
+	}{
+		{"single", []time.Duration{time.Second}, time.Second},
+		{"longest wins", []time.Duration{time.Second, 5 * time.Second, 2 * time.Second}, 5 * time.Second},
+		{"no timeout wins", []time.Duration{time.Second, 0, 5 * time.Second}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := make([]*applyRequest, len(tt.timeouts))
+			for i, timeout := range tt.timeouts {
+				batch[i] = &applyRequest{timeout: timeout}
+			}
+			if got := batchTimeout(batch); got != tt.want {
+				t.Errorf("batchTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// benchmarkCallers is the number of goroutines applying commands at once,
+// like agents heartbeating concurrently
+const benchmarkCallers = 64
+
+// benchmarkConcurrentApply applies heartbeats from benchmarkCallers
+// goroutines and reports the throughput
+func benchmarkConcurrentApply(b *testing.B, configure ...func(*ClusterConfig)) {
+	cluster := setupBenchmarkCluster(b, configure...)
+
+	var next atomic.Int64
+	b.SetParallelism(max(1, benchmarkCallers/runtime.GOMAXPROCS(0)))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := next.Add(1)
+			data, err := EncodeLogEntry(LogEntryNodeHeartbeat, NodeHeartbeatEntry{
+				NodeID:    fmt.Sprintf("node-%d", n%benchmarkCallers),
+				Timestamp: n,
+			})
+			if err != nil {
+				b.Errorf("failed to encode log entry: %v", err)
+				return
+			}
+			if err := cluster.Apply(data, 5*time.Second); err != nil {
+				b.Errorf("Apply() returned error: %v", err)
+				return
+			}
+		}
+	})
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "commands/s")
+}
+
+func BenchmarkApply_Unbatched(b *testing.B) {
+	benchmarkConcurrentApply(b)
+}
+
+func BenchmarkApply_Batched(b *testing.B) {
+	benchmarkConcurrentApply(b, func(config *ClusterConfig) {
+		config.ApplyBatchSize = defaultApplyBatchSize
+		config.ApplyLinger = defaultApplyLinger
+	})
+}
+
+func BenchmarkApply_BatchedNoLinger(b *testing.B) {
+	benchmarkConcurrentApply(b, func(config *ClusterConfig) {
+		config.ApplyBatchSize = defaultApplyBatchSize
+	})
+}
@@ -0,0 +1,113 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestFSM_Apply_AddTasks(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "existing"})
+
+	// The reduce task is listed before the map it depends on
+	applyLog(t, fsm, LogEntryAddTasks, AddTasksEntry{Tasks: []AddTaskEntry{
+		{TaskID: "reduce", DependsOn: []string{"map", "existing"}},
+		{TaskID: "map"},
+	}})
+	assertStatus(t, fsm, "map", pb.TaskStatus_PENDING)
+	assertStatus(t, fsm, "reduce", pb.TaskStatus_BLOCKED)
+
+	// One bad task rejects the whole batch
+	tests := []struct {
+		name  string
+		tasks []AddTaskEntry
+		want  error
+	}{
+		{"existing task", []AddTaskEntry{{TaskID: "new-1"}, {TaskID: "map"}}, ErrAlreadyExists},
+		{"duplicate in batch", []AddTaskEntry{{TaskID: "new-1"}, {TaskID: "new-1"}}, ErrAlreadyExists},
+		{"unknown dependency", []AddTaskEntry{{TaskID: "new-1"}, {TaskID: "new-2", DependsOn: []string{"missing"}}}, ErrUnknownTask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyLogErr(t, fsm, LogEntryAddTasks, AddTasksEntry{Tasks: tt.tasks})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if _, found := fsm.GetTask("new-1"); found {
+				t.Error("expected no task of a rejected batch to be added")
+			}
+		})
+	}
+}
+
+// benchmarkBatchSize is the number of tasks submitted per benchmark iteration
+const benchmarkBatchSize = 100
+
+// BenchmarkSubmit_EntryPerTask commits every task in its own log entry
+func BenchmarkSubmit_EntryPerTask(b *testing.B) {
+	cluster := setupBenchmarkCluster(b)
+	created := time.Now().Unix()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < benchmarkBatchSize; j++ {
+			data, err := EncodeLogEntry(LogEntryAddTask, benchmarkTask(i, j, created))
+			if err != nil {
+				b.Fatalf("failed to encode log entry: %v", err)
+			}
+			if err := cluster.Apply(data, 5*time.Second); err != nil {
+				b.Fatalf("Apply() returned error: %v", err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkBatchSize)/b.Elapsed().Seconds(), "tasks/s")
+}
+
+// BenchmarkSubmit_BatchEntry commits the same tasks in one entry per batch
+func BenchmarkSubmit_BatchEntry(b *testing.B) {
+	cluster := setupBenchmarkCluster(b)
+	created := time.Now().Unix()
+
+	for i := 0; i < b.N; i++ {
+		entry := AddTasksEntry{Tasks: make([]AddTaskEntry, benchmarkBatchSize)}
+		for j := range entry.Tasks {
+			entry.Tasks[j] = benchmarkTask(i, j, created)
+		}
+		data, err := EncodeLogEntry(LogEntryAddTasks, entry)
+		if err != nil {
+			b.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			b.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkBatchSize)/b.Elapsed().Seconds(), "tasks/s")
+}
+
+// setupBenchmarkCluster starts a single-node cluster and waits until it leads
+func setupBenchmarkCluster(b *testing.B, configure ...func(*ClusterConfig)) *RaftCluster {
+	b.Helper()
+	cluster := setupCluster(b, 1, nil, configure...)
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		b.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		b.Fatalf("no leader elected: %v", err)
+	}
+	b.ResetTimer()
+	return cluster
+}
+
+// benchmarkTask describes a matmul block task the size of a planned one
+func benchmarkTask(batch, index int, created int64) AddTaskEntry {
+	return AddTaskEntry{
+		TaskID:    fmt.Sprintf("block-%d-%d", batch, index),
+		TaskType:  "matmul_map",
+		TaskData:  []byte(fmt.Sprintf(`{"i":%d,"j":%d,"k":0,"rows":256,"cols":256}`, batch, index)),
+		CreatedAt: created,
+	}
+}
@@ -0,0 +1,140 @@
+package raft
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestFSM_Apply_CancelTask(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "queued"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "training"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "eval", DependsOn: []string{"training"}})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "training", NodeID: "node-1", LeaseExpiresAt: 100}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	// Unassigned tasks are cancelled at once
+	if err := applyAt(t, fsm, 11, LogEntryCancelTask, CancelTaskEntry{TaskID: "queued", CancelledAt: 50}); err != nil {
+		t.Fatalf("cancel returned error: %v", err)
+	}
+	assertStatus(t, fsm, "queued", pb.TaskStatus_CANCELLED)
+	if len(fsm.PendingTasks()) != 0 {
+		t.Errorf("expected the cancelled task to leave the queue")
+	}
+
+	// Assigned tasks wait for the agent and keep their node
+	if err := applyAt(t, fsm, 12, LogEntryCancelTask, CancelTaskEntry{TaskID: "training", CancelledAt: 50}); err != nil {
+		t.Fatalf("cancel returned error: %v", err)
+	}
+	assertStatus(t, fsm, "training", pb.TaskStatus_CANCELLING)
+	assertStatus(t, fsm, "eval", pb.TaskStatus_BLOCKED)
+	if node, _ := fsm.GetNode("node-1"); node.ActiveTasks != 1 {
+		t.Errorf("expected node-1 still busy, got %d active tasks", node.ActiveTasks)
+	}
+	if cancelling := fsm.CancellingTasks("node-1"); len(cancelling) != 1 || cancelling[0] != "training" {
+		t.Errorf("expected training to be cancelling on node-1, got %v", cancelling)
+	}
+
+	err := applyAt(t, fsm, 13, LogEntryCancelTask, CancelTaskEntry{TaskID: "training"})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition cancelling twice, got %v", err)
+	}
+	err = applyAt(t, fsm, 14, LogEntryCompleteTask, CompleteTaskEntry{TaskID: "training", FencingToken: 10})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition completing a cancelling task, got %v", err)
+	}
+	err = applyAt(t, fsm, 15, LogEntryAcknowledgeCancel, AcknowledgeCancelEntry{TaskID: "training", FencingToken: 9})
+	if !errors.Is(err, ErrStaleFencingToken) {
+		t.Errorf("expected ErrStaleFencingToken, got %v", err)
+	}
+
+	if err := applyAt(t, fsm, 16, LogEntryAcknowledgeCancel, AcknowledgeCancelEntry{TaskID: "training", FencingToken: 10, CancelledAt: 60}); err != nil {
+		t.Fatalf("acknowledge returned error: %v", err)
+	}
+	assertStatus(t, fsm, "training", pb.TaskStatus_CANCELLED)
+	assertStatus(t, fsm, "eval", pb.TaskStatus_FAILED)
+	if node, _ := fsm.GetNode("node-1"); node.ActiveTasks != 0 {
+		t.Errorf("expected node-1 released, got %d active tasks", node.ActiveTasks)
+	}
+	if cancelling := fsm.CancellingTasks("node-1"); len(cancelling) != 0 {
+		t.Errorf("expected no cancelling tasks on node-1, got %v", cancelling)
+	}
+
+	err = applyAt(t, fsm, 17, LogEntryCancelTask, CancelTaskEntry{TaskID: "missing"})
+	if !errors.Is(err, ErrUnknownTask) {
+		t.Errorf("expected ErrUnknownTask, got %v", err)
+	}
+}
+
+func TestFSM_Apply_CancellingTaskLeaseExpires(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1", LeaseExpiresAt: 100}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+	if err := applyAt(t, fsm, 11, LogEntryCancelTask, CancelTaskEntry{TaskID: "task-1", CancelledAt: 50}); err != nil {
+		t.Fatalf("cancel returned error: %v", err)
+	}
+
+	// The agent never acknowledges, so the lapsed lease finishes the cancellation
+	if err := applyAt(t, fsm, 12, LogEntryExpireLease, ExpireLeaseEntry{TaskID: "task-1", FencingToken: 10, ExpiredAt: 100}); err != nil {
+		t.Fatalf("expire returned error: %v", err)
+	}
+	assertStatus(t, fsm, "task-1", pb.TaskStatus_CANCELLED)
+	if node, _ := fsm.GetNode("node-1"); node.ActiveTasks != 0 {
+		t.Errorf("expected node-1 released, got %d active tasks", node.ActiveTasks)
+	}
+	if len(fsm.PendingTasks()) != 0 {
+		t.Errorf("expected the cancelled task not to be requeued")
+	}
+}
+
+func TestFSM_CancellingTasks_FollowsReclaimAndRestore(t *testing.T) {
+	fsm := setupFSM(t)
+	for _, nodeID := range []string{"node-1", "node-2"} {
+		applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: nodeID})
+	}
+	for i, assignment := range []struct{ taskID, nodeID string }{
+		{"task-b", "node-1"}, {"task-a", "node-1"}, {"task-c", "node-2"},
+	} {
+		applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: assignment.taskID})
+		if err := applyAt(t, fsm, uint64(10+i), LogEntryAssignTask, AssignTaskEntry{TaskID: assignment.taskID, NodeID: assignment.nodeID}); err != nil {
+			t.Fatalf("assign returned error: %v", err)
+		}
+		applyLog(t, fsm, LogEntryCancelTask, CancelTaskEntry{TaskID: assignment.taskID, CancelledAt: 50})
+	}
+
+	var buf bytes.Buffer
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("fsm.Snapshot() returned error: %v", err)
+	}
+	if err := snapshot.Persist(&mockSnapshotSink{writer: &buf}); err != nil {
+		t.Fatalf("snapshot.Persist() returned error: %v", err)
+	}
+	restored := setupFSM(t)
+	if err := restored.Restore(io.NopCloser(&buf)); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+
+	for _, f := range []*TaskManifestFSM{fsm, restored} {
+		if got := f.CancellingTasks("node-1"); !reflect.DeepEqual(got, []string{"task-a", "task-b"}) {
+			t.Errorf("CancellingTasks(node-1) = %v", got)
+		}
+		applyLog(t, f, LogEntryMarkNodeUnhealthy, MarkNodeUnhealthyEntry{NodeID: "node-1", DetectedAt: 100})
+		if got := f.CancellingTasks("node-1"); len(got) != 0 {
+			t.Errorf("expected no cancelling tasks on a reclaimed node, got %v", got)
+		}
+		if got := f.CancellingTasks("node-2"); !reflect.DeepEqual(got, []string{"task-c"}) {
+			t.Errorf("CancellingTasks(node-2) = %v", got)
+		}
+	}
+}
@@ -1,12 +1,19 @@
 package raft
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"ml-raft-control-plane/internal/scheduler"
+	pb "ml-raft-control-plane/pkg/proto"
+
 	"github.com/hashicorp/raft"
 	raftboltdb "github.com/hashicorp/raft-boltdb"
 )
@@ -20,20 +27,58 @@ type RaftCluster struct {
 	logStore      *raftboltdb.BoltStore
 	stableStore   *raftboltdb.BoltStore
 	snapshotStore raft.SnapshotStore
+
+	// detectorMu guards failure detector statistics
+	detectorMu    sync.Mutex
+	detectorStats FailureDetectorStats
+
+	// progressMu guards progress reports waiting to be committed
+	progressMu      sync.Mutex
+	pendingProgress map[string]ProgressUpdate
+
+	// applyQueue feeds commands to the batching apply queue; it is nil when
+	// batching is disabled. applyMu guards sending on it against Shutdown
+	// closing it, and applyDone is closed once the queue has drained.
+	applyQueue  chan *applyRequest
+	applyMu     sync.RWMutex
+	applyClosed bool
+	applyDone   chan struct{}
+
+	// placementMu serializes placement rounds and guards the tasks the last
+	// round found to fit nowhere; placementRounds counts the finished rounds
+	// so callers that waited for one can skip their own
+	placementMu     sync.Mutex
+	placementRounds atomic.Uint64
+	unplaceable     map[string]uint64
 }
 
 // ClusterConfig holds Raft cluster configuration
 type ClusterConfig struct {
-	NodeID            string
-	BindAddress       string
-	DataDir           string
-	BootstrapExpect   int
-	Peers             []string
-	HeartbeatTimeout  time.Duration
-	ElectionTimeout   time.Duration
-	CommitTimeout     time.Duration
-	SnapshotInterval  time.Duration
-	SnapshotThreshold uint64
+	NodeID                string
+	BindAddress           string
+	GRPCAddress           string
+	CloudProvider         string
+	Region                string
+	DataDir               string
+	BootstrapExpect       int
+	BootstrapTimeout      time.Duration
+	Peers                 []Peer
+	HeartbeatTimeout      time.Duration
+	ElectionTimeout       time.Duration
+	CommitTimeout         time.Duration
+	SnapshotInterval      time.Duration
+	SnapshotThreshold     uint64
+	RetryInterval         time.Duration
+	NodeTimeout           time.Duration
+	FailureCheckInterval  time.Duration
+	TaskLeaseDuration     time.Duration
+	ProgressFlushInterval time.Duration
+	ApplyBatchSize        int           // Most commands per log entry; 1 or less disables batching
+	ApplyLinger           time.Duration // How long a command waits for others to join its batch
+	PlacementInterval     time.Duration
+	MaxPlacementsPerRound int                 // Most tasks placed per log entry
+	Scheduler             scheduler.Scheduler // Places PENDING tasks; defaults to least-loaded
+	DisablePreemption     bool
 }
 
 // NewRaftCluster creates and initializes a new Raft cluster
@@ -99,19 +144,30 @@ func NewRaftCluster(config *ClusterConfig) (*RaftCluster, error) {
 		stableStore:   stableStore,
 		snapshotStore: snapshotStore,
 	}
-
-	// Bootstrap cluster if needed
-	if config.BootstrapExpect > 0 {
-		if err := cluster.bootstrap(); err != nil {
-			return nil, fmt.Errorf("failed to bootstrap cluster: %w", err)
-		}
+	if config.ApplyBatchSize > 1 {
+		cluster.applyQueue = make(chan *applyRequest, config.ApplyBatchSize)
+		cluster.applyDone = make(chan struct{})
+		go cluster.runApplyQueue()
 	}
 
 	return cluster, nil
 }
 
-// bootstrap initializes the Raft cluster
-func (rc *RaftCluster) bootstrap() error {
+// PeerProbe contacts a peer and returns the server ID it reports for itself
+type PeerProbe func(ctx context.Context, peer Peer) (string, error)
+
+// peerProbeInterval is how often bootstrap retries unreachable peers
+const peerProbeInterval = time.Second
+
+// Bootstrap initializes a new cluster from the configured peers once
+// BootstrapExpect servers (including this one) are reachable and have
+// confirmed their IDs through probe. It is a no-op when BootstrapExpect
+// is 0 or the node already has Raft state.
+func (rc *RaftCluster) Bootstrap(ctx context.Context, probe PeerProbe) error {
+	if rc.config.BootstrapExpect <= 0 {
+		return nil
+	}
+
 	// Check if already bootstrapped
 	hasState, err := raft.HasExistingState(rc.logStore, rc.stableStore, rc.snapshotStore)
 	if err != nil {
@@ -123,6 +179,16 @@ func (rc *RaftCluster) bootstrap() error {
 		return nil
 	}
 
+	if rc.config.BootstrapTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rc.config.BootstrapTimeout)
+		defer cancel()
+	}
+
+	if err := rc.waitForPeers(ctx, probe); err != nil {
+		return err
+	}
+
 	// Build server configuration
 	servers := []raft.Server{
 		{
@@ -131,13 +197,14 @@ func (rc *RaftCluster) bootstrap() error {
 		},
 	}
 
-	// Add peer servers
-	for _, peerAddr := range rc.config.Peers {
-		// Extract node ID from address (assuming format: ip:port)
-		peerID := fmt.Sprintf("node-%s", peerAddr)
+	// Add every configured peer under its configured ID. When BootstrapExpect
+	// is below the cluster size this includes peers waitForPeers never heard
+	// from; every node must bootstrap with the same configuration, so they
+	// are not left out.
+	for _, peer := range rc.config.Peers {
 		servers = append(servers, raft.Server{
-			ID:      raft.ServerID(peerID),
-			Address: raft.ServerAddress(peerAddr),
+			ID:      raft.ServerID(peer.ID),
+			Address: raft.ServerAddress(peer.Address),
 		})
 	}
 
@@ -155,21 +222,82 @@ func (rc *RaftCluster) bootstrap() error {
 	return nil
 }
 
+// waitForPeers blocks until BootstrapExpect-1 peers answer the probe with
+// their configured IDs. A peer reporting a different ID aborts bootstrap.
+func (rc *RaftCluster) waitForPeers(ctx context.Context, probe PeerProbe) error {
+	needed := rc.config.BootstrapExpect - 1
+	if needed <= 0 {
+		return nil
+	}
+	if needed > len(rc.config.Peers) {
+		return fmt.Errorf("bootstrap_expect is %d but only %d peers are configured",
+			rc.config.BootstrapExpect, len(rc.config.Peers))
+	}
+	if probe == nil {
+		return fmt.Errorf("bootstrap_expect is %d but no peer probe was provided", rc.config.BootstrapExpect)
+	}
+
+	ticker := time.NewTicker(peerProbeInterval)
+	defer ticker.Stop()
+
+	confirmed := make(map[string]bool)
+	for {
+		for _, peer := range rc.config.Peers {
+			if confirmed[peer.ID] {
+				continue
+			}
+
+			reportedID, err := probe(ctx, peer)
+			if err != nil {
+				continue // Not reachable yet
+			}
+			if reportedID != peer.ID {
+				return fmt.Errorf("%w: peer at %s reports id %q, configured as %q",
+					ErrPeerMismatch, peer.Address, reportedID, peer.ID)
+			}
+			confirmed[peer.ID] = true
+		}
+
+		if len(confirmed) >= needed {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("only %d of %d expected peers reachable: %w", len(confirmed), needed, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
 // Apply submits a log entry to Raft for consensus
 func (rc *RaftCluster) Apply(data []byte, timeout time.Duration) error {
+	_, err := rc.ApplyWithResponse(data, timeout)
+	return err
+}
+
+// ApplyWithResponse submits a log entry to Raft and returns the FSM's
+// non-error response, such as the number of tasks a command affected.
+// When batching is enabled the command may share a log entry with those of
+// concurrent callers.
+func (rc *RaftCluster) ApplyWithResponse(data []byte, timeout time.Duration) (interface{}, error) {
+	if rc.applyQueue != nil {
+		return rc.enqueueApply(data, timeout)
+	}
+
 	future := rc.raft.Apply(data, timeout)
 	if err := future.Error(); err != nil {
-		return fmt.Errorf("failed to apply log entry: %w", err)
+		return nil, fmt.Errorf("failed to apply log entry: %w", err)
 	}
+	return fsmResponse(future.Response())
+}
 
-	// Check if the apply returned an error
-	if response := future.Response(); response != nil {
-		if err, ok := response.(error); ok {
-			return fmt.Errorf("FSM apply error: %w", err)
-		}
+// fsmResponse turns an error returned by the FSM into an error of the caller
+func fsmResponse(response interface{}) (interface{}, error) {
+	if err, ok := response.(error); ok {
+		return nil, fmt.Errorf("FSM apply error: %w", err)
 	}
-
-	return nil
+	return response, nil
 }
 
 // IsLeader returns true if this node is the current leader
@@ -177,20 +305,67 @@ func (rc *RaftCluster) IsLeader() bool {
 	return rc.raft.State() == raft.Leader
 }
 
-// GetLeader returns the address of the current leader
+// GetLeader returns the server ID of the current leader
 func (rc *RaftCluster) GetLeader() string {
 	_, leaderID := rc.raft.LeaderWithID()
 	return string(leaderID)
 }
 
+// GetLeaderAddress returns the Raft transport address of the current leader
+func (rc *RaftCluster) GetLeaderAddress() string {
+	leaderAddr, _ := rc.raft.LeaderWithID()
+	return string(leaderAddr)
+}
+
+// LocalServerInfo returns the metadata this node publishes about itself
+func (rc *RaftCluster) LocalServerInfo() *pb.ServerInfo {
+	return &pb.ServerInfo{
+		ServerId:      rc.config.NodeID,
+		RaftAddress:   rc.config.BindAddress,
+		GrpcAddress:   rc.config.GRPCAddress,
+		CloudProvider: rc.config.CloudProvider,
+		Region:        rc.config.Region,
+	}
+}
+
+// LookupServer resolves a Raft server ID to its replicated metadata
+func (rc *RaftCluster) LookupServer(serverID string) (*pb.ServerInfo, bool) {
+	return rc.fsm.GetServer(serverID)
+}
+
+// GetLeaderInfo returns the replicated metadata of the current leader
+func (rc *RaftCluster) GetLeaderInfo() (*pb.ServerInfo, bool) {
+	leaderID := rc.GetLeader()
+	if leaderID == "" {
+		return nil, false
+	}
+	return rc.LookupServer(leaderID)
+}
+
+// TaskLeaseDuration returns how long a task assignment stays valid without renewal
+func (rc *RaftCluster) TaskLeaseDuration() time.Duration {
+	if rc.config.TaskLeaseDuration <= 0 {
+		return defaultTaskLeaseDuration
+	}
+	return rc.config.TaskLeaseDuration
+}
+
 // GetFSM returns the FSM
 func (rc *RaftCluster) GetFSM() *TaskManifestFSM {
 	return rc.fsm
 }
 
-// GetStats returns Raft statistics
+// GetStats returns Raft statistics along with failure detector statistics
 func (rc *RaftCluster) GetStats() map[string]string {
-	return rc.raft.Stats()
+	stats := rc.raft.Stats()
+
+	detector := rc.GetFailureDetectorStats()
+	stats["nodes_marked_unhealthy"] = strconv.FormatUint(detector.NodesMarkedUnhealthy, 10)
+	stats["tasks_reclaimed"] = strconv.FormatUint(detector.TasksReclaimed, 10)
+	stats["leases_expired"] = strconv.FormatUint(detector.LeasesExpired, 10)
+	stats["last_failure_detection_latency"] = detector.LastDetectionLatency.String()
+
+	return stats
 }
 
 // WaitForLeader blocks until a leader is elected or timeout
@@ -214,6 +389,12 @@ func (rc *RaftCluster) WaitForLeader(timeout time.Duration) error {
 
 // Shutdown gracefully shuts down the Raft cluster
 func (rc *RaftCluster) Shutdown() error {
+	// Queued commands reach Raft first, so shutting it down resolves every
+	// waiting caller with the real outcome of its command
+	if rc.applyQueue != nil {
+		rc.closeApplyQueue()
+	}
+
 	future := rc.raft.Shutdown()
 	if err := future.Error(); err != nil {
 		return fmt.Errorf("failed to shutdown raft: %w", err)
@@ -0,0 +1,128 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setupCluster creates an unbootstrapped node expecting the given peers
+func setupCluster(t testing.TB, bootstrapExpect int, peers []Peer, configure ...func(*ClusterConfig)) *RaftCluster {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve address: %v", err)
+	}
+	bindAddress := listener.Addr().String()
+	listener.Close()
+
+	config := &ClusterConfig{
+		NodeID:            "node-a",
+		BindAddress:       bindAddress,
+		DataDir:           t.TempDir(),
+		BootstrapExpect:   bootstrapExpect,
+		BootstrapTimeout:  3 * time.Second,
+		Peers:             peers,
+		HeartbeatTimeout:  500 * time.Millisecond,
+		ElectionTimeout:   500 * time.Millisecond,
+		CommitTimeout:     50 * time.Millisecond,
+		SnapshotInterval:  120 * time.Second,
+		SnapshotThreshold: 8192,
+	}
+	for _, fn := range configure {
+		fn(config)
+	}
+	cluster, err := NewRaftCluster(config)
+	if err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	t.Cleanup(func() { cluster.Shutdown() })
+	return cluster
+}
+
+func TestBootstrap_RefusesPeerIDMismatch(t *testing.T) {
+	peers := []Peer{{ID: "node-b", Address: "127.0.0.1:1"}}
+	cluster := setupCluster(t, 2, peers)
+
+	probe := func(ctx context.Context, peer Peer) (string, error) {
+		return "node-127.0.0.1:1", nil
+	}
+
+	err := cluster.Bootstrap(context.Background(), probe)
+	if !errors.Is(err, ErrPeerMismatch) {
+		t.Fatalf("expected ErrPeerMismatch, got %v", err)
+	}
+	if cluster.GetLeader() != "" {
+		t.Error("expected cluster not to be bootstrapped")
+	}
+}
+
+func TestBootstrap_WaitsForExpectedPeers(t *testing.T) {
+	peers := []Peer{
+		{ID: "node-b", Address: "127.0.0.1:1"},
+		{ID: "node-c", Address: "127.0.0.1:2"},
+	}
+	cluster := setupCluster(t, 3, peers)
+
+	// Only node-b is reachable, so bootstrap_expect of 3 can never be met
+	probe := func(ctx context.Context, peer Peer) (string, error) {
+		if peer.ID == "node-b" {
+			return "node-b", nil
+		}
+		return "", errors.New("connection refused")
+	}
+
+	err := cluster.Bootstrap(context.Background(), probe)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected bootstrap to time out waiting for peers, got %v", err)
+	}
+}
+
+func TestBootstrap_AddsUnconfirmedPeers(t *testing.T) {
+	peers := []Peer{
+		{ID: "node-b", Address: "127.0.0.1:1"},
+		{ID: "node-c", Address: "127.0.0.1:2"},
+	}
+	cluster := setupCluster(t, 2, peers)
+
+	// bootstrap_expect of 2 is met by node-b alone
+	probe := func(ctx context.Context, peer Peer) (string, error) {
+		if peer.ID == "node-b" {
+			return "node-b", nil
+		}
+		return "", errors.New("connection refused")
+	}
+
+	if err := cluster.Bootstrap(context.Background(), probe); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	future := cluster.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		t.Fatalf("GetConfiguration() returned error: %v", err)
+	}
+	var got []string
+	for _, server := range future.Configuration().Servers {
+		got = append(got, string(server.ID)+"@"+string(server.Address))
+	}
+	want := []string{cluster.config.NodeID + "@" + cluster.config.BindAddress, "node-b@127.0.0.1:1", "node-c@127.0.0.1:2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bootstrapped with %v, want %v", got, want)
+	}
+}
+
+func TestBootstrap_SingleNode(t *testing.T) {
+	cluster := setupCluster(t, 1, nil)
+
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+	if !cluster.IsLeader() {
+		t.Error("expected single node to become leader")
+	}
+}
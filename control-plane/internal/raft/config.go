@@ -3,30 +3,88 @@ package raft
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"ml-raft-control-plane/internal/scheduler"
+)
+
+const (
+	// defaultBootstrapTimeout bounds how long bootstrap waits for expected peers
+	defaultBootstrapTimeout = 5 * time.Minute
+
+	// defaultRetryInterval is how often the leader scans for FAILED tasks to retry
+	defaultRetryInterval = 5 * time.Second
+
+	// defaultNodeTimeout is how long a node may go without heartbeating before it is
+	// declared dead. Together with the check interval it keeps MTTR well under 90s.
+	defaultNodeTimeout = 30 * time.Second
+
+	// defaultFailureCheckInterval is how often the leader scans for dead nodes
+	// and lapsed task leases
+	defaultFailureCheckInterval = 5 * time.Second
+
+	// defaultTaskLeaseDuration is how long an assignment stays valid without renewal
+	defaultTaskLeaseDuration = 60 * time.Second
+
+	// defaultProgressFlushInterval is how often the leader commits buffered
+	// progress reports
+	defaultProgressFlushInterval = 5 * time.Second
+
+	// defaultPlacementInterval is how often the leader places PENDING tasks
+	defaultPlacementInterval = time.Second
+
+	// defaultMaxPlacementsPerRound is the most tasks placed in one log entry
+	defaultMaxPlacementsPerRound = 256
+
+	// defaultApplyBatchSize is the most commands committed in one log entry
+	defaultApplyBatchSize = 64
+
+	// defaultApplyLinger is how long a command waits for concurrent commands
+	// to join its batch; short next to a cross-cloud commit
+	defaultApplyLinger = time.Millisecond
 )
 
 // NodeConfig represents the configuration file structure
 type NodeConfig struct {
-	NodeID           string   `json:"node_id"`
-	BindAddress      string   `json:"bind_address"`
-	AdvertiseAddress string   `json:"advertise_address"`
-	CloudProvider    string   `json:"cloud_provider"`
-	Region           string   `json:"region"`
-	DataDir          string   `json:"data_dir"`
-	BootstrapExpect  int      `json:"bootstrap_expect"`
-	Peers            []string `json:"peers"`
+	NodeID           string `json:"node_id"`
+	BindAddress      string `json:"bind_address"`
+	AdvertiseAddress string `json:"advertise_address"`
+	CloudProvider    string `json:"cloud_provider"`
+	Region           string `json:"region"`
+	DataDir          string `json:"data_dir"`
+	BootstrapExpect  int    `json:"bootstrap_expect"`
+	Peers            []Peer `json:"peers"`
 	Raft             struct {
 		HeartbeatTimeout  string `json:"heartbeat_timeout"`
 		ElectionTimeout   string `json:"election_timeout"`
 		CommitTimeout     string `json:"commit_timeout"`
 		SnapshotInterval  string `json:"snapshot_interval"`
 		SnapshotThreshold uint64 `json:"snapshot_threshold"`
+		BootstrapTimeout  string `json:"bootstrap_timeout"` // optional, defaults to 5m
+		ApplyBatchSize    int    `json:"apply_batch_size"`  // optional, defaults to 64; 1 disables batching
+		ApplyLinger       string `json:"apply_linger"`      // optional, defaults to 1ms
 	} `json:"raft"`
+	Leader struct {
+		RetryInterval         string `json:"retry_interval"`           // optional, defaults to 5s
+		NodeTimeout           string `json:"node_timeout"`             // optional, defaults to 30s
+		FailureCheckInterval  string `json:"failure_check_interval"`   // optional, defaults to 5s
+		TaskLeaseDuration     string `json:"task_lease_duration"`      // optional, defaults to 60s
+		ProgressFlushInterval string `json:"progress_flush_interval"`  // optional, defaults to 5s
+		PlacementInterval     string `json:"placement_interval"`       // optional, defaults to 1s
+		MaxPlacementsPerRound int    `json:"max_placements_per_round"` // optional, defaults to 256
+	} `json:"leader"`
+	Scheduler struct {
+		Strategy          string `json:"strategy"`           // least_loaded (default), bin_packing, spread or random_of_two
+		DisablePreemption bool   `json:"disable_preemption"` // optional, preemption is on by default
+	} `json:"scheduler"`
 	GRPC struct {
-		Port                 int `json:"port"`
-		MaxConcurrentStreams int `json:"max_concurrent_streams"`
+		Port                 int    `json:"port"`
+		MaxConcurrentStreams int    `json:"max_concurrent_streams"`
+		LeaderForwarding     string `json:"leader_forwarding"` // "proxy" (default) or "redirect"
 	} `json:"grpc"`
 }
 
@@ -52,6 +110,9 @@ func LoadConfig(path string) (*NodeConfig, error) {
 	if config.DataDir == "" {
 		return nil, fmt.Errorf("data_dir is required")
 	}
+	if err := config.validatePeers(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
@@ -79,16 +140,223 @@ func (nc *NodeConfig) ToClusterConfig() (*ClusterConfig, error) {
 		return nil, fmt.Errorf("invalid snapshot_interval: %w", err)
 	}
 
+	grpcAddress, err := nc.GRPCAdvertiseAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrapTimeout, err := parseOptionalDuration(nc.Raft.BootstrapTimeout, defaultBootstrapTimeout, "bootstrap_timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	applyLinger, err := parseOptionalDuration(nc.Raft.ApplyLinger, defaultApplyLinger, "apply_linger")
+	if err != nil {
+		return nil, err
+	}
+	applyBatchSize := nc.Raft.ApplyBatchSize
+	if applyBatchSize == 0 {
+		applyBatchSize = defaultApplyBatchSize
+	}
+	if applyBatchSize < 0 {
+		return nil, fmt.Errorf("apply_batch_size must not be negative")
+	}
+
+	retryInterval, err := parseOptionalDuration(nc.Leader.RetryInterval, defaultRetryInterval, "retry_interval")
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTimeout, err := parseOptionalDuration(nc.Leader.NodeTimeout, defaultNodeTimeout, "node_timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	failureCheckInterval, err := parseOptionalDuration(nc.Leader.FailureCheckInterval, defaultFailureCheckInterval, "failure_check_interval")
+	if err != nil {
+		return nil, err
+	}
+
+	taskLeaseDuration, err := parseOptionalDuration(nc.Leader.TaskLeaseDuration, defaultTaskLeaseDuration, "task_lease_duration")
+	if err != nil {
+		return nil, err
+	}
+
+	progressFlushInterval, err := parseOptionalDuration(nc.Leader.ProgressFlushInterval, defaultProgressFlushInterval, "progress_flush_interval")
+	if err != nil {
+		return nil, err
+	}
+
+	placementInterval, err := parseOptionalDuration(nc.Leader.PlacementInterval, defaultPlacementInterval, "placement_interval")
+	if err != nil {
+		return nil, err
+	}
+	maxPlacementsPerRound := nc.Leader.MaxPlacementsPerRound
+	if maxPlacementsPerRound == 0 {
+		maxPlacementsPerRound = defaultMaxPlacementsPerRound
+	}
+	if maxPlacementsPerRound < 0 {
+		return nil, fmt.Errorf("max_placements_per_round must not be negative")
+	}
+
+	taskScheduler, err := scheduler.New(nc.Scheduler.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Peers without an explicit gRPC address serve gRPC on the same port as this node
+	peers := make([]Peer, len(nc.Peers))
+	for i, peer := range nc.Peers {
+		peers[i] = peer
+		if peer.GRPCAddress == "" {
+			host, _, _ := net.SplitHostPort(peer.Address)
+			peers[i].GRPCAddress = net.JoinHostPort(host, strconv.Itoa(nc.GRPC.Port))
+		}
+	}
+
 	return &ClusterConfig{
-		NodeID:            nc.NodeID,
-		BindAddress:       nc.BindAddress,
-		DataDir:           nc.DataDir,
-		BootstrapExpect:   nc.BootstrapExpect,
-		Peers:             nc.Peers,
-		HeartbeatTimeout:  heartbeatTimeout,
-		ElectionTimeout:   electionTimeout,
-		CommitTimeout:     commitTimeout,
-		SnapshotInterval:  snapshotInterval,
-		SnapshotThreshold: nc.Raft.SnapshotThreshold,
+		NodeID:                nc.NodeID,
+		BindAddress:           nc.BindAddress,
+		GRPCAddress:           grpcAddress,
+		CloudProvider:         nc.CloudProvider,
+		Region:                nc.Region,
+		DataDir:               nc.DataDir,
+		BootstrapExpect:       nc.BootstrapExpect,
+		Peers:                 peers,
+		BootstrapTimeout:      bootstrapTimeout,
+		HeartbeatTimeout:      heartbeatTimeout,
+		ElectionTimeout:       electionTimeout,
+		CommitTimeout:         commitTimeout,
+		SnapshotInterval:      snapshotInterval,
+		SnapshotThreshold:     nc.Raft.SnapshotThreshold,
+		RetryInterval:         retryInterval,
+		NodeTimeout:           nodeTimeout,
+		FailureCheckInterval:  failureCheckInterval,
+		TaskLeaseDuration:     taskLeaseDuration,
+		ProgressFlushInterval: progressFlushInterval,
+		ApplyBatchSize:        applyBatchSize,
+		ApplyLinger:           applyLinger,
+		PlacementInterval:     placementInterval,
+		MaxPlacementsPerRound: maxPlacementsPerRound,
+		Scheduler:             taskScheduler,
+		DisablePreemption:     nc.Scheduler.DisablePreemption,
 	}, nil
 }
+
+// parseOptionalDuration parses a duration setting, returning def when it is unset
+func parseOptionalDuration(value string, def time.Duration, name string) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return d, nil
+}
+
+// GRPCAdvertiseAddress returns the address other nodes use to reach this node's
+// gRPC server: the advertise host (or bind host if unset) with the gRPC port
+func (nc *NodeConfig) GRPCAdvertiseAddress() (string, error) {
+	advertise := nc.AdvertiseAddress
+	if advertise == "" {
+		advertise = nc.BindAddress
+	}
+
+	host, _, err := net.SplitHostPort(advertise)
+	if err != nil {
+		return "", fmt.Errorf("invalid advertise_address: %w", err)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(nc.GRPC.Port)), nil
+}
+
+// Peer identifies another control-plane server. In the config file a peer is
+// either an "id@host:port" string or an object with id, address and an
+// optional grpc_address.
+type Peer struct {
+	ID          string `json:"id"`
+	Address     string `json:"address"`
+	GRPCAddress string `json:"grpc_address,omitempty"`
+}
+
+// ParsePeer parses an "id@host:port" peer spec
+func ParsePeer(spec string) (Peer, error) {
+	id, address, found := strings.Cut(spec, "@")
+	if !found {
+		return Peer{}, fmt.Errorf("peer %q must be in id@host:port form", spec)
+	}
+
+	peer := Peer{ID: id, Address: address}
+	if err := peer.validate(); err != nil {
+		return Peer{}, err
+	}
+	return peer, nil
+}
+
+// UnmarshalJSON accepts either an "id@host:port" string or a peer object
+func (p *Peer) UnmarshalJSON(data []byte) error {
+	var spec string
+	if err := json.Unmarshal(data, &spec); err == nil {
+		peer, err := ParsePeer(spec)
+		if err != nil {
+			return err
+		}
+		*p = peer
+		return nil
+	}
+
+	// Decode through an alias to avoid recursing into this method
+	type peerObject Peer
+	var obj peerObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("invalid peer %s: %w", data, err)
+	}
+
+	peer := Peer(obj)
+	if err := peer.validate(); err != nil {
+		return err
+	}
+	*p = peer
+	return nil
+}
+
+// validate checks that a peer has an ID and a host:port address
+func (p Peer) validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("peer %q is missing an id", p.Address)
+	}
+	if _, _, err := net.SplitHostPort(p.Address); err != nil {
+		return fmt.Errorf("peer %s has invalid address %q: %w", p.ID, p.Address, err)
+	}
+	if p.GRPCAddress != "" {
+		if _, _, err := net.SplitHostPort(p.GRPCAddress); err != nil {
+			return fmt.Errorf("peer %s has invalid grpc_address %q: %w", p.ID, p.GRPCAddress, err)
+		}
+	}
+	return nil
+}
+
+// validatePeers rejects duplicate or self-referencing peers and an unreachable bootstrap_expect
+func (nc *NodeConfig) validatePeers() error {
+	ids := map[string]bool{nc.NodeID: true}
+	addresses := map[string]bool{nc.BindAddress: true}
+
+	for _, peer := range nc.Peers {
+		if ids[peer.ID] {
+			return fmt.Errorf("peer id %s is duplicated or matches node_id", peer.ID)
+		}
+		if addresses[peer.Address] {
+			return fmt.Errorf("peer address %s is duplicated or matches bind_address", peer.Address)
+		}
+		ids[peer.ID] = true
+		addresses[peer.Address] = true
+	}
+
+	if nc.BootstrapExpect > len(nc.Peers)+1 {
+		return fmt.Errorf("bootstrap_expect is %d but only %d servers are configured",
+			nc.BootstrapExpect, len(nc.Peers)+1)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package raft
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestParsePeer(t *testing.T) {
+	peer, err := ParsePeer("gcp-node-1@34.122.34.56:8080")
+	if err != nil {
+		t.Fatalf("ParsePeer() returned error: %v", err)
+	}
+	if peer.ID != "gcp-node-1" || peer.Address != "34.122.34.56:8080" {
+		t.Errorf("unexpected peer: %+v", peer)
+	}
+
+	for _, spec := range []string{"34.122.34.56:8080", "@34.122.34.56:8080", "gcp-node-1@34.122.34.56"} {
+		if _, err := ParsePeer(spec); err == nil {
+			t.Errorf("expected ParsePeer(%q) to fail", spec)
+		}
+	}
+}
+
+func TestPeer_UnmarshalJSON_StringAndObject(t *testing.T) {
+	var peers []Peer
+	data := `["aws-node-2@10.0.0.2:8080", {"id": "gcp-node-1", "address": "10.1.0.1:8080", "grpc_address": "10.1.0.1:6000"}]`
+	if err := json.Unmarshal([]byte(data), &peers); err != nil {
+		t.Fatalf("failed to unmarshal peers: %v", err)
+	}
+
+	if len(peers) != 2 {
+		t.Fatalf("expected 2 peers, got %d", len(peers))
+	}
+	if peers[0].ID != "aws-node-2" || peers[0].Address != "10.0.0.2:8080" {
+		t.Errorf("unexpected string peer: %+v", peers[0])
+	}
+	if peers[1].ID != "gcp-node-1" || peers[1].GRPCAddress != "10.1.0.1:6000" {
+		t.Errorf("unexpected object peer: %+v", peers[1])
+	}
+}
+
+func TestLoadConfig_PeerValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		peers   string
+		expect  int
+		wantErr bool
+	}{
+		{"valid", `["b@10.0.0.2:8080", "c@10.0.0.3:8080"]`, 3, false},
+		{"bare address", `["10.0.0.2:8080"]`, 2, true},
+		{"duplicate id", `["b@10.0.0.2:8080", "b@10.0.0.3:8080"]`, 3, true},
+		{"self id", `["a@10.0.0.2:8080"]`, 2, true},
+		{"self address", `["b@10.0.0.1:8080"]`, 2, true},
+		{"bootstrap_expect too large", `["b@10.0.0.2:8080"]`, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := `{
+				"node_id": "a",
+				"bind_address": "10.0.0.1:8080",
+				"data_dir": "/tmp/raft",
+				"bootstrap_expect": ` + strconv.Itoa(tt.expect) + `,
+				"peers": ` + tt.peers + `
+			}`
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			_, err := LoadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToClusterConfig_DefaultsPeerGRPCAddress(t *testing.T) {
+	nc := &NodeConfig{
+		NodeID:      "a",
+		BindAddress: "10.0.0.1:8080",
+		DataDir:     "/tmp/raft",
+		Peers:       []Peer{{ID: "b", Address: "10.0.0.2:8080"}},
+	}
+	nc.Raft.HeartbeatTimeout = "1s"
+	nc.Raft.ElectionTimeout = "3s"
+	nc.Raft.CommitTimeout = "500ms"
+	nc.Raft.SnapshotInterval = "120s"
+	nc.GRPC.Port = 50051
+
+	cc, err := nc.ToClusterConfig()
+	if err != nil {
+		t.Fatalf("ToClusterConfig() returned error: %v", err)
+	}
+	if cc.GRPCAddress != "10.0.0.1:50051" {
+		t.Errorf("expected local gRPC address 10.0.0.1:50051, got %s", cc.GRPCAddress)
+	}
+	if cc.Peers[0].GRPCAddress != "10.0.0.2:50051" {
+		t.Errorf("expected peer gRPC address 10.0.0.2:50051, got %s", cc.Peers[0].GRPCAddress)
+	}
+}
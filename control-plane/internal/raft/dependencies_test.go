@@ -0,0 +1,96 @@
+package raft
+
+import (
+	"testing"
+
+	"ml-raft-control-plane/internal/models"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// runTask assigns a task to node-1 at the given log index and reports its outcome
+func runTask(t *testing.T, fsm *TaskManifestFSM, index uint64, taskID string, outcome LogEntryType) {
+	t.Helper()
+	if err := applyAt(t, fsm, index, LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: "node-1"}); err != nil {
+		t.Fatalf("assign %s returned error: %v", taskID, err)
+	}
+
+	var err error
+	switch outcome {
+	case LogEntryCompleteTask:
+		err = applyAt(t, fsm, index+1, outcome, CompleteTaskEntry{TaskID: taskID, FencingToken: index, CompletedAt: 200})
+	case LogEntryFailTask:
+		err = applyAt(t, fsm, index+1, outcome, FailTaskEntry{TaskID: taskID, FencingToken: index, ErrorMessage: "boom", FailedAt: 200})
+	}
+	if err != nil {
+		t.Fatalf("reporting %s returned error: %v", taskID, err)
+	}
+}
+
+func assertStatus(t *testing.T, fsm *TaskManifestFSM, taskID string, want pb.TaskStatus) {
+	t.Helper()
+	if task, _ := fsm.GetTask(taskID); task.Status != want {
+		t.Errorf("expected %s to be %s, got %s", taskID, want, task.Status)
+	}
+}
+
+func TestFSM_Apply_DependenciesUnblockOnCompletion(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+
+	// The reduce task is listed before the maps it depends on
+	applyLog(t, fsm, LogEntrySubmitJob, SubmitJobEntry{
+		JobID: "job-1",
+		Tasks: []AddTaskEntry{
+			{TaskID: "reduce", DependsOn: []string{"map-0", "map-1"}},
+			{TaskID: "map-0"},
+			{TaskID: "map-1"},
+		},
+	})
+	assertStatus(t, fsm, "reduce", pb.TaskStatus_BLOCKED)
+	assertStatus(t, fsm, "map-0", pb.TaskStatus_PENDING)
+
+	runTask(t, fsm, 10, "map-0", LogEntryCompleteTask)
+	assertStatus(t, fsm, "reduce", pb.TaskStatus_BLOCKED)
+
+	runTask(t, fsm, 20, "map-1", LogEntryCompleteTask)
+	assertStatus(t, fsm, "reduce", pb.TaskStatus_PENDING)
+	if pending := fsm.PendingTasks(); len(pending) != 1 || pending[0].TaskId != "reduce" {
+		t.Errorf("expected reduce to be dispatchable, got %v", pending)
+	}
+
+	// A task depending on completed work starts PENDING
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "report", DependsOn: []string{"map-0"}})
+	assertStatus(t, fsm, "report", pb.TaskStatus_PENDING)
+}
+
+func TestFSM_Apply_DependencyFailureCascades(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntrySubmitJob, SubmitJobEntry{
+		JobID: "job-1",
+		Tasks: []AddTaskEntry{
+			{TaskID: "map", RetryPolicy: &pb.RetryPolicy{MaxAttempts: 1}},
+			{TaskID: "reduce", DependsOn: []string{"map"}, RetryPolicy: &pb.RetryPolicy{MaxAttempts: 3}},
+			{TaskID: "report", DependsOn: []string{"reduce"}},
+		},
+	})
+
+	// A failure that may still be retried leaves the downstream tasks waiting
+	runTask(t, fsm, 10, "map", LogEntryFailTask)
+	assertStatus(t, fsm, "reduce", pb.TaskStatus_BLOCKED)
+
+	applyLog(t, fsm, LogEntryDeadLetterTask, DeadLetterTaskEntry{TaskID: "map", Attempt: 1, Reason: "retries exhausted"})
+	assertStatus(t, fsm, "reduce", pb.TaskStatus_FAILED)
+	assertStatus(t, fsm, "report", pb.TaskStatus_FAILED)
+
+	reduce, _ := fsm.GetTask("reduce")
+	if reduce.LastFailure.GetErrorClass() != models.ErrorClassDependencyFailed {
+		t.Errorf("expected a %s failure, got %v", models.ErrorClassDependencyFailed, reduce.LastFailure)
+	}
+	if models.IsRetryable(reduce.RetryPolicy, reduce.LastFailure) {
+		t.Error("expected a dependency failure not to be retried")
+	}
+	if job, _ := fsm.GetJob("job-1"); job.Status != pb.JobStatus_JOB_FAILED || job.Progress.Failed != 3 {
+		t.Errorf("expected FAILED job with 3 failed tasks, got %s %v", job.Status, job.Progress)
+	}
+}
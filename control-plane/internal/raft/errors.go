@@ -0,0 +1,43 @@
+package raft
+
+import "errors"
+
+// Errors returned by the FSM through RaftCluster.Apply. Callers can match
+// them with errors.Is to react to rejected log entries.
+var (
+	// ErrUnknownTask is returned when a log entry references a task that does not exist
+	ErrUnknownTask = errors.New("unknown task")
+
+	// ErrUnknownJob is returned when a log entry references a job that does not exist
+	ErrUnknownJob = errors.New("unknown job")
+
+	// ErrAlreadyExists is returned when a log entry creates a job or task whose ID is taken
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrUnknownNode is returned when a log entry references a node that is not registered
+	ErrUnknownNode = errors.New("unknown node")
+
+	// ErrInvalidTransition is returned when a task cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid task status transition")
+
+	// ErrUnknownStatus is returned when a log entry carries an unrecognized task status
+	ErrUnknownStatus = errors.New("unknown task status")
+
+	// ErrInsufficientResources is returned when a node lacks the resources a task requests
+	ErrInsufficientResources = errors.New("insufficient node resources")
+
+	// ErrAffinityMismatch is returned when a node violates a task's required affinity
+	ErrAffinityMismatch = errors.New("node does not satisfy task affinity")
+
+	// ErrStaleNodeFailure is returned when a node heartbeated after being detected as failed
+	ErrStaleNodeFailure = errors.New("stale node failure")
+
+	// ErrStaleFencingToken is returned when an entry targets an earlier assignment of a task
+	ErrStaleFencingToken = errors.New("stale fencing token")
+
+	// ErrLeaseActive is returned when expiring a lease that was renewed in the meantime
+	ErrLeaseActive = errors.New("task lease still active")
+
+	// ErrPeerMismatch is returned when a peer reports a different ID than configured
+	ErrPeerMismatch = errors.New("peer id mismatch")
+)
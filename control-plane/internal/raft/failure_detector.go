@@ -0,0 +1,107 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// FailureDetectorStats summarizes failure detector activity so that the
+// time to recover from a node failure can be measured
+type FailureDetectorStats struct {
+	// NodesMarkedUnhealthy counts nodes declared dead by this leader
+	NodesMarkedUnhealthy uint64
+	// TasksReclaimed counts ASSIGNED/RUNNING tasks moved back to PENDING
+	TasksReclaimed uint64
+	// LeasesExpired counts tasks requeued because their lease lapsed
+	LeasesExpired uint64
+	// LastDetectionLatency is the time from a dead node's last heartbeat
+	// until it was marked unhealthy
+	LastDetectionLatency time.Duration
+	// LastDetectionAt is when the most recent node was marked unhealthy
+	LastDetectionAt time.Time
+}
+
+// RunFailureDetector periodically marks nodes that stopped heartbeating as
+// unhealthy and reclaims their tasks, then requeues tasks whose lease lapsed
+// on nodes that are still heartbeating. It only acts while this node is the
+// leader and returns when ctx is cancelled.
+func (rc *RaftCluster) RunFailureDetector(ctx context.Context) {
+	interval := rc.config.FailureCheckInterval
+	if interval <= 0 {
+		interval = defaultFailureCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if rc.IsLeader() {
+				rc.detectFailedNodes(now)
+				rc.expireLeases(now)
+			}
+		}
+	}
+}
+
+// GetFailureDetectorStats returns a copy of the failure detector statistics
+func (rc *RaftCluster) GetFailureDetectorStats() FailureDetectorStats {
+	rc.detectorMu.Lock()
+	defer rc.detectorMu.Unlock()
+	return rc.detectorStats
+}
+
+// detectFailedNodes commits a MarkNodeUnhealthy entry for every healthy node
+// whose last heartbeat is older than NodeTimeout
+func (rc *RaftCluster) detectFailedNodes(now time.Time) {
+	timeout := rc.config.NodeTimeout
+	if timeout <= 0 {
+		timeout = defaultNodeTimeout
+	}
+
+	for _, node := range rc.fsm.StaleNodes(now.Unix(), int64(timeout.Seconds())) {
+		entry := MarkNodeUnhealthyEntry{
+			NodeID:        node.NodeId,
+			LastHeartbeat: node.LastHeartbeat,
+			DetectedAt:    now.Unix(),
+		}
+
+		data, err := EncodeLogEntry(LogEntryMarkNodeUnhealthy, entry)
+		if err != nil {
+			log.Printf("Failed to encode node failure for %s: %v", node.NodeId, err)
+			continue
+		}
+
+		response, err := rc.ApplyWithResponse(data, leaderApplyTimeout)
+		if errors.Is(err, ErrStaleNodeFailure) {
+			continue // The node came back before the entry committed
+		}
+		if err != nil {
+			log.Printf("Failed to mark node %s unhealthy: %v", node.NodeId, err)
+			continue
+		}
+
+		reclaimed, _ := response.(int)
+		latency := now.Sub(time.Unix(node.LastHeartbeat, 0))
+		rc.recordNodeFailure(now, latency, reclaimed)
+
+		log.Printf("Node %s marked unhealthy %s after its last heartbeat, reclaimed %d tasks",
+			node.NodeId, latency, reclaimed)
+	}
+}
+
+// recordNodeFailure updates failure detector statistics
+func (rc *RaftCluster) recordNodeFailure(detectedAt time.Time, latency time.Duration, reclaimed int) {
+	rc.detectorMu.Lock()
+	defer rc.detectorMu.Unlock()
+
+	rc.detectorStats.NodesMarkedUnhealthy++
+	rc.detectorStats.TasksReclaimed += uint64(reclaimed)
+	rc.detectorStats.LastDetectionLatency = latency
+	rc.detectorStats.LastDetectionAt = detectedAt
+}
@@ -0,0 +1,118 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestFSM_Apply_MarkNodeUnhealthy_ReclaimsTasks(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "node-1", Timestamp: 100})
+	for _, taskID := range []string{"task-1", "task-2", "task-3"} {
+		applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	}
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-2", NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryCompleteTask, CompleteTaskEntry{TaskID: "task-2", FencingToken: 2})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-3", NodeID: "node-1"})
+
+	applyLog(t, fsm, LogEntryMarkNodeUnhealthy, MarkNodeUnhealthyEntry{NodeID: "node-1", LastHeartbeat: 100, DetectedAt: 200})
+
+	node, _ := fsm.GetNode("node-1")
+	if node.Status != pb.NodeStatus_UNHEALTHY {
+		t.Errorf("expected node UNHEALTHY, got %s", node.Status)
+	}
+	if node.ActiveTasks != 0 {
+		t.Errorf("expected no active tasks on dead node, got %d", node.ActiveTasks)
+	}
+
+	for taskID, want := range map[string]pb.TaskStatus{
+		"task-1": pb.TaskStatus_PENDING,
+		"task-2": pb.TaskStatus_COMPLETED,
+		"task-3": pb.TaskStatus_PENDING,
+	} {
+		task, _ := fsm.GetTask(taskID)
+		if task.Status != want {
+			t.Errorf("%s: expected status %s, got %s", taskID, want, task.Status)
+		}
+		if want == pb.TaskStatus_PENDING && task.AssignedNodeId != "" {
+			t.Errorf("%s: expected reclaimed task to be unassigned, got %q", taskID, task.AssignedNodeId)
+		}
+	}
+}
+
+func TestFSM_Apply_MarkNodeUnhealthy_RejectsStaleDetection(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "node-1", Timestamp: 150})
+
+	// The leader saw the heartbeat at 100, but a newer one committed first
+	err := applyLogErr(t, fsm, LogEntryMarkNodeUnhealthy, MarkNodeUnhealthyEntry{NodeID: "node-1", LastHeartbeat: 100})
+	if !errors.Is(err, ErrStaleNodeFailure) {
+		t.Fatalf("expected ErrStaleNodeFailure, got %v", err)
+	}
+
+	node, _ := fsm.GetNode("node-1")
+	if node.Status != pb.NodeStatus_HEALTHY {
+		t.Errorf("expected node to stay HEALTHY, got %s", node.Status)
+	}
+}
+
+func TestDetectFailedNodes_MarksStaleNodes(t *testing.T) {
+	cluster := setupCluster(t, 1, nil)
+	cluster.config.NodeTimeout = 30 * time.Second
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	apply := func(entryType LogEntryType, entry interface{}) {
+		t.Helper()
+		data, err := EncodeLogEntry(entryType, entry)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+
+	now := time.Now()
+	apply(LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "node-dead", Timestamp: now.Add(-time.Minute).Unix()})
+	apply(LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "node-alive", Timestamp: now.Unix()})
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	apply(LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-dead"})
+
+	cluster.detectFailedNodes(now)
+
+	if node, _ := cluster.GetFSM().GetNode("node-dead"); node.Status != pb.NodeStatus_UNHEALTHY {
+		t.Errorf("expected node-dead UNHEALTHY, got %s", node.Status)
+	}
+	if node, _ := cluster.GetFSM().GetNode("node-alive"); node.Status != pb.NodeStatus_HEALTHY {
+		t.Errorf("expected node-alive HEALTHY, got %s", node.Status)
+	}
+	if task, _ := cluster.GetFSM().GetTask("task-1"); task.Status != pb.TaskStatus_PENDING {
+		t.Errorf("expected orphaned task PENDING, got %s", task.Status)
+	}
+
+	stats := cluster.GetFailureDetectorStats()
+	if stats.NodesMarkedUnhealthy != 1 || stats.TasksReclaimed != 1 {
+		t.Errorf("expected 1 node and 1 task in stats, got %+v", stats)
+	}
+	if stats.LastDetectionLatency < time.Minute {
+		t.Errorf("expected detection latency of at least 1m, got %s", stats.LastDetectionLatency)
+	}
+
+	// A second scan finds nothing new
+	cluster.detectFailedNodes(now)
+	if stats := cluster.GetFailureDetectorStats(); stats.NodesMarkedUnhealthy != 1 {
+		t.Errorf("expected dead node to be reported once, got %d", stats.NodesMarkedUnhealthy)
+	}
+}
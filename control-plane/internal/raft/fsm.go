@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
+	"time"
 
 	"ml-raft-control-plane/internal/models"
 	pb "ml-raft-control-plane/pkg/proto"
 
 	"github.com/hashicorp/raft"
+	"google.golang.org/protobuf/proto"
 )
 
 // TaskManifestFSM implements the Raft FSM interface for Task Manifest
@@ -36,62 +39,372 @@ func (fsm *TaskManifestFSM) Apply(log *raft.Log) interface{} {
 	if err != nil {
 		return fmt.Errorf("failed to decode log entry: %w", err)
 	}
+	if entry.Type == LogEntryBatch {
+		return fsm.applyBatch(entry.Data, log)
+	}
+	return fsm.applyEntry(entry, log)
+}
+
+// applyBatch applies the commands of a batch in order and returns the
+// response of each, so every caller of the apply queue learns its own
+// result. Each command is applied as if appended when it was queued, and
+// assignments in the batch still get fencing tokens of their own.
+func (fsm *TaskManifestFSM) applyBatch(data []byte, log *raft.Log) interface{} {
+	var batch BatchEntry
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return fmt.Errorf("failed to unmarshal BatchEntry: %w", err)
+	}
+
+	responses := make([]interface{}, len(batch.Commands))
+	for i, command := range batch.Commands {
+		commandLog := *log
+		if i < len(batch.QueuedAt) {
+			commandLog.AppendedAt = time.Unix(0, batch.QueuedAt[i])
+		}
+
+		entry, err := DecodeLogEntry(command)
+		switch {
+		case err != nil:
+			responses[i] = fmt.Errorf("failed to decode log entry: %w", err)
+		case entry.Type == LogEntryBatch:
+			responses[i] = fmt.Errorf("batches cannot be nested")
+		default:
+			responses[i] = fsm.applyEntry(entry, &commandLog)
+		}
+	}
+	return responses
+}
 
-	// Apply based on entry type
+// applyEntry applies a single command based on its type
+func (fsm *TaskManifestFSM) applyEntry(entry *LogEntry, log *raft.Log) interface{} {
 	switch entry.Type {
 	case LogEntryAddTask:
-		return fsm.applyAddTask(entry.Data)
+		return fsm.applyAddTask(entry.Data, log)
 	case LogEntryAssignTask:
-		return fsm.applyAssignTask(entry.Data)
+		return fsm.applyAssignTask(entry.Data, log)
 	case LogEntryUpdateTaskStatus:
 		return fsm.applyUpdateTaskStatus(entry.Data)
 	case LogEntryCompleteTask:
-		return fsm.applyCompleteTask(entry.Data)
+		return fsm.applyCompleteTask(entry.Data, log)
 	case LogEntryFailTask:
-		return fsm.applyFailTask(entry.Data)
+		return fsm.applyFailTask(entry.Data, log)
 	case LogEntryNodeHeartbeat:
-		return fsm.applyNodeHeartbeat(entry.Data)
+		return fsm.applyNodeHeartbeat(entry.Data, log)
 	case LogEntryRegisterNode:
 		return fsm.applyRegisterNode(entry.Data)
+	case LogEntryRegisterServer:
+		return fsm.applyRegisterServer(entry.Data)
+	case LogEntryRetryTask:
+		return fsm.applyRetryTask(entry.Data)
+	case LogEntryDeadLetterTask:
+		return fsm.applyDeadLetterTask(entry.Data, log)
+	case LogEntryMarkNodeUnhealthy:
+		return fsm.applyMarkNodeUnhealthy(entry.Data, log)
+	case LogEntryExpireLease:
+		return fsm.applyExpireLease(entry.Data, log)
+	case LogEntryPlaceTask:
+		return fsm.applyPlaceTask(entry.Data, log)
+	case LogEntryPreemptTask:
+		return fsm.applyPreemptTask(entry.Data, log)
+	case LogEntrySubmitJob:
+		return fsm.applySubmitJob(entry.Data)
+	case LogEntryCancelJob:
+		return fsm.applyCancelJob(entry.Data, log)
+	case LogEntryCancelTask:
+		return fsm.applyCancelTask(entry.Data, log)
+	case LogEntryAcknowledgeCancel:
+		return fsm.applyAcknowledgeCancel(entry.Data, log)
+	case LogEntryReportProgress:
+		return fsm.applyReportProgress(entry.Data, log)
+	case LogEntryAddTasks:
+		return fsm.applyAddTasks(entry.Data)
+	case LogEntryPlaceTasks:
+		return fsm.applyPlaceTasks(entry.Data, log)
 	default:
 		return fmt.Errorf("unknown log entry type: %d", entry.Type)
 	}
 }
 
-// applyAddTask adds a new task to the manifest
-func (fsm *TaskManifestFSM) applyAddTask(data []byte) interface{} {
+// applyAddTask adds a new task to the manifest. A submission retried with
+// the same idempotency key is a no-op; the caller looks up the original task.
+func (fsm *TaskManifestFSM) applyAddTask(data []byte, log *raft.Log) interface{} {
 	var entry AddTaskEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
 		return fmt.Errorf("failed to unmarshal AddTaskEntry: %w", err)
 	}
 
-	task := &pb.Task{
-		TaskId:    entry.TaskID,
-		TaskType:  entry.TaskType,
-		Status:    pb.TaskStatus_PENDING,
-		TaskData:  entry.TaskData,
+	if entry.IdempotencyKey != "" {
+		if _, found := fsm.manifest.LookupIdempotencyKey(submitKey(entry.IdempotencyKey)); found {
+			return nil
+		}
+	}
+	if _, exists := fsm.manifest.GetTask(entry.TaskID); exists {
+		return fmt.Errorf("%w: task %s", ErrAlreadyExists, entry.TaskID)
+	}
+	if err := fsm.checkDependencies(entry, nil); err != nil {
+		return err
+	}
+
+	fsm.manifest.AddTask(newTask(entry))
+	if entry.IdempotencyKey != "" {
+		fsm.manifest.RecordIdempotencyKey(submitKey(entry.IdempotencyKey), entry.TaskID, logTimestamp(entry.CreatedAt, log))
+	}
+	return nil
+}
+
+// applyAddTasks adds a batch of tasks. Tasks may depend on others in the
+// batch; if any task cannot be added, none is.
+func (fsm *TaskManifestFSM) applyAddTasks(data []byte) interface{} {
+	var entry AddTasksEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal AddTasksEntry: %w", err)
+	}
+
+	seen := make(map[string]bool, len(entry.Tasks))
+	for _, taskEntry := range entry.Tasks {
+		if _, exists := fsm.manifest.GetTask(taskEntry.TaskID); exists || seen[taskEntry.TaskID] {
+			return fmt.Errorf("%w: task %s", ErrAlreadyExists, taskEntry.TaskID)
+		}
+		seen[taskEntry.TaskID] = true
+	}
+	for _, taskEntry := range entry.Tasks {
+		if err := fsm.checkDependencies(taskEntry, seen); err != nil {
+			return err
+		}
+	}
+
+	for _, taskEntry := range entry.Tasks {
+		fsm.manifest.AddTask(newTask(taskEntry))
+	}
+	return nil
+}
+
+// checkDependencies rejects a new task that depends on a task that neither
+// exists nor is created by the same entry
+func (fsm *TaskManifestFSM) checkDependencies(entry AddTaskEntry, siblings map[string]bool) error {
+	for _, dependency := range entry.DependsOn {
+		if _, exists := fsm.manifest.GetTask(dependency); !exists && !siblings[dependency] {
+			return fmt.Errorf("%w: task %s depends on %s", ErrUnknownTask, entry.TaskID, dependency)
+		}
+	}
+	return nil
+}
+
+// newTask builds the PENDING task described by an AddTaskEntry
+func newTask(entry AddTaskEntry) *pb.Task {
+	return &pb.Task{
+		TaskId:        entry.TaskID,
+		TaskType:      entry.TaskType,
+		Status:        pb.TaskStatus_PENDING,
+		TaskData:      entry.TaskData,
+		CreatedAt:     entry.CreatedAt,
+		RetryPolicy:   entry.RetryPolicy,
+		Resources:     entry.Resources,
+		Affinity:      entry.Affinity,
+		Priority:      entry.Priority,
+		PriorityClass: entry.PriorityClass,
+		DependsOn:     entry.DependsOn,
+	}
+}
+
+// applySubmitJob adds a job and its tasks. Every ID is checked before the
+// manifest changes, so either the whole job is added or nothing is.
+func (fsm *TaskManifestFSM) applySubmitJob(data []byte) interface{} {
+	var entry SubmitJobEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal SubmitJobEntry: %w", err)
+	}
+
+	if _, exists := fsm.manifest.GetJob(entry.JobID); exists {
+		return fmt.Errorf("%w: job %s", ErrAlreadyExists, entry.JobID)
+	}
+	tasks := make([]*pb.Task, len(entry.Tasks))
+	seen := make(map[string]bool, len(entry.Tasks))
+	for i, taskEntry := range entry.Tasks {
+		if _, exists := fsm.manifest.GetTask(taskEntry.TaskID); exists || seen[taskEntry.TaskID] {
+			return fmt.Errorf("%w: task %s", ErrAlreadyExists, taskEntry.TaskID)
+		}
+		seen[taskEntry.TaskID] = true
+		tasks[i] = newTask(taskEntry)
+	}
+	for _, taskEntry := range entry.Tasks {
+		if err := fsm.checkDependencies(taskEntry, seen); err != nil {
+			return err
+		}
+	}
+
+	fsm.manifest.AddJob(&pb.Job{
+		JobId:     entry.JobID,
+		Name:      entry.Name,
 		CreatedAt: entry.CreatedAt,
+	}, tasks)
+	return nil
+}
+
+// applyCancelJob cancels every unfinished task of a job. It returns the
+// number of cancelled tasks.
+func (fsm *TaskManifestFSM) applyCancelJob(data []byte, log *raft.Log) interface{} {
+	var entry CancelJobEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal CancelJobEntry: %w", err)
 	}
 
-	fsm.manifest.AddTask(task)
+	job, exists := fsm.manifest.GetJob(entry.JobID)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, entry.JobID)
+	}
+	summary := proto.Clone(job).(*pb.Job)
+	models.SummarizeJob(summary, fsm.manifest.Tasks)
+	if summary.Status == pb.JobStatus_JOB_SUCCEEDED || summary.Status == pb.JobStatus_JOB_CANCELLED {
+		return fmt.Errorf("%w: job %s is %s", ErrInvalidTransition, entry.JobID, summary.Status)
+	}
+
+	return len(fsm.manifest.CancelJob(entry.JobID, logTimestamp(entry.CancelledAt, log)))
+}
+
+// applyCancelTask cancels a task that has not finished. ASSIGNED and
+// RUNNING tasks move to CANCELLING until their agent acknowledges.
+func (fsm *TaskManifestFSM) applyCancelTask(data []byte, log *raft.Log) interface{} {
+	var entry CancelTaskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal CancelTaskEntry: %w", err)
+	}
+
+	task, exists := fsm.manifest.GetTask(entry.TaskID)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownTask, entry.TaskID)
+	}
+	if !models.IsCancellable(task) {
+		return fmt.Errorf("%w: task %s is %s and cannot be cancelled", ErrInvalidTransition, entry.TaskID, task.Status)
+	}
+
+	fsm.manifest.CancelTask(entry.TaskID, logTimestamp(entry.CancelledAt, log))
 	return nil
 }
 
+// applyAcknowledgeCancel moves a CANCELLING task to CANCELLED and releases
+// the node's slot
+func (fsm *TaskManifestFSM) applyAcknowledgeCancel(data []byte, log *raft.Log) interface{} {
+	var entry AcknowledgeCancelEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal AcknowledgeCancelEntry: %w", err)
+	}
+	if duplicate, err := fsm.duplicateResult(entry.TaskID, entry.IdempotencyKey); duplicate {
+		return err
+	}
+
+	task, exists := fsm.manifest.GetTask(entry.TaskID)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownTask, entry.TaskID)
+	}
+	cancelledAt := logTimestamp(entry.CancelledAt, log)
+	if task.Status != pb.TaskStatus_CANCELLING {
+		return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, cancelledAt,
+			fmt.Errorf("%w: task %s is %s, only CANCELLING tasks can be acknowledged",
+				ErrInvalidTransition, entry.TaskID, task.Status))
+	}
+	if err := checkFencingToken(task, entry.FencingToken); err != nil {
+		return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, cancelledAt, err)
+	}
+
+	fsm.manifest.AcknowledgeCancel(entry.TaskID, cancelledAt)
+	return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, cancelledAt, nil)
+}
+
 // applyAssignTask assigns a task to a node
-func (fsm *TaskManifestFSM) applyAssignTask(data []byte) interface{} {
+func (fsm *TaskManifestFSM) applyAssignTask(data []byte, log *raft.Log) interface{} {
 	var entry AssignTaskEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
 		return fmt.Errorf("failed to unmarshal AssignTaskEntry: %w", err)
 	}
 
-	if !fsm.manifest.AssignTask(entry.TaskID, entry.NodeID) {
-		return fmt.Errorf("failed to assign task %s to node %s", entry.TaskID, entry.NodeID)
+	// Only PENDING tasks can be assigned, so a task is never handed to two nodes
+	task, err := fsm.checkTransition(entry.TaskID, pb.TaskStatus_ASSIGNED)
+	if err != nil {
+		return err
+	}
+	node, exists := fsm.manifest.Nodes[entry.NodeID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, entry.NodeID)
+	}
+	if !models.Fits(node, task.Resources) {
+		return fmt.Errorf("%w: node %s cannot fit task %s", ErrInsufficientResources, entry.NodeID, entry.TaskID)
+	}
+	if mismatch := models.AffinityMismatch(node, task.Affinity); mismatch != "" {
+		return fmt.Errorf("%w: node %s %s", ErrAffinityMismatch, entry.NodeID, mismatch)
+	}
+
+	fsm.manifest.AssignTask(entry.TaskID, entry.NodeID, logTimestamp(entry.AssignedAt, log))
+
+	// Tokens are unique and increasing, so each fences off earlier assignments
+	fsm.manifest.GrantLease(entry.TaskID, fsm.manifest.NextFencingToken(log.Index), entry.LeaseExpiresAt)
+	return nil
+}
+
+// applyPlaceTask records the scheduler's choice of node for a PENDING task
+func (fsm *TaskManifestFSM) applyPlaceTask(data []byte, log *raft.Log) interface{} {
+	var entry PlaceTaskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal PlaceTaskEntry: %w", err)
 	}
 
+	task, exists := fsm.manifest.GetTask(entry.TaskID)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownTask, entry.TaskID)
+	}
+	if task.Status != pb.TaskStatus_PENDING {
+		return fmt.Errorf("%w: task %s is %s, only PENDING tasks can be placed",
+			ErrInvalidTransition, entry.TaskID, task.Status)
+	}
+	if _, exists := fsm.manifest.Nodes[entry.NodeID]; !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, entry.NodeID)
+	}
+
+	fsm.manifest.PlaceTask(entry.TaskID, entry.NodeID, entry.Scheduler, logTimestamp(entry.PlacedAt, log))
 	return nil
 }
 
-// applyUpdateTaskStatus updates task status
+// applyPlaceTasks applies a placement round. The dropped placements and
+// preemptions go first so the placements that rely on them find the room
+// free. Decisions made stale by entries committed since the round was
+// planned are skipped. It returns the number of placed tasks.
+func (fsm *TaskManifestFSM) applyPlaceTasks(data []byte, log *raft.Log) interface{} {
+	var entry PlaceTasksEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal PlaceTasksEntry: %w", err)
+	}
+
+	for _, taskID := range entry.Unplacements {
+		fsm.manifest.UnplaceTask(taskID)
+	}
+
+	for _, preemption := range entry.Preemptions {
+		task, exists := fsm.manifest.GetTask(preemption.TaskID)
+		if !exists || !models.IsLeased(task) || task.Status == pb.TaskStatus_CANCELLING ||
+			task.FencingToken != preemption.FencingToken {
+			continue
+		}
+		fsm.manifest.PreemptTask(preemption.TaskID, preemption.PreemptorID, logTimestamp(preemption.PreemptedAt, log))
+	}
+
+	placed := 0
+	for _, placement := range entry.Placements {
+		task, exists := fsm.manifest.GetTask(placement.TaskID)
+		if !exists || task.Status != pb.TaskStatus_PENDING {
+			continue
+		}
+		if _, exists := fsm.manifest.Nodes[placement.NodeID]; !exists {
+			continue
+		}
+		fsm.manifest.PlaceTask(placement.TaskID, placement.NodeID, placement.Scheduler, logTimestamp(placement.PlacedAt, log))
+		placed++
+	}
+	return placed
+}
+
+// applyUpdateTaskStatus marks an ASSIGNED task RUNNING. Every other
+// transition has an entry of its own that also releases the node, the lease
+// and dependent tasks, so it is rejected here.
 func (fsm *TaskManifestFSM) applyUpdateTaskStatus(data []byte) interface{} {
 	var entry UpdateTaskStatusEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
@@ -99,42 +412,152 @@ func (fsm *TaskManifestFSM) applyUpdateTaskStatus(data []byte) interface{} {
 	}
 
 	// Convert string status to protobuf enum
-	status := stringToTaskStatus(entry.Status)
+	status, err := stringToTaskStatus(entry.Status)
+	if err != nil {
+		return err
+	}
 
-	if !fsm.manifest.UpdateTaskStatus(entry.TaskID, status) {
-		return fmt.Errorf("failed to update task %s status", entry.TaskID)
+	task, err := fsm.checkTransition(entry.TaskID, status)
+	if err != nil {
+		return err
+	}
+	if task.Status != pb.TaskStatus_ASSIGNED || status != pb.TaskStatus_RUNNING {
+		return fmt.Errorf("%w: task %s cannot move from %s to %s by a status update, only from ASSIGNED to RUNNING",
+			ErrInvalidTransition, entry.TaskID, task.Status, status)
 	}
 
+	fsm.manifest.UpdateTaskStatus(entry.TaskID, status)
 	return nil
 }
 
 // applyCompleteTask marks a task as completed
-func (fsm *TaskManifestFSM) applyCompleteTask(data []byte) interface{} {
+func (fsm *TaskManifestFSM) applyCompleteTask(data []byte, log *raft.Log) interface{} {
 	var entry CompleteTaskEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
 		return fmt.Errorf("failed to unmarshal CompleteTaskEntry: %w", err)
 	}
+	if duplicate, err := fsm.duplicateResult(entry.TaskID, entry.IdempotencyKey); duplicate {
+		return err
+	}
 
-	if !fsm.manifest.CompleteTask(entry.TaskID, string(entry.ResultData)) {
-		return fmt.Errorf("failed to complete task %s", entry.TaskID)
+	completedAt := logTimestamp(entry.CompletedAt, log)
+	task, err := fsm.checkTransition(entry.TaskID, pb.TaskStatus_COMPLETED)
+	if err == nil {
+		err = checkFencingToken(task, entry.FencingToken)
+	}
+	if err != nil {
+		return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, completedAt, err)
 	}
 
-	return nil
+	fsm.manifest.CompleteTask(entry.TaskID, string(entry.ResultData), completedAt)
+	return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, completedAt, nil)
 }
 
-// applyFailTask marks a task as failed
-func (fsm *TaskManifestFSM) applyFailTask(data []byte) interface{} {
+// applyFailTask marks a task as failed, records the failure and releases the node's slot
+func (fsm *TaskManifestFSM) applyFailTask(data []byte, log *raft.Log) interface{} {
 	var entry FailTaskEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
 		return fmt.Errorf("failed to unmarshal FailTaskEntry: %w", err)
 	}
+	if duplicate, err := fsm.duplicateResult(entry.TaskID, entry.IdempotencyKey); duplicate {
+		return err
+	}
+
+	failedAt := logTimestamp(entry.FailedAt, log)
+	task, err := fsm.checkTransition(entry.TaskID, pb.TaskStatus_FAILED)
+	if err == nil {
+		err = checkFencingToken(task, entry.FencingToken)
+	}
+	if err != nil {
+		return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, failedAt, err)
+	}
+
+	fsm.manifest.FailTask(entry.TaskID, entry.ErrorMessage, entry.ErrorClass, failedAt)
+	return fsm.recordResult(entry.TaskID, entry.IdempotencyKey, failedAt, nil)
+}
+
+// applyReportProgress records the progress of each task in a batch, moving
+// ASSIGNED tasks to RUNNING. Updates for tasks that finished or were
+// reassigned since they were reported are skipped. It returns the number of
+// recorded updates.
+func (fsm *TaskManifestFSM) applyReportProgress(data []byte, log *raft.Log) interface{} {
+	var entry ReportProgressEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal ReportProgressEntry: %w", err)
+	}
+
+	recorded := 0
+	for _, update := range entry.Updates {
+		task, exists := fsm.manifest.GetTask(update.TaskID)
+		if !exists || !models.IsLeased(task) || task.FencingToken != update.FencingToken {
+			continue
+		}
+		fsm.manifest.RecordProgress(update.TaskID, &pb.TaskProgress{
+			PercentComplete: update.PercentComplete,
+			Stage:           update.Stage,
+			Metrics:         update.Metrics,
+			ReportedAt:      logTimestamp(update.ReportedAt, log),
+		})
+		recorded++
+	}
+	return recorded
+}
+
+// applyRetryTask moves a FAILED task back to PENDING for another attempt
+func (fsm *TaskManifestFSM) applyRetryTask(data []byte) interface{} {
+	var entry RetryTaskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal RetryTaskEntry: %w", err)
+	}
+
+	task, err := fsm.checkTransition(entry.TaskID, pb.TaskStatus_PENDING)
+	if err != nil {
+		return err
+	}
+	if task.Status != pb.TaskStatus_FAILED || task.Attempt != entry.Attempt {
+		return fmt.Errorf("%w: retry of task %s attempt %d is stale (task is %s on attempt %d)",
+			ErrInvalidTransition, entry.TaskID, entry.Attempt, task.Status, task.Attempt)
+	}
 
-	fsm.manifest.UpdateTaskStatus(entry.TaskID, pb.TaskStatus_FAILED)
+	fsm.manifest.RequeueTask(entry.TaskID)
 	return nil
 }
 
+// applyDeadLetterTask moves a FAILED task to DEAD_LETTER
+func (fsm *TaskManifestFSM) applyDeadLetterTask(data []byte, log *raft.Log) interface{} {
+	var entry DeadLetterTaskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal DeadLetterTaskEntry: %w", err)
+	}
+
+	task, err := fsm.checkTransition(entry.TaskID, pb.TaskStatus_DEAD_LETTER)
+	if err != nil {
+		return err
+	}
+	if task.Attempt != entry.Attempt {
+		return fmt.Errorf("%w: dead-letter of task %s attempt %d is stale (task is on attempt %d)",
+			ErrInvalidTransition, entry.TaskID, entry.Attempt, task.Attempt)
+	}
+
+	fsm.manifest.DeadLetterTask(entry.TaskID, entry.Reason, logTimestamp(entry.DeadLetteredAt, log))
+	return nil
+}
+
+// checkTransition returns the task if it exists and may move to the given status
+func (fsm *TaskManifestFSM) checkTransition(taskID string, to pb.TaskStatus) (*pb.Task, error) {
+	task, exists := fsm.manifest.GetTask(taskID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTask, taskID)
+	}
+	if !models.CanTransition(task.Status, to) {
+		return nil, fmt.Errorf("%w: task %s cannot move from %s to %s",
+			ErrInvalidTransition, taskID, task.Status, to)
+	}
+	return task, nil
+}
+
 // applyNodeHeartbeat updates node heartbeat
-func (fsm *TaskManifestFSM) applyNodeHeartbeat(data []byte) interface{} {
+func (fsm *TaskManifestFSM) applyNodeHeartbeat(data []byte, log *raft.Log) interface{} {
 	var entry NodeHeartbeatEntry
 	if err := json.Unmarshal(data, &entry); err != nil {
 		return fmt.Errorf("failed to unmarshal NodeHeartbeatEntry: %w", err)
@@ -145,8 +568,113 @@ func (fsm *TaskManifestFSM) applyNodeHeartbeat(data []byte) interface{} {
 		entry.CPUUsage,
 		entry.MemoryUsage,
 		entry.ActiveTasks,
+		logTimestamp(entry.Timestamp, log),
 	)
+	if entry.Capacity != nil {
+		fsm.manifest.SetNodeResources(entry.NodeID, entry.Capacity, entry.Allocatable)
+	}
+	fsm.manifest.SetNodeTopology(entry.NodeID, entry.CloudProvider, entry.Region, entry.Labels)
+
+	// Leases the node no longer holds are skipped; the caller reports them as revoked
+	for _, lease := range entry.Leases {
+		fsm.manifest.RenewLease(lease.TaskId, entry.NodeID, lease.FencingToken, entry.LeaseExpiresAt)
+	}
+
+	return nil
+}
+
+// applyMarkNodeUnhealthy marks a node unhealthy, moves its ASSIGNED and
+// RUNNING tasks back to PENDING and its CANCELLING tasks to CANCELLED. It
+// returns the number of reclaimed tasks.
+func (fsm *TaskManifestFSM) applyMarkNodeUnhealthy(data []byte, log *raft.Log) interface{} {
+	var entry MarkNodeUnhealthyEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal MarkNodeUnhealthyEntry: %w", err)
+	}
+
+	node, exists := fsm.manifest.Nodes[entry.NodeID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownNode, entry.NodeID)
+	}
+
+	// The node heartbeated after the leader decided it was dead
+	if node.LastHeartbeat > entry.LastHeartbeat {
+		return fmt.Errorf("%w: node %s heartbeated at %d after detection at %d",
+			ErrStaleNodeFailure, entry.NodeID, node.LastHeartbeat, entry.LastHeartbeat)
+	}
+
+	fsm.manifest.MarkNodeUnhealthy(entry.NodeID)
+	reclaimed := fsm.manifest.ReclaimNodeTasks(entry.NodeID, logTimestamp(entry.DetectedAt, log))
+	fsm.manifest.ClearNodePlacements(entry.NodeID)
+	node.ActiveTasks = 0
+
+	return len(reclaimed)
+}
+
+// applyExpireLease moves a task whose lease lapsed back to PENDING, or to
+// CANCELLED if it was CANCELLING
+func (fsm *TaskManifestFSM) applyExpireLease(data []byte, log *raft.Log) interface{} {
+	var entry ExpireLeaseEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal ExpireLeaseEntry: %w", err)
+	}
+
+	to := pb.TaskStatus_PENDING
+	if task, exists := fsm.manifest.GetTask(entry.TaskID); exists && task.Status == pb.TaskStatus_CANCELLING {
+		to = pb.TaskStatus_CANCELLED
+	}
+	task, err := fsm.checkTransition(entry.TaskID, to)
+	if err != nil {
+		return err
+	}
+	if err := checkFencingToken(task, entry.FencingToken); err != nil {
+		return err
+	}
+
+	// The holder renewed the lease after the leader decided it had lapsed
+	expiredAt := logTimestamp(entry.ExpiredAt, log)
+	if task.LeaseExpiresAt == 0 || task.LeaseExpiresAt > expiredAt {
+		return fmt.Errorf("%w: task %s lease runs until %d", ErrLeaseActive, entry.TaskID, task.LeaseExpiresAt)
+	}
+
+	if to == pb.TaskStatus_CANCELLED {
+		fsm.manifest.AcknowledgeCancel(entry.TaskID, expiredAt)
+		return nil
+	}
+	fsm.manifest.RequeueTask(entry.TaskID)
+	return nil
+}
+
+// applyPreemptTask moves an ASSIGNED or RUNNING task back to PENDING so a
+// higher-priority task can use its resources
+func (fsm *TaskManifestFSM) applyPreemptTask(data []byte, log *raft.Log) interface{} {
+	var entry PreemptTaskEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal PreemptTaskEntry: %w", err)
+	}
+
+	task, err := fsm.checkTransition(entry.TaskID, pb.TaskStatus_PENDING)
+	if err != nil {
+		return err
+	}
+	if !models.IsLeased(task) {
+		return fmt.Errorf("%w: task %s is %s, only ASSIGNED or RUNNING tasks can be preempted",
+			ErrInvalidTransition, entry.TaskID, task.Status)
+	}
+	if err := checkFencingToken(task, entry.FencingToken); err != nil {
+		return err
+	}
+
+	fsm.manifest.PreemptTask(entry.TaskID, entry.PreemptorID, logTimestamp(entry.PreemptedAt, log))
+	return nil
+}
 
+// checkFencingToken rejects entries issued for an earlier assignment of a task
+func checkFencingToken(task *pb.Task, fencingToken uint64) error {
+	if task.FencingToken != fencingToken {
+		return fmt.Errorf("%w: task %s is held under token %d, got %d",
+			ErrStaleFencingToken, task.TaskId, task.FencingToken, fencingToken)
+	}
 	return nil
 }
 
@@ -167,6 +695,28 @@ func (fsm *TaskManifestFSM) applyRegisterNode(data []byte) interface{} {
 	}
 
 	fsm.manifest.Nodes[entry.NodeID] = node
+	fsm.manifest.SetNodeTopology(entry.NodeID, "", "", entry.Labels)
+	if entry.Capacity != nil {
+		fsm.manifest.SetNodeResources(entry.NodeID, entry.Capacity, entry.Allocatable)
+	}
+	return nil
+}
+
+// applyRegisterServer records control-plane server metadata
+func (fsm *TaskManifestFSM) applyRegisterServer(data []byte) interface{} {
+	var entry RegisterServerEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal RegisterServerEntry: %w", err)
+	}
+
+	fsm.manifest.RegisterServer(&pb.ServerInfo{
+		ServerId:      entry.ServerID,
+		RaftAddress:   entry.RaftAddress,
+		GrpcAddress:   entry.GRPCAddress,
+		CloudProvider: entry.CloudProvider,
+		Region:        entry.Region,
+		RegisteredAt:  entry.RegisteredAt,
+	})
 	return nil
 }
 
@@ -200,7 +750,24 @@ func (fsm *TaskManifestFSM) Restore(rc io.ReadCloser) error {
 
 	// Restore manifest state
 	fsm.manifest.Tasks = snapshot.Tasks
+	if fsm.manifest.Tasks == nil {
+		fsm.manifest.Tasks = make(map[string]*pb.Task)
+	}
+	fsm.manifest.ReindexTasks()
 	fsm.manifest.Nodes = snapshot.Nodes
+	fsm.manifest.Servers = snapshot.Servers
+	if fsm.manifest.Servers == nil {
+		// Snapshots taken before server metadata existed
+		fsm.manifest.Servers = make(map[string]*pb.ServerInfo)
+	}
+	fsm.manifest.Jobs = snapshot.Jobs
+	if fsm.manifest.Jobs == nil {
+		// Snapshots taken before jobs existed
+		fsm.manifest.Jobs = make(map[string]*pb.Job)
+	}
+	fsm.manifest.Idempotency = snapshot.Idempotency
+	fsm.manifest.ReindexIdempotency()
+	fsm.manifest.LastFencingToken = snapshot.LastFencingToken
 
 	return nil
 }
@@ -212,61 +779,257 @@ func (fsm *TaskManifestFSM) GetManifest() *models.TaskManifest {
 	return fsm.manifest
 }
 
+// GetTask returns a copy of a task that is safe to use outside the FSM lock
+func (fsm *TaskManifestFSM) GetTask(taskID string) (*pb.Task, bool) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	task, exists := fsm.manifest.GetTask(taskID)
+	if !exists {
+		return nil, false
+	}
+	return proto.Clone(task).(*pb.Task), true
+}
+
+// GetNode returns a copy of a node that is safe to use outside the FSM lock
+func (fsm *TaskManifestFSM) GetNode(nodeID string) (*pb.Node, bool) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	node, exists := fsm.manifest.Nodes[nodeID]
+	if !exists {
+		return nil, false
+	}
+	return proto.Clone(node).(*pb.Node), true
+}
+
+// StaleNodes returns copies of healthy nodes whose last heartbeat is more
+// than timeoutSeconds before now
+func (fsm *TaskManifestFSM) StaleNodes(now, timeoutSeconds int64) []*pb.Node {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	nodeIDs := fsm.manifest.CheckStaleNodes(now, timeoutSeconds)
+	sort.Strings(nodeIDs)
+
+	stale := make([]*pb.Node, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		stale = append(stale, proto.Clone(fsm.manifest.Nodes[nodeID]).(*pb.Node))
+	}
+	return stale
+}
+
+// ExpiredLeases returns copies of ASSIGNED, RUNNING or CANCELLING tasks
+// whose lease lapsed at or before now
+func (fsm *TaskManifestFSM) ExpiredLeases(now int64) []*pb.Task {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	expired := fsm.manifest.GetExpiredLeases(now)
+	sort.Slice(expired, func(i, j int) bool { return expired[i].TaskId < expired[j].TaskId })
+
+	copies := make([]*pb.Task, len(expired))
+	for i, task := range expired {
+		copies[i] = proto.Clone(task).(*pb.Task)
+	}
+	return copies
+}
+
+// leasedTasks returns the ASSIGNED, RUNNING and CANCELLING tasks ordered by
+// task ID. The caller must hold the read lock and must not modify them.
+func (fsm *TaskManifestFSM) leasedTasks() []*pb.Task {
+	var leased []*pb.Task
+	for _, task := range fsm.manifest.Tasks {
+		if models.IsLeased(task) {
+			leased = append(leased, task)
+		}
+	}
+	sort.Slice(leased, func(i, j int) bool { return leased[i].TaskId < leased[j].TaskId })
+	return leased
+}
+
+// CancellingTasks returns the IDs of the CANCELLING tasks assigned to a node,
+// ordered by task ID. It reads the node's entry of the CANCELLING index, so
+// it costs the same however many tasks the manifest holds.
+func (fsm *TaskManifestFSM) CancellingTasks(nodeID string) []string {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+	return fsm.manifest.CancellingTasks(nodeID)
+}
+
+// GetJob returns a copy of a job with its status and progress filled in
+func (fsm *TaskManifestFSM) GetJob(jobID string) (*pb.Job, bool) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	job, exists := fsm.manifest.GetJob(jobID)
+	if !exists {
+		return nil, false
+	}
+	summary := proto.Clone(job).(*pb.Job)
+	models.SummarizeJob(summary, fsm.manifest.Tasks)
+	return summary, true
+}
+
+// ListJobs returns copies of jobs with their status and progress filled in,
+// ordered by creation time and optionally filtered by status. A limit of 0
+// or less returns all matching jobs.
+func (fsm *TaskManifestFSM) ListJobs(statusFilter *pb.JobStatus, limit int) []*pb.Job {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	jobs := make([]*pb.Job, 0, len(fsm.manifest.Jobs))
+	for _, job := range fsm.manifest.Jobs {
+		summary := proto.Clone(job).(*pb.Job)
+		models.SummarizeJob(summary, fsm.manifest.Tasks)
+		if statusFilter == nil || summary.Status == *statusFilter {
+			jobs = append(jobs, summary)
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt != jobs[j].CreatedAt {
+			return jobs[i].CreatedAt < jobs[j].CreatedAt
+		}
+		return jobs[i].JobId < jobs[j].JobId
+	})
+
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs
+}
+
+// GetServer returns a copy of control-plane server metadata
+func (fsm *TaskManifestFSM) GetServer(serverID string) (*pb.ServerInfo, bool) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	server, exists := fsm.manifest.GetServer(serverID)
+	if !exists {
+		return nil, false
+	}
+	return proto.Clone(server).(*pb.ServerInfo), true
+}
+
+// ListTasks returns copies of tasks ordered by creation time, optionally
+// filtered by status. A limit of 0 or less returns all matching tasks.
+func (fsm *TaskManifestFSM) ListTasks(statusFilter *pb.TaskStatus, limit int) []*pb.Task {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	var tasks []*pb.Task
+	if statusFilter != nil {
+		tasks = fsm.manifest.GetTasksByStatus(*statusFilter)
+	} else {
+		tasks = fsm.manifest.GetAllTasks()
+	}
+
+	// Map iteration order is random, so sort for stable pagination
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt != tasks[j].CreatedAt {
+			return tasks[i].CreatedAt < tasks[j].CreatedAt
+		}
+		return tasks[i].TaskId < tasks[j].TaskId
+	})
+
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	result := make([]*pb.Task, len(tasks))
+	for i, task := range tasks {
+		result[i] = proto.Clone(task).(*pb.Task)
+	}
+	return result
+}
+
+// PendingTasks returns copies of PENDING tasks in dispatch order: highest
+// priority first, then oldest first
+func (fsm *TaskManifestFSM) PendingTasks() []*pb.Task {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	pending := fsm.manifest.GetPendingTasks()
+	copies := make([]*pb.Task, len(pending))
+	for i, task := range pending {
+		copies[i] = proto.Clone(task).(*pb.Task)
+	}
+	return copies
+}
+
+// HealthyNodes returns copies of healthy nodes ordered by node ID
+func (fsm *TaskManifestFSM) HealthyNodes() []*pb.Node {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	return fsm.healthyNodes()
+}
+
+// healthyNodes returns copies of healthy nodes ordered by node ID. The
+// caller must hold the read lock.
+func (fsm *TaskManifestFSM) healthyNodes() []*pb.Node {
+	healthy := fsm.manifest.GetHealthyNodes()
+	sort.Slice(healthy, func(i, j int) bool { return healthy[i].NodeId < healthy[j].NodeId })
+
+	copies := make([]*pb.Node, len(healthy))
+	for i, node := range healthy {
+		copies[i] = proto.Clone(node).(*pb.Node)
+	}
+	return copies
+}
+
 // copyManifest creates a deep copy of the manifest
 func (fsm *TaskManifestFSM) copyManifest() *models.TaskManifest {
 	copy := models.NewTaskManifest()
 
 	// Deep copy tasks
 	for id, task := range fsm.manifest.Tasks {
-		taskCopy := &pb.Task{
-			TaskId:         task.TaskId,
-			TaskType:       task.TaskType,
-			Status:         task.Status,
-			AssignedNodeId: task.AssignedNodeId,
-			TaskData:       task.TaskData,
-			CreatedAt:      task.CreatedAt,
-			StartedAt:      task.StartedAt,
-			CompletedAt:    task.CompletedAt,
-			ResultData:     task.ResultData,
-		}
-		copy.Tasks[id] = taskCopy
+		copy.Tasks[id] = proto.Clone(task).(*pb.Task)
 	}
 
 	// Deep copy nodes
 	for id, node := range fsm.manifest.Nodes {
-		nodeCopy := &pb.Node{
-			NodeId:        node.NodeId,
-			Address:       node.Address,
-			CloudProvider: node.CloudProvider,
-			Region:        node.Region,
-			Status:        node.Status,
-			LastHeartbeat: node.LastHeartbeat,
-			CpuUsage:      node.CpuUsage,
-			MemoryUsage:   node.MemoryUsage,
-			ActiveTasks:   node.ActiveTasks,
-		}
-		copy.Nodes[id] = nodeCopy
+		copy.Nodes[id] = proto.Clone(node).(*pb.Node)
+	}
+
+	// Deep copy servers
+	for id, server := range fsm.manifest.Servers {
+		copy.Servers[id] = proto.Clone(server).(*pb.ServerInfo)
+	}
+
+	// Deep copy jobs
+	for id, job := range fsm.manifest.Jobs {
+		copy.Jobs[id] = proto.Clone(job).(*pb.Job)
 	}
 
+	// Copy idempotency records, which are never modified once recorded
+	copy.Idempotency = append([]*models.IdempotencyRecord(nil), fsm.manifest.Idempotency...)
+	copy.LastFencingToken = fsm.manifest.LastFencingToken
+
 	return copy
 }
 
-// Helper: convert string to TaskStatus enum
-func stringToTaskStatus(status string) pb.TaskStatus {
-	switch status {
-	case "PENDING":
-		return pb.TaskStatus_PENDING
-	case "ASSIGNED":
-		return pb.TaskStatus_ASSIGNED
-	case "RUNNING":
-		return pb.TaskStatus_RUNNING
-	case "COMPLETED":
-		return pb.TaskStatus_COMPLETED
-	case "FAILED":
-		return pb.TaskStatus_FAILED
-	default:
-		return pb.TaskStatus_PENDING
+// logTimestamp returns the timestamp carried by a log entry, falling back to
+// the time the leader appended the log. Apply must never read the wall clock,
+// otherwise replicas and log replays would disagree.
+func logTimestamp(entryTimestamp int64, log *raft.Log) int64 {
+	if entryTimestamp != 0 {
+		return entryTimestamp
 	}
+	if !log.AppendedAt.IsZero() {
+		return log.AppendedAt.Unix()
+	}
+	return 0
+}
+
+// Helper: convert string to TaskStatus enum, rejecting unknown names
+func stringToTaskStatus(status string) (pb.TaskStatus, error) {
+	value, ok := pb.TaskStatus_value[status]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownStatus, status)
+	}
+	return pb.TaskStatus(value), nil
 }
 
 // TaskManifestSnapshot implements raft.FSMSnapshot
@@ -278,8 +1041,13 @@ type TaskManifestSnapshot struct {
 func (s *TaskManifestSnapshot) Persist(sink raft.SnapshotSink) error {
 	// Encode snapshot as JSON
 	snapshot := TaskManifestSnapshotData{
-		Tasks: s.manifest.Tasks,
-		Nodes: s.manifest.Nodes,
+		Tasks:       s.manifest.Tasks,
+		Nodes:       s.manifest.Nodes,
+		Servers:     s.manifest.Servers,
+		Jobs:        s.manifest.Jobs,
+		Idempotency: s.manifest.Idempotency,
+
+		LastFencingToken: s.manifest.LastFencingToken,
 	}
 
 	encoder := json.NewEncoder(sink)
@@ -298,6 +1066,12 @@ func (s *TaskManifestSnapshot) Release() {
 
 // TaskManifestSnapshotData represents serialized snapshot data
 type TaskManifestSnapshotData struct {
-	Tasks map[string]*pb.Task `json:"tasks"`
-	Nodes map[string]*pb.Node `json:"nodes"`
+	Tasks       map[string]*pb.Task         `json:"tasks"`
+	Nodes       map[string]*pb.Node         `json:"nodes"`
+	Servers     map[string]*pb.ServerInfo   `json:"servers"`
+	Jobs        map[string]*pb.Job          `json:"jobs"`
+	Idempotency []*models.IdempotencyRecord `json:"idempotency,omitempty"`
+
+	// Absent in snapshots taken before batching; their tokens were all log indexes
+	LastFencingToken uint64 `json:"last_fencing_token,omitempty"`
 }
@@ -3,10 +3,13 @@ package raft
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"reflect"
 	"testing"
 	"time"
 
+	"ml-raft-control-plane/internal/models"
 	pb "ml-raft-control-plane/pkg/proto"
 
 	"github.com/google/uuid"
@@ -38,6 +41,18 @@ func applyLog(t *testing.T, fsm *TaskManifestFSM, entryType LogEntryType, data i
 	}
 }
 
+// helper to apply a log entry that is expected to be rejected by the FSM
+func applyLogErr(t *testing.T, fsm *TaskManifestFSM, entryType LogEntryType, data interface{}) error {
+	t.Helper()
+	encodedData, err := EncodeLogEntry(entryType, data)
+	if err != nil {
+		t.Fatalf("failed to encode log entry: %v", err)
+	}
+
+	err, _ = fsm.Apply(&raft.Log{Data: encodedData}).(error)
+	return err
+}
+
 func TestFSM_Apply_RegisterNode(t *testing.T) {
 	fsm := setupFSM(t)
 	nodeID := uuid.NewString()
@@ -119,6 +134,197 @@ func TestFSM_Apply_AssignTask(t *testing.T) {
 	}
 }
 
+func TestFSM_Apply_AssignTask_RejectsNonPending(t *testing.T) {
+	fsm := setupFSM(t)
+	taskID := uuid.NewString()
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-a"})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-b"})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: "node-a"})
+
+	err := applyLogErr(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: "node-b"})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition reassigning an ASSIGNED task, got %v", err)
+	}
+
+	if owner := fsm.manifest.Tasks[taskID].AssignedNodeId; owner != "node-a" {
+		t.Errorf("expected task to stay on node-a, got %s", owner)
+	}
+}
+
+func TestFSM_Apply_PlaceTask(t *testing.T) {
+	fsm := setupFSM(t)
+	taskID := uuid.NewString()
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-a"})
+
+	err := applyLogErr(t, fsm, LogEntryPlaceTask, PlaceTaskEntry{TaskID: taskID, NodeID: "node-x"})
+	if !errors.Is(err, ErrUnknownNode) {
+		t.Fatalf("expected ErrUnknownNode placing on an unregistered node, got %v", err)
+	}
+
+	applyLog(t, fsm, LogEntryPlaceTask, PlaceTaskEntry{TaskID: taskID, NodeID: "node-a", Scheduler: "spread", PlacedAt: 1700000000})
+	task, _ := fsm.GetTask(taskID)
+	if task.Status != pb.TaskStatus_PENDING || task.PlacedNodeId != "node-a" || task.PlacedBy != "spread" {
+		t.Errorf("expected PENDING task placed on node-a by spread, got %s on %q by %q",
+			task.Status, task.PlacedNodeId, task.PlacedBy)
+	}
+
+	// Losing the node drops its placements so the task can be placed elsewhere
+	applyLog(t, fsm, LogEntryMarkNodeUnhealthy, MarkNodeUnhealthyEntry{NodeID: "node-a"})
+	if task, _ := fsm.GetTask(taskID); task.PlacedNodeId != "" {
+		t.Errorf("expected placement cleared, got %q", task.PlacedNodeId)
+	}
+
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: "node-a"})
+	err = applyLogErr(t, fsm, LogEntryPlaceTask, PlaceTaskEntry{TaskID: taskID, NodeID: "node-a"})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition placing an ASSIGNED task, got %v", err)
+	}
+}
+
+func TestFSM_Apply_TracksResourceAllocations(t *testing.T) {
+	fsm := setupFSM(t)
+	request := &pb.Resources{CpuCores: 4, MemoryMb: 48 * 1024}
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "shard-1", Resources: request})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "shard-2", Resources: request})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{
+		NodeID:      "node-1",
+		Capacity:    &pb.Resources{CpuCores: 16, MemoryMb: 64 * 1024},
+		Allocatable: &pb.Resources{CpuCores: 14, MemoryMb: 60 * 1024},
+	})
+
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "shard-1", NodeID: "node-1"})
+	node, _ := fsm.GetNode("node-1")
+	if node.Allocated.GetCpuCores() != 4 || node.Allocated.GetMemoryMb() != 48*1024 {
+		t.Fatalf("expected 4 cores and 48 GB allocated, got %v", node.Allocated)
+	}
+
+	// The second shard no longer fits in the remaining 12 GB
+	err := applyLogErr(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "shard-2", NodeID: "node-1"})
+	if !errors.Is(err, ErrInsufficientResources) {
+		t.Fatalf("expected ErrInsufficientResources, got %v", err)
+	}
+
+	applyLog(t, fsm, LogEntryFailTask, FailTaskEntry{TaskID: "shard-1", FencingToken: 1})
+	node, _ = fsm.GetNode("node-1")
+	if node.Allocated.GetCpuCores() != 0 || node.Allocated.GetMemoryMb() != 0 {
+		t.Errorf("expected allocations released after failure, got %v", node.Allocated)
+	}
+
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "shard-2", NodeID: "node-1"})
+}
+
+func TestFSM_Apply_AssignTask_RequiresAffinity(t *testing.T) {
+	fsm := setupFSM(t)
+	affinity := &pb.Affinity{
+		RequiredAnti: []*pb.LabelExpression{{Key: "spot", Operator: pb.LabelOperator_EXISTS}},
+	}
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1", Affinity: affinity})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "spot-1", Labels: map[string]string{"spot": "true"}})
+	applyLog(t, fsm, LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "ondemand-1", CloudProvider: "aws"})
+
+	err := applyLogErr(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "spot-1"})
+	if !errors.Is(err, ErrAffinityMismatch) {
+		t.Fatalf("expected ErrAffinityMismatch, got %v", err)
+	}
+
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "ondemand-1"})
+	if node, _ := fsm.GetNode("ondemand-1"); node.CloudProvider != "aws" {
+		t.Errorf("expected heartbeat to record cloud aws, got %q", node.CloudProvider)
+	}
+}
+
+func TestFSM_Apply_LifecycleErrors(t *testing.T) {
+	fsm := setupFSM(t)
+	taskID := uuid.NewString()
+	nodeID := uuid.NewString()
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: nodeID})
+
+	tests := []struct {
+		name      string
+		entryType LogEntryType
+		data      interface{}
+		want      error
+	}{
+		{"assign unknown task", LogEntryAssignTask, AssignTaskEntry{TaskID: "missing", NodeID: nodeID}, ErrUnknownTask},
+		{"assign to unknown node", LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: "missing"}, ErrUnknownNode},
+		{"complete pending task", LogEntryCompleteTask, CompleteTaskEntry{TaskID: taskID}, ErrInvalidTransition},
+		{"fail pending task", LogEntryFailTask, FailTaskEntry{TaskID: taskID}, ErrInvalidTransition},
+		{"unknown status string", LogEntryUpdateTaskStatus, UpdateTaskStatusEntry{TaskID: taskID, Status: "COMPLETE"}, ErrUnknownStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := applyLogErr(t, fsm, tt.entryType, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if status := fsm.manifest.Tasks[taskID].Status; status != pb.TaskStatus_PENDING {
+		t.Errorf("rejected entries must not change the task, got %s", status)
+	}
+}
+
+func TestFSM_Apply_CompletedTaskIsTerminal(t *testing.T) {
+	fsm := setupFSM(t)
+	taskID := uuid.NewString()
+	nodeID := uuid.NewString()
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: nodeID})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: nodeID})
+	applyLog(t, fsm, LogEntryUpdateTaskStatus, UpdateTaskStatusEntry{TaskID: taskID, Status: "RUNNING"})
+	applyLog(t, fsm, LogEntryCompleteTask, CompleteTaskEntry{TaskID: taskID, FencingToken: 1})
+
+	for _, status := range []string{"PENDING", "ASSIGNED", "RUNNING", "FAILED"} {
+		err := applyLogErr(t, fsm, LogEntryUpdateTaskStatus, UpdateTaskStatusEntry{TaskID: taskID, Status: status})
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("expected COMPLETED -> %s to be rejected, got %v", status, err)
+		}
+	}
+}
+
+func TestFSM_Apply_UpdateTaskStatusOnlyStartsTasks(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "upstream"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "blocked", DependsOn: []string{"upstream"}})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "assigned"})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "assigned", NodeID: "node-1"})
+
+	// Requeueing, finishing and unblocking need their own entries
+	for _, tt := range []struct {
+		taskID string
+		status string
+	}{
+		{"blocked", "PENDING"},
+		{"assigned", "PENDING"},
+		{"assigned", "COMPLETED"},
+		{"assigned", "FAILED"},
+	} {
+		err := applyLogErr(t, fsm, LogEntryUpdateTaskStatus, UpdateTaskStatusEntry{TaskID: tt.taskID, Status: tt.status})
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("expected %s -> %s to be rejected, got %v", tt.taskID, tt.status, err)
+		}
+	}
+	assertStatus(t, fsm, "blocked", pb.TaskStatus_BLOCKED)
+	assertStatus(t, fsm, "assigned", pb.TaskStatus_ASSIGNED)
+
+	applyLog(t, fsm, LogEntryUpdateTaskStatus, UpdateTaskStatusEntry{TaskID: "assigned", Status: "RUNNING"})
+	assertStatus(t, fsm, "assigned", pb.TaskStatus_RUNNING)
+	if node, _ := fsm.GetNode("node-1"); node.ActiveTasks != 1 {
+		t.Errorf("expected the running task to keep its slot, got %d active tasks", node.ActiveTasks)
+	}
+}
+
 func TestFSM_Apply_CompleteTask(t *testing.T) {
 	fsm := setupFSM(t)
 	taskID := uuid.NewString()
@@ -132,8 +338,9 @@ func TestFSM_Apply_CompleteTask(t *testing.T) {
 	// Apply completion
 	resultData := json.RawMessage(`{"result": "success"}`)
 	completeEntry := CompleteTaskEntry{
-		TaskID:     taskID,
-		ResultData: resultData,
+		TaskID:       taskID,
+		ResultData:   resultData,
+		FencingToken: 1,
 	}
 	applyLog(t, fsm, LogEntryCompleteTask, completeEntry)
 
@@ -157,6 +364,52 @@ func TestFSM_Apply_CompleteTask(t *testing.T) {
 	}
 }
 
+func TestFSM_Apply_FailTask(t *testing.T) {
+	fsm := setupFSM(t)
+	taskID := uuid.NewString()
+	nodeID := uuid.NewString()
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: nodeID})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: taskID, NodeID: nodeID})
+
+	failEntry := FailTaskEntry{
+		TaskID:       taskID,
+		ErrorMessage: "CUDA out of memory",
+		FailedAt:     1700000042,
+		FencingToken: 1,
+	}
+	applyLog(t, fsm, LogEntryFailTask, failEntry)
+
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	task := fsm.manifest.Tasks[taskID]
+	if task.Status != pb.TaskStatus_FAILED {
+		t.Errorf("expected task status FAILED, got %s", task.Status)
+	}
+	if task.LastFailure == nil {
+		t.Fatal("expected failure to be recorded on the task")
+	}
+	if task.LastFailure.ErrorMessage != failEntry.ErrorMessage {
+		t.Errorf("expected error message %q, got %q", failEntry.ErrorMessage, task.LastFailure.ErrorMessage)
+	}
+	if task.LastFailure.FailedAt != failEntry.FailedAt {
+		t.Errorf("expected failed_at %d, got %d", failEntry.FailedAt, task.LastFailure.FailedAt)
+	}
+	if task.LastFailure.NodeId != nodeID {
+		t.Errorf("expected failing node %s, got %s", nodeID, task.LastFailure.NodeId)
+	}
+	if task.LastFailure.Attempt != 1 {
+		t.Errorf("expected attempt 1, got %d", task.LastFailure.Attempt)
+	}
+
+	node := fsm.manifest.Nodes[nodeID]
+	if node.ActiveTasks != 0 {
+		t.Errorf("expected node active tasks to be 0 after failure, got %d", node.ActiveTasks)
+	}
+}
+
 func TestFSM_Apply_NodeHeartbeat(t *testing.T) {
 	fsm := setupFSM(t)
 	nodeID := uuid.NewString()
@@ -184,14 +437,200 @@ func TestFSM_Apply_NodeHeartbeat(t *testing.T) {
 	if node.MemoryUsage != 66.6 {
 		t.Errorf("expected memory usage 66.6, got %f", node.MemoryUsage)
 	}
-	if node.ActiveTasks != 2 {
-		t.Errorf("expected active tasks 2, got %d", node.ActiveTasks)
+	if node.ReportedActiveTasks != 2 {
+		t.Errorf("expected reported active tasks 2, got %d", node.ReportedActiveTasks)
+	}
+	if node.ActiveTasks != 0 {
+		t.Errorf("expected the reported count not to replace the FSM's, got %d active tasks", node.ActiveTasks)
 	}
 	if node.LastHeartbeat < heartbeatEntry.Timestamp {
 		t.Error("heartbeat timestamp was not updated")
 	}
 }
 
+func TestFSM_Apply_RegisterServer(t *testing.T) {
+	fsm := setupFSM(t)
+	entry := RegisterServerEntry{
+		ServerID:      "aws-node-1",
+		RaftAddress:   "10.0.0.1:8080",
+		GRPCAddress:   "10.0.0.1:50051",
+		CloudProvider: "aws",
+		Region:        "us-east-1",
+		RegisteredAt:  time.Now().Unix(),
+	}
+
+	applyLog(t, fsm, LogEntryRegisterServer, entry)
+
+	server, ok := fsm.GetServer("aws-node-1")
+	if !ok {
+		t.Fatal("server aws-node-1 was not registered")
+	}
+	if server.GrpcAddress != entry.GRPCAddress || server.Region != entry.Region {
+		t.Errorf("unexpected server metadata: %v", server)
+	}
+}
+
+func TestFSM_PendingTasks_PriorityOrder(t *testing.T) {
+	fsm := setupFSM(t)
+
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "backfill-1", CreatedAt: 100})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "backfill-2", CreatedAt: 101})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "urgent", CreatedAt: 200, Priority: 1000})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "batch", CreatedAt: 50, Priority: -100})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "high", CreatedAt: 300, Priority: 100})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+
+	order := func(fsm *TaskManifestFSM) []string {
+		var ids []string
+		for _, task := range fsm.PendingTasks() {
+			ids = append(ids, task.TaskId)
+		}
+		return ids
+	}
+
+	want := []string{"urgent", "high", "backfill-1", "backfill-2", "batch"}
+	if got := order(fsm); !reflect.DeepEqual(got, want) {
+		t.Fatalf("PendingTasks() = %v, want %v", got, want)
+	}
+
+	// Assigned tasks leave the index and requeued ones return to their place
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "urgent", NodeID: "node-1", LeaseExpiresAt: 1}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "backfill-1", NodeID: "node-1"})
+	if got := order(fsm); !reflect.DeepEqual(got, []string{"high", "backfill-2", "batch"}) {
+		t.Fatalf("PendingTasks() after assignment = %v", got)
+	}
+	applyLog(t, fsm, LogEntryExpireLease, ExpireLeaseEntry{TaskID: "urgent", FencingToken: 10, ExpiredAt: 2})
+
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("fsm.Snapshot() returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Persist(&mockSnapshotSink{writer: &buf}); err != nil {
+		t.Fatalf("snapshot.Persist() returned error: %v", err)
+	}
+
+	restored := setupFSM(t)
+	if err := restored.Restore(io.NopCloser(&buf)); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+	want = []string{"urgent", "high", "backfill-2", "batch"}
+	if got := order(restored); !reflect.DeepEqual(got, want) {
+		t.Errorf("PendingTasks() after restore = %v, want %v", got, want)
+	}
+}
+
+func TestFSM_Apply_PreemptTask(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{
+		TaskID:      "batch",
+		Priority:    -100,
+		Resources:   &pb.Resources{CpuCores: 4},
+		RetryPolicy: &pb.RetryPolicy{MaxAttempts: 1},
+	})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1", Capacity: &pb.Resources{CpuCores: 4}})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "batch", NodeID: "node-1", LeaseExpiresAt: 100}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	err := applyAt(t, fsm, 11, LogEntryPreemptTask, PreemptTaskEntry{TaskID: "batch", FencingToken: 9, PreemptorID: "urgent"})
+	if !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+	if err := applyAt(t, fsm, 12, LogEntryPreemptTask, PreemptTaskEntry{TaskID: "batch", FencingToken: 10, PreemptorID: "urgent", PreemptedAt: 50}); err != nil {
+		t.Fatalf("preempt returned error: %v", err)
+	}
+
+	task, _ := fsm.GetTask("batch")
+	if task.Status != pb.TaskStatus_PENDING || task.AssignedNodeId != "" {
+		t.Errorf("expected task PENDING and unassigned, got %s on %q", task.Status, task.AssignedNodeId)
+	}
+	// The single allowed attempt is given back
+	if task.Attempt != 0 {
+		t.Errorf("expected attempt 0 after preemption, got %d", task.Attempt)
+	}
+	if task.LastPreemption.GetErrorClass() != models.ErrorClassPreempted || task.LastPreemption.GetNodeId() != "node-1" {
+		t.Errorf("expected PREEMPTED record on node-1, got %v", task.LastPreemption)
+	}
+	if task.LastFailure != nil {
+		t.Errorf("expected preemption to leave the last failure alone, got %v", task.LastFailure)
+	}
+	if !models.WasPreemptedFrom(task, "node-1") {
+		t.Error("expected the task to count as preempted from node-1")
+	}
+	node, _ := fsm.GetNode("node-1")
+	if node.ActiveTasks != 0 || node.Allocated.GetCpuCores() != 0 {
+		t.Errorf("expected node-1 released, got %d tasks and %v allocated", node.ActiveTasks, node.Allocated)
+	}
+
+	// Only held tasks can be preempted
+	err = applyAt(t, fsm, 13, LogEntryPreemptTask, PreemptTaskEntry{TaskID: "batch", FencingToken: 10})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition for a PENDING task, got %v", err)
+	}
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "reduce", DependsOn: []string{"batch"}})
+	err = applyAt(t, fsm, 14, LogEntryPreemptTask, PreemptTaskEntry{TaskID: "reduce"})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition for a BLOCKED task, got %v", err)
+	}
+	if blocked, _ := fsm.GetTask("reduce"); blocked.Status != pb.TaskStatus_BLOCKED {
+		t.Errorf("expected reduce to stay BLOCKED, got %s", blocked.Status)
+	}
+}
+
+func TestFSM_Apply_SubmitJob(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "existing"})
+
+	// A task ID that is already taken rejects the whole job
+	err := applyLogErr(t, fsm, LogEntrySubmitJob, SubmitJobEntry{
+		JobID: "job-1",
+		Tasks: []AddTaskEntry{{TaskID: "map-0"}, {TaskID: "existing"}},
+	})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+	if _, found := fsm.GetTask("map-0"); found {
+		t.Fatal("expected no task from the rejected job")
+	}
+
+	applyLog(t, fsm, LogEntrySubmitJob, SubmitJobEntry{
+		JobID:     "job-1",
+		Name:      "matmul",
+		CreatedAt: 100,
+		Tasks:     []AddTaskEntry{{TaskID: "map-0", CreatedAt: 100}, {TaskID: "map-1", CreatedAt: 100}},
+	})
+
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("fsm.Snapshot() returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Persist(&mockSnapshotSink{writer: &buf}); err != nil {
+		t.Fatalf("snapshot.Persist() returned error: %v", err)
+	}
+	restored := setupFSM(t)
+	if err := restored.Restore(io.NopCloser(&buf)); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+
+	job, found := restored.GetJob("job-1")
+	if !found {
+		t.Fatal("expected job-1 to survive a snapshot")
+	}
+	if !reflect.DeepEqual(job.TaskIds, []string{"map-0", "map-1"}) {
+		t.Errorf("expected tasks [map-0 map-1], got %v", job.TaskIds)
+	}
+	if job.Status != pb.JobStatus_JOB_PENDING || job.Progress.GetPending() != 2 {
+		t.Errorf("expected PENDING job with 2 pending tasks, got %s %v", job.Status, job.Progress)
+	}
+	if task, _ := restored.GetTask("map-1"); task.JobId != "job-1" {
+		t.Errorf("expected map-1 to belong to job-1, got %q", task.JobId)
+	}
+}
+
 func TestFSM_Snapshot_Restore(t *testing.T) {
 	fsm := setupFSM(t)
 
@@ -200,6 +639,7 @@ func TestFSM_Snapshot_Restore(t *testing.T) {
 	nodeID := uuid.NewString()
 	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: taskID, TaskType: "type1"})
 	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: nodeID, Address: "addr1"})
+	applyLog(t, fsm, LogEntryRegisterServer, RegisterServerEntry{ServerID: "cp-1", GRPCAddress: "10.0.0.1:50051"})
 
 	// 2. Create a snapshot
 	snapshot, err := fsm.Snapshot()
@@ -239,6 +679,10 @@ func TestFSM_Snapshot_Restore(t *testing.T) {
 	if _, ok := newFSM.manifest.Nodes[nodeID]; !ok {
 		t.Errorf("restored FSM missing node %s", nodeID)
 	}
+
+	if server, ok := newFSM.manifest.Servers["cp-1"]; !ok || server.GrpcAddress != "10.0.0.1:50051" {
+		t.Errorf("restored FSM missing server metadata for cp-1, got %v", server)
+	}
 }
 
 // mockSnapshotSink is a helper for testing snapshot persistence
@@ -264,3 +708,82 @@ func (m *mockSnapshotSink) Cancel() error {
 	m.is = true
 	return nil
 }
+
+func TestFSM_Replay_Deterministic(t *testing.T) {
+	appendedAt := time.Unix(1700000000, 0)
+	taskA, taskB := uuid.NewString(), uuid.NewString()
+	nodeID := uuid.NewString()
+
+	entries := []struct {
+		entryType LogEntryType
+		data      interface{}
+	}{
+		{LogEntryRegisterNode, RegisterNodeEntry{NodeID: nodeID, RegisteredAt: 1700000000}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: taskA, TaskType: "matmul", CreatedAt: 1700000001}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: taskB, TaskType: "matmul", CreatedAt: 1700000002}},
+		{LogEntryAssignTask, AssignTaskEntry{TaskID: taskA, NodeID: nodeID, AssignedAt: 1700000003}},
+		// No timestamp in the entry: AppendedAt must be used instead of the wall clock
+		{LogEntryAssignTask, AssignTaskEntry{TaskID: taskB, NodeID: nodeID}},
+		{LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: nodeID, CPUUsage: 10, ActiveTasks: 2}},
+		// taskA was assigned by the entry at log index 4
+		{LogEntryCompleteTask, CompleteTaskEntry{TaskID: taskA, CompletedAt: 1700000005, FencingToken: 4}},
+	}
+
+	var logs []*raft.Log
+	for i, e := range entries {
+		encoded, err := EncodeLogEntry(e.entryType, e.data)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		logs = append(logs, &raft.Log{
+			Index:      uint64(i + 1),
+			Data:       encoded,
+			AppendedAt: appendedAt.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	replay := func() []byte {
+		fsm := setupFSM(t)
+		for _, l := range logs {
+			if err, ok := fsm.Apply(l).(error); ok {
+				t.Fatalf("fsm.Apply() returned an error: %v", err)
+			}
+		}
+
+		snapshot, err := fsm.Snapshot()
+		if err != nil {
+			t.Fatalf("fsm.Snapshot() returned error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := snapshot.Persist(&mockSnapshotSink{writer: &buf}); err != nil {
+			t.Fatalf("snapshot.Persist() returned error: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	first := replay()
+	// Cross a wall-clock second boundary so any time.Now() read would show up
+	time.Sleep(1100 * time.Millisecond)
+	second := replay()
+
+	if !bytes.Equal(first, second) {
+		t.Fatalf("replayed FSMs diverged:\nfirst:  %s\nsecond: %s", first, second)
+	}
+
+	var state TaskManifestSnapshotData
+	if err := json.Unmarshal(first, &state); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if got := state.Tasks[taskA].StartedAt; got != 1700000003 {
+		t.Errorf("expected StartedAt from AssignedAt, got %d", got)
+	}
+	if got := state.Tasks[taskB].StartedAt; got != appendedAt.Add(4*time.Second).Unix() {
+		t.Errorf("expected StartedAt from AppendedAt, got %d", got)
+	}
+	if got := state.Tasks[taskA].CompletedAt; got != 1700000005 {
+		t.Errorf("expected CompletedAt from entry, got %d", got)
+	}
+	if got := state.Nodes[nodeID].LastHeartbeat; got != appendedAt.Add(5*time.Second).Unix() {
+		t.Errorf("expected LastHeartbeat from AppendedAt, got %d", got)
+	}
+}
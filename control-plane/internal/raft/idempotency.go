@@ -0,0 +1,87 @@
+package raft
+
+import "errors"
+
+// Request keys are scoped so a submission key never matches a result key,
+// and result keys of different tasks never match each other
+func submitKey(key string) string {
+	return "submit/" + key
+}
+
+func resultKey(taskID, key string) string {
+	return "result/" + taskID + "/" + key
+}
+
+// resultRejections are the errors that reject a result for good, by the
+// kind recorded with its idempotency key. A retry would get the same error,
+// so it is recorded and replayed instead.
+var resultRejections = map[string]error{
+	"invalid_transition":  ErrInvalidTransition,
+	"stale_fencing_token": ErrStaleFencingToken,
+}
+
+// rejectedResult is the recorded rejection of a result, replayed to retries
+type rejectedResult struct {
+	err     error
+	message string
+}
+
+func (r *rejectedResult) Error() string { return r.message }
+func (r *rejectedResult) Unwrap() error { return r.err }
+
+// SubmittedTask returns the ID of the task a SubmitTask idempotency key created
+func (fsm *TaskManifestFSM) SubmittedTask(key string) (string, bool) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	record, found := fsm.manifest.LookupIdempotencyKey(submitKey(key))
+	if !found {
+		return "", false
+	}
+	return record.TaskID, true
+}
+
+// ReportedResult reports whether a result carrying an idempotency key was
+// already applied to or rejected for a task, and the error it was rejected
+// with, if any
+func (fsm *TaskManifestFSM) ReportedResult(taskID, key string) (bool, error) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	return fsm.duplicateResult(taskID, key)
+}
+
+// duplicateResult is ReportedResult for callers holding the lock; results
+// without a key are never duplicates
+func (fsm *TaskManifestFSM) duplicateResult(taskID, key string) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+	record, found := fsm.manifest.LookupIdempotencyKey(resultKey(taskID, key))
+	if !found {
+		return false, nil
+	}
+	if err, rejected := resultRejections[record.Rejection]; rejected {
+		return true, &rejectedResult{err: err, message: record.Message}
+	}
+	return true, nil
+}
+
+// recordResult remembers the idempotency key of a result, if any, and
+// returns err. Results rejected for good are remembered with their error.
+func (fsm *TaskManifestFSM) recordResult(taskID, key string, at int64, err error) error {
+	if key == "" {
+		return err
+	}
+	if err == nil {
+		fsm.manifest.RecordIdempotencyKey(resultKey(taskID, key), taskID, at)
+		return nil
+	}
+	for rejection, rejectionErr := range resultRejections {
+		if errors.Is(err, rejectionErr) {
+			fsm.manifest.RecordRejectedKey(resultKey(taskID, key), taskID, rejection, err.Error(), at)
+			break
+		}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package raft
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"ml-raft-control-plane/internal/models"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestFSM_Apply_IdempotentSubmission(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1", TaskType: "train", CreatedAt: 100, IdempotencyKey: "req-1"})
+
+	// A retry that reached the log under a new task ID creates nothing
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-2", TaskType: "train", CreatedAt: 101, IdempotencyKey: "req-1"})
+	if _, found := fsm.GetTask("task-2"); found {
+		t.Error("expected the retried submission not to create task-2")
+	}
+	if taskID, found := fsm.SubmittedTask("req-1"); !found || taskID != "task-1" {
+		t.Errorf("expected req-1 to name task-1, got %q, %v", taskID, found)
+	}
+
+	// Keys are forgotten once the retention has passed
+	later := 100 + int64(models.IdempotencyRetention.Seconds())
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-3", TaskType: "train", CreatedAt: later, IdempotencyKey: "req-3"})
+	if _, found := fsm.SubmittedTask("req-1"); found {
+		t.Error("expected req-1 to be forgotten after the retention")
+	}
+	if taskID, _ := fsm.SubmittedTask("req-3"); taskID != "task-3" {
+		t.Errorf("expected req-3 to name task-3, got %q", taskID)
+	}
+}
+
+func TestFSM_Apply_IdempotentResult(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	complete := CompleteTaskEntry{TaskID: "task-1", FencingToken: 10, CompletedAt: 200, IdempotencyKey: "result-1"}
+	if err := applyAt(t, fsm, 11, LogEntryCompleteTask, complete); err != nil {
+		t.Fatalf("complete returned error: %v", err)
+	}
+
+	// The same report again is accepted without effect, another one is not
+	if err := applyAt(t, fsm, 12, LogEntryCompleteTask, complete); err != nil {
+		t.Errorf("expected the retried result to be accepted, got %v", err)
+	}
+	complete.IdempotencyKey = ""
+	if err := applyAt(t, fsm, 13, LogEntryCompleteTask, complete); err == nil {
+		t.Error("expected a result without the key to be rejected")
+	}
+	assertStatus(t, fsm, "task-1", pb.TaskStatus_COMPLETED)
+
+	// Result keys are scoped to their task
+	reported, err := fsm.ReportedResult("task-1", "result-1")
+	if !reported || err != nil {
+		t.Errorf("expected result-1 to be recorded as applied for task-1, got %v, %v", reported, err)
+	}
+	if reported, _ := fsm.ReportedResult("task-2", "result-1"); reported {
+		t.Error("expected result-1 to be recorded for task-1 only")
+	}
+}
+
+func TestFSM_Apply_IdempotentResultReplaysRejection(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	stale := FailTaskEntry{TaskID: "task-1", FencingToken: 9, FailedAt: 200, IdempotencyKey: "result-1"}
+	if err := applyAt(t, fsm, 11, LogEntryFailTask, stale); !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+
+	// A retry under the same key gets the first outcome even with the current token
+	retry := FailTaskEntry{TaskID: "task-1", FencingToken: 10, FailedAt: 201, IdempotencyKey: "result-1"}
+	err := applyAt(t, fsm, 12, LogEntryFailTask, retry)
+	if !errors.Is(err, ErrStaleFencingToken) || !strings.Contains(err.Error(), "got 9") {
+		t.Errorf("expected the first rejection to be replayed, got %v", err)
+	}
+	assertStatus(t, fsm, "task-1", pb.TaskStatus_ASSIGNED)
+	if reported, err := fsm.ReportedResult("task-1", "result-1"); !reported || !errors.Is(err, ErrStaleFencingToken) {
+		t.Errorf("expected result-1 to be recorded as rejected, got %v, %v", reported, err)
+	}
+
+	// Unknown tasks are not remembered, so a key can be used once the task exists
+	complete := CompleteTaskEntry{TaskID: "task-2", IdempotencyKey: "result-2"}
+	if err := applyAt(t, fsm, 13, LogEntryCompleteTask, complete); !errors.Is(err, ErrUnknownTask) {
+		t.Fatalf("expected ErrUnknownTask, got %v", err)
+	}
+	if reported, _ := fsm.ReportedResult("task-2", "result-2"); reported {
+		t.Error("expected a result for an unknown task not to be recorded")
+	}
+}
+
+func TestFSM_Snapshot_RestoresIdempotencyKeys(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1", CreatedAt: 100, IdempotencyKey: "req-1"})
+
+	snapshot, err := fsm.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Persist(&mockSnapshotSink{writer: &buf}); err != nil {
+		t.Fatalf("snapshot.Persist() returned error: %v", err)
+	}
+	restored := setupFSM(t)
+	if err := restored.Restore(io.NopCloser(&buf)); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+
+	if taskID, found := restored.SubmittedTask("req-1"); !found || taskID != "task-1" {
+		t.Errorf("expected req-1 to survive a snapshot, got %q, %v", taskID, found)
+	}
+	applyLog(t, restored, LogEntryAddTask, AddTaskEntry{TaskID: "task-2", CreatedAt: 101, IdempotencyKey: "req-1"})
+	if _, found := restored.GetTask("task-2"); found {
+		t.Error("expected the restored key to deduplicate a retry")
+	}
+}
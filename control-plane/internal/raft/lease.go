@@ -0,0 +1,41 @@
+package raft
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// expireLeases commits an ExpireLease entry for every leased task whose lease
+// lapsed, putting it back in the PENDING queue or, if it was CANCELLING,
+// finishing the cancellation
+func (rc *RaftCluster) expireLeases(now time.Time) {
+	for _, task := range rc.fsm.ExpiredLeases(now.Unix()) {
+		entry := ExpireLeaseEntry{
+			TaskID:       task.TaskId,
+			FencingToken: task.FencingToken,
+			ExpiredAt:    now.Unix(),
+		}
+
+		data, err := EncodeLogEntry(LogEntryExpireLease, entry)
+		if err != nil {
+			log.Printf("Failed to encode lease expiry for task %s: %v", task.TaskId, err)
+			continue
+		}
+
+		err = rc.Apply(data, leaderApplyTimeout)
+		if errors.Is(err, ErrLeaseActive) || errors.Is(err, ErrStaleFencingToken) || errors.Is(err, ErrInvalidTransition) {
+			continue // Renewed, reported or reassigned before the entry committed
+		}
+		if err != nil {
+			log.Printf("Failed to expire lease of task %s: %v", task.TaskId, err)
+			continue
+		}
+
+		rc.detectorMu.Lock()
+		rc.detectorStats.LeasesExpired++
+		rc.detectorMu.Unlock()
+
+		log.Printf("Lease of task %s on node %s expired, task requeued", task.TaskId, task.AssignedNodeId)
+	}
+}
@@ -0,0 +1,135 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// helper to apply a log entry at a given index and return the FSM's error, if any
+func applyAt(t *testing.T, fsm *TaskManifestFSM, index uint64, entryType LogEntryType, data interface{}) error {
+	t.Helper()
+	encodedData, err := EncodeLogEntry(entryType, data)
+	if err != nil {
+		t.Fatalf("failed to encode log entry: %v", err)
+	}
+
+	err, _ = fsm.Apply(&raft.Log{Index: index, Data: encodedData}).(error)
+	return err
+}
+
+func TestFSM_Apply_FencingTokenRejectsZombieResult(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-2"})
+
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1", LeaseExpiresAt: 100}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+	if err := applyAt(t, fsm, 11, LogEntryExpireLease, ExpireLeaseEntry{TaskID: "task-1", FencingToken: 10, ExpiredAt: 100}); err != nil {
+		t.Fatalf("expire returned error: %v", err)
+	}
+	if err := applyAt(t, fsm, 12, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-2", LeaseExpiresAt: 200}); err != nil {
+		t.Fatalf("reassign returned error: %v", err)
+	}
+
+	// node-1 wakes up and reports with its old token
+	err := applyAt(t, fsm, 13, LogEntryCompleteTask, CompleteTaskEntry{TaskID: "task-1", FencingToken: 10})
+	if !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+
+	if err := applyAt(t, fsm, 14, LogEntryCompleteTask, CompleteTaskEntry{TaskID: "task-1", FencingToken: 12}); err != nil {
+		t.Fatalf("complete with current token returned error: %v", err)
+	}
+	task, _ := fsm.GetTask("task-1")
+	if task.Status != pb.TaskStatus_COMPLETED || task.AssignedNodeId != "node-2" {
+		t.Errorf("expected task COMPLETED on node-2, got %s on %q", task.Status, task.AssignedNodeId)
+	}
+}
+
+func TestFSM_Apply_HeartbeatRenewsHeldLeases(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1"})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	if err := applyAt(t, fsm, 5, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1", LeaseExpiresAt: 100}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	// A renewal with the wrong token must not extend the lease
+	applyLog(t, fsm, LogEntryNodeHeartbeat, NodeHeartbeatEntry{
+		NodeID:         "node-1",
+		Leases:         []*pb.TaskLease{{TaskId: "task-1", FencingToken: 4}},
+		LeaseExpiresAt: 500,
+	})
+	if task, _ := fsm.GetTask("task-1"); task.LeaseExpiresAt != 100 {
+		t.Fatalf("expected lease unchanged by stale token, got %d", task.LeaseExpiresAt)
+	}
+
+	applyLog(t, fsm, LogEntryNodeHeartbeat, NodeHeartbeatEntry{
+		NodeID:         "node-1",
+		Leases:         []*pb.TaskLease{{TaskId: "task-1", FencingToken: 5}},
+		LeaseExpiresAt: 160,
+	})
+	if task, _ := fsm.GetTask("task-1"); task.LeaseExpiresAt != 160 {
+		t.Fatalf("expected lease renewed to 160, got %d", task.LeaseExpiresAt)
+	}
+
+	// The leader decided at 150 that the lease lapsed, but the renewal won
+	err := applyLogErr(t, fsm, LogEntryExpireLease, ExpireLeaseEntry{TaskID: "task-1", FencingToken: 5, ExpiredAt: 150})
+	if !errors.Is(err, ErrLeaseActive) {
+		t.Fatalf("expected ErrLeaseActive, got %v", err)
+	}
+	if task, _ := fsm.GetTask("task-1"); task.Status != pb.TaskStatus_ASSIGNED {
+		t.Errorf("expected task to stay ASSIGNED, got %s", task.Status)
+	}
+}
+
+func TestExpireLeases_RequeuesLapsedTasks(t *testing.T) {
+	cluster := setupCluster(t, 1, nil)
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	apply := func(entryType LogEntryType, entry interface{}) {
+		t.Helper()
+		data, err := EncodeLogEntry(entryType, entry)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+
+	now := time.Now()
+	apply(LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "node-1", Timestamp: now.Unix()})
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "task-lapsed"})
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "task-held"})
+	apply(LogEntryAssignTask, AssignTaskEntry{TaskID: "task-lapsed", NodeID: "node-1", LeaseExpiresAt: now.Add(-time.Second).Unix()})
+	apply(LogEntryAssignTask, AssignTaskEntry{TaskID: "task-held", NodeID: "node-1", LeaseExpiresAt: now.Add(time.Minute).Unix()})
+
+	cluster.expireLeases(now)
+
+	if task, _ := cluster.GetFSM().GetTask("task-lapsed"); task.Status != pb.TaskStatus_PENDING || task.AssignedNodeId != "" {
+		t.Errorf("expected lapsed task PENDING and unassigned, got %s on %q", task.Status, task.AssignedNodeId)
+	}
+	if task, _ := cluster.GetFSM().GetTask("task-held"); task.Status != pb.TaskStatus_ASSIGNED {
+		t.Errorf("expected held task to stay ASSIGNED, got %s", task.Status)
+	}
+	if node, _ := cluster.GetFSM().GetNode("node-1"); node.ActiveTasks != 1 {
+		t.Errorf("expected 1 active task on node-1, got %d", node.ActiveTasks)
+	}
+	if stats := cluster.GetFailureDetectorStats(); stats.LeasesExpired != 1 {
+		t.Errorf("expected 1 expired lease in stats, got %d", stats.LeasesExpired)
+	}
+}
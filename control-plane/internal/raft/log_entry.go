@@ -3,6 +3,8 @@ package raft
 import (
     "encoding/json"
     "fmt"
+
+    pb "ml-raft-control-plane/pkg/proto"
 )
 
 // LogEntryType represents the type of operation in a Raft log entry
@@ -16,6 +18,21 @@ const (
     LogEntryFailTask
     LogEntryNodeHeartbeat
     LogEntryRegisterNode
+    LogEntryRegisterServer
+    LogEntryRetryTask
+    LogEntryDeadLetterTask
+    LogEntryMarkNodeUnhealthy
+    LogEntryExpireLease
+    LogEntryPlaceTask
+    LogEntryPreemptTask
+    LogEntrySubmitJob
+    LogEntryCancelJob
+    LogEntryCancelTask
+    LogEntryAcknowledgeCancel
+    LogEntryReportProgress
+    LogEntryAddTasks
+    LogEntryBatch
+    LogEntryPlaceTasks
 )
 
 // LogEntry represents an operation to be applied to the FSM
@@ -26,10 +43,60 @@ type LogEntry struct {
 
 // AddTaskEntry represents adding a new task
 type AddTaskEntry struct {
-    TaskID      string            `json:"task_id"`
-    TaskType    string            `json:"task_type"`
-    TaskData    json.RawMessage   `json:"task_data"`
-    CreatedAt   int64             `json:"created_at"`
+    TaskID         string          `json:"task_id"`
+    TaskType       string          `json:"task_type"`
+    TaskData       json.RawMessage `json:"task_data"`
+    CreatedAt      int64           `json:"created_at"`
+    RetryPolicy    *pb.RetryPolicy `json:"retry_policy,omitempty"`
+    Resources      *pb.Resources   `json:"resources,omitempty"`
+    Affinity       *pb.Affinity    `json:"affinity,omitempty"`
+    Priority       int32           `json:"priority,omitempty"`
+    PriorityClass  string          `json:"priority_class,omitempty"`
+    DependsOn      []string        `json:"depends_on,omitempty"`
+    IdempotencyKey string          `json:"idempotency_key,omitempty"` // Repeated keys do not create another task
+}
+
+// AddTasksEntry represents adding many tasks at once; either every task is
+// added or none is
+type AddTasksEntry struct {
+    Tasks []AddTaskEntry `json:"tasks"`
+}
+
+// BatchEntry represents commands of concurrent callers committed as one
+// log entry. Each command is an encoded LogEntry, applied as if appended at
+// the Unix time in nanoseconds it was queued.
+type BatchEntry struct {
+    Commands []json.RawMessage `json:"commands"`
+    QueuedAt []int64           `json:"queued_at,omitempty"`
+}
+
+// SubmitJobEntry represents adding a job and all of its tasks at once
+type SubmitJobEntry struct {
+    JobID     string         `json:"job_id"`
+    Name      string         `json:"name,omitempty"`
+    CreatedAt int64          `json:"created_at"`
+    Tasks     []AddTaskEntry `json:"tasks"`
+}
+
+// CancelJobEntry represents cancelling every unfinished task of a job
+type CancelJobEntry struct {
+    JobID       string `json:"job_id"`
+    CancelledAt int64  `json:"cancelled_at"`
+}
+
+// CancelTaskEntry represents cancelling a single task
+type CancelTaskEntry struct {
+    TaskID      string `json:"task_id"`
+    CancelledAt int64  `json:"cancelled_at"`
+}
+
+// AcknowledgeCancelEntry represents an agent confirming it stopped a
+// CANCELLING task
+type AcknowledgeCancelEntry struct {
+    TaskID         string `json:"task_id"`
+    FencingToken   uint64 `json:"fencing_token"`
+    CancelledAt    int64  `json:"cancelled_at"`
+    IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // AssignTaskEntry represents assigning a task to a node
@@ -37,9 +104,44 @@ type AssignTaskEntry struct {
     TaskID    string `json:"task_id"`
     NodeID    string `json:"node_id"`
     AssignedAt int64 `json:"assigned_at"`
+    LeaseExpiresAt int64 `json:"lease_expires_at,omitempty"` // 0 means the assignment never expires
+}
+
+// PlaceTaskEntry represents the leader's scheduler choosing a node for a
+// PENDING task. The task is assigned when that node next polls.
+type PlaceTaskEntry struct {
+    TaskID    string `json:"task_id"`
+    NodeID    string `json:"node_id"`
+    Scheduler string `json:"scheduler"` // Strategy that made the decision
+    PlacedAt  int64  `json:"placed_at"`
 }
 
-// UpdateTaskStatusEntry represents updating task status
+// PlaceTasksEntry represents one placement round of the leader: the dropped
+// placements and evictions that make room for higher-priority tasks, then
+// the placements
+type PlaceTasksEntry struct {
+    Unplacements []string           `json:"unplacements,omitempty"` // PENDING tasks whose placement is dropped
+    Preemptions  []PreemptTaskEntry `json:"preemptions,omitempty"`
+    Placements   []PlaceTaskEntry   `json:"placements"`
+}
+
+// ReportProgressEntry represents a batch of progress reports from agents
+type ReportProgressEntry struct {
+    Updates []ProgressUpdate `json:"updates"`
+}
+
+// ProgressUpdate is the latest progress an agent reported for one task
+type ProgressUpdate struct {
+    TaskID          string             `json:"task_id"`
+    FencingToken    uint64             `json:"fencing_token"`
+    PercentComplete float64            `json:"percent_complete"`
+    Stage           string             `json:"stage,omitempty"`
+    Metrics         map[string]float64 `json:"metrics,omitempty"`
+    ReportedAt      int64              `json:"reported_at"`
+}
+
+// UpdateTaskStatusEntry represents an agent starting an ASSIGNED task; Status
+// must be RUNNING
 type UpdateTaskStatusEntry struct {
     TaskID    string `json:"task_id"`
     Status    string `json:"status"`
@@ -48,31 +150,95 @@ type UpdateTaskStatusEntry struct {
 
 // CompleteTaskEntry represents completing a task
 type CompleteTaskEntry struct {
-    TaskID      string          `json:"task_id"`
-    ResultData  json.RawMessage `json:"result_data"`
-    CompletedAt int64           `json:"completed_at"`
+    TaskID         string          `json:"task_id"`
+    ResultData     json.RawMessage `json:"result_data"`
+    CompletedAt    int64           `json:"completed_at"`
+    FencingToken   uint64          `json:"fencing_token"`
+    IdempotencyKey string          `json:"idempotency_key,omitempty"` // Repeated keys are acknowledged without effect
 }
 
 // FailTaskEntry represents a task failure
 type FailTaskEntry struct {
-    TaskID       string `json:"task_id"`
-    ErrorMessage string `json:"error_message"`
-    FailedAt     int64  `json:"failed_at"`
+    TaskID         string `json:"task_id"`
+    ErrorMessage   string `json:"error_message"`
+    ErrorClass     string `json:"error_class,omitempty"`
+    FailedAt       int64  `json:"failed_at"`
+    FencingToken   uint64 `json:"fencing_token"`
+    IdempotencyKey string `json:"idempotency_key,omitempty"` // Repeated keys are acknowledged without effect
+}
+
+// RetryTaskEntry represents re-queueing a FAILED task for another attempt
+type RetryTaskEntry struct {
+    TaskID    string `json:"task_id"`
+    Attempt   int32  `json:"attempt"` // Attempt that failed; stale entries are rejected
+    RetriedAt int64  `json:"retried_at"`
+}
+
+// DeadLetterTaskEntry represents giving up on a FAILED task
+type DeadLetterTaskEntry struct {
+    TaskID         string `json:"task_id"`
+    Attempt        int32  `json:"attempt"` // Attempt that failed; stale entries are rejected
+    Reason         string `json:"reason"`
+    DeadLetteredAt int64  `json:"dead_lettered_at"`
 }
 
 // NodeHeartbeatEntry represents a node heartbeat
 type NodeHeartbeatEntry struct {
-    NodeID      string  `json:"node_id"`
-    CPUUsage    float64 `json:"cpu_usage"`
-    MemoryUsage float64 `json:"memory_usage"`
-    ActiveTasks int32   `json:"active_tasks"`
-    Timestamp   int64   `json:"timestamp"`
+    NodeID         string            `json:"node_id"`
+    CPUUsage       float64           `json:"cpu_usage"`
+    MemoryUsage    float64           `json:"memory_usage"`
+    ActiveTasks    int32             `json:"active_tasks"`
+    Timestamp      int64             `json:"timestamp"`
+    Leases         []*pb.TaskLease   `json:"leases,omitempty"`           // Leases the node asks to renew
+    LeaseExpiresAt int64             `json:"lease_expires_at,omitempty"` // New expiry of the renewed leases
+    Capacity       *pb.Resources     `json:"capacity,omitempty"`         // Unset keeps the last advertised capacity
+    Allocatable    *pb.Resources     `json:"allocatable,omitempty"`      // Defaults to Capacity
+    CloudProvider  string            `json:"cloud_provider,omitempty"`   // Unset keeps the last advertised value
+    Region         string            `json:"region,omitempty"`           // Unset keeps the last advertised value
+    Labels         map[string]string `json:"labels,omitempty"`           // Replaces the node's labels when set
 }
 
 // RegisterNodeEntry represents registering a new node
 type RegisterNodeEntry struct {
+    NodeID        string            `json:"node_id"`
+    Address       string            `json:"address"`
+    CloudProvider string            `json:"cloud_provider"`
+    Region        string            `json:"region"`
+    RegisteredAt  int64             `json:"registered_at"`
+    Capacity      *pb.Resources     `json:"capacity,omitempty"`
+    Allocatable   *pb.Resources     `json:"allocatable,omitempty"` // Defaults to Capacity
+    Labels        map[string]string `json:"labels,omitempty"`
+}
+
+// MarkNodeUnhealthyEntry represents the leader declaring a node dead
+// and reclaiming the tasks it owned
+type MarkNodeUnhealthyEntry struct {
     NodeID        string `json:"node_id"`
-    Address       string `json:"address"`
+    LastHeartbeat int64  `json:"last_heartbeat"` // Heartbeat the decision was based on
+    DetectedAt    int64  `json:"detected_at"`
+}
+
+// ExpireLeaseEntry represents the leader revoking a lapsed task lease
+type ExpireLeaseEntry struct {
+    TaskID       string `json:"task_id"`
+    FencingToken uint64 `json:"fencing_token"` // Assignment the decision was based on
+    ExpiredAt    int64  `json:"expired_at"`
+}
+
+// PreemptTaskEntry represents the leader evicting a task from its node to
+// make room for a higher-priority one
+type PreemptTaskEntry struct {
+    TaskID       string `json:"task_id"`
+    FencingToken uint64 `json:"fencing_token"` // Assignment the decision was based on
+    PreemptorID  string `json:"preemptor_id"`  // Higher-priority task the room is made for
+    PreemptedAt  int64  `json:"preempted_at"`
+}
+
+// RegisterServerEntry represents publishing control-plane server metadata
+type RegisterServerEntry struct {
+    ServerID      string `json:"server_id"`
+    RaftAddress   string `json:"raft_address"`
+    GRPCAddress   string `json:"grpc_address"`
     CloudProvider string `json:"cloud_provider"`
     Region        string `json:"region"`
     RegisteredAt  int64  `json:"registered_at"`
@@ -0,0 +1,342 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"time"
+
+	"ml-raft-control-plane/internal/models"
+	"ml-raft-control-plane/internal/scheduler"
+	pb "ml-raft-control-plane/pkg/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// RunPlacementLoop periodically places PENDING tasks on nodes, committing
+// each round of decisions as a single log entry. It only acts while this
+// node is the leader and returns when ctx is cancelled.
+func (rc *RaftCluster) RunPlacementLoop(ctx context.Context) {
+	interval := rc.config.PlacementInterval
+	if interval <= 0 {
+		interval = defaultPlacementInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if rc.IsLeader() {
+				if err := rc.PlaceTasks(); err != nil {
+					log.Printf("Failed to place tasks: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// PlaceTasks runs a placement round: unplaced PENDING tasks are placed in
+// dispatch order, preempting lower-priority work for tasks that fit nowhere,
+// until MaxPlacementsPerRound tasks are placed; tasks an earlier round found
+// to fit nowhere are skipped until the nodes change. The round is committed as one
+// log entry. A caller that waited for a round already in progress returns
+// once it finishes instead of running another.
+func (rc *RaftCluster) PlaceTasks() error {
+	round := rc.placementRounds.Load()
+	rc.placementMu.Lock()
+	defer rc.placementMu.Unlock()
+
+	if rc.placementRounds.Load() != round || !rc.IsLeader() {
+		return nil
+	}
+	defer rc.placementRounds.Add(1)
+
+	taskScheduler := rc.config.Scheduler
+	if taskScheduler == nil {
+		taskScheduler = scheduler.LeastLoaded{}
+	}
+	limit := rc.config.MaxPlacementsPerRound
+	if limit <= 0 {
+		limit = defaultMaxPlacementsPerRound
+	}
+
+	var entry PlaceTasksEntry
+	entry, rc.unplaceable = rc.fsm.planPlacements(placementRound{
+		scheduler:   taskScheduler,
+		preempt:     !rc.config.DisablePreemption,
+		limit:       limit,
+		ttl:         int64(rc.PlacementTTL().Seconds()),
+		now:         time.Now(),
+		unplaceable: rc.unplaceable,
+	})
+	if len(entry.Placements) == 0 {
+		return nil
+	}
+
+	data, err := EncodeLogEntry(LogEntryPlaceTasks, entry)
+	if err != nil {
+		return err
+	}
+	if err := rc.Apply(data, leaderApplyTimeout); err != nil {
+		return fmt.Errorf("failed to apply placement of %d tasks: %w", len(entry.Placements), err)
+	}
+	return nil
+}
+
+// PlacementTTL is how long a placement holds a task for its node. Placements
+// expire with the lease duration so a node that stops polling does not hold
+// on to its queue.
+func (rc *RaftCluster) PlacementTTL() time.Duration {
+	return rc.TaskLeaseDuration()
+}
+
+// NextPlacedTask returns a copy of the first PENDING task in dispatch order
+// whose placement on nodeID has not expired and that fits the node. It walks
+// the pending index under the read lock and copies only the task it returns.
+func (fsm *TaskManifestFSM) NextPlacedTask(nodeID string, now time.Time, ttl time.Duration) (*pb.Task, bool) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	node, exists := fsm.manifest.Nodes[nodeID]
+	if !exists {
+		return nil, false
+	}
+
+	var placed *pb.Task
+	fsm.manifest.ForEachPending(func(task *pb.Task) bool {
+		if task.PlacedNodeId == nodeID && now.Unix()-task.PlacedAt <= int64(ttl.Seconds()) &&
+			models.Fits(node, task.Resources) {
+			placed = proto.Clone(task).(*pb.Task)
+			return false
+		}
+		return true
+	})
+	return placed, placed != nil
+}
+
+// placementRound holds the settings of a placement round and the tasks
+// earlier rounds found to fit nowhere
+type placementRound struct {
+	scheduler   scheduler.Scheduler
+	preempt     bool
+	limit       int
+	ttl         int64
+	now         time.Time
+	unplaceable map[string]uint64 // Task ID to the fingerprint of the nodes it fit none of
+}
+
+// planPlacements decides the placements and preemptions of a round under
+// the read lock, reading tasks in place rather than copying them. Tasks that
+// fit nowhere are skipped while the nodes they were checked against are
+// unchanged. It also returns the tasks that fit nowhere this round.
+func (fsm *TaskManifestFSM) planPlacements(round placementRound) (PlaceTasksEntry, map[string]uint64) {
+	fsm.mu.RLock()
+	defer fsm.mu.RUnlock()
+
+	view := &placementView{
+		nodes:   fsm.healthyNodes(),
+		byID:    make(map[string]*pb.Node),
+		placed:  make(map[string][]*pb.Task),
+		dropped: make(map[string]bool),
+	}
+	for _, node := range view.nodes {
+		view.byID[node.NodeId] = node
+	}
+	placedOn := func(task *pb.Task) *pb.Node {
+		if task.PlacedNodeId == "" || view.dropped[task.TaskId] || round.now.Unix()-task.PlacedAt > round.ttl {
+			return nil
+		}
+		return view.byID[task.PlacedNodeId]
+	}
+
+	// Tasks placed on a node count towards its load and allocations until it polls them
+	fsm.manifest.ForEachPending(func(task *pb.Task) bool {
+		if node := placedOn(task); node != nil {
+			reserve(node, task)
+			view.placed[node.NodeId] = append(view.placed[node.NodeId], task)
+		}
+		return true
+	})
+
+	var entry PlaceTasksEntry
+	unplaceable := make(map[string]uint64)
+	fingerprint, changed := uint64(0), true
+	leasedLoaded := false
+	fsm.manifest.ForEachPending(func(task *pb.Task) bool {
+		if len(entry.Placements) >= round.limit {
+			return false
+		}
+		if placedOn(task) != nil {
+			return true
+		}
+		if changed {
+			fingerprint, changed = nodesFingerprint(view.nodes), false
+		}
+		if known, found := round.unplaceable[task.TaskId]; found && known == fingerprint {
+			unplaceable[task.TaskId] = fingerprint
+			return true
+		}
+
+		node := round.scheduler.Select(task, view.nodes)
+		if node == nil && round.preempt {
+			if !leasedLoaded {
+				view.leased, leasedLoaded = fsm.leasedTasks(), true
+			}
+			node = view.makeRoom(&entry, round, task)
+		}
+		if node == nil {
+			unplaceable[task.TaskId] = fingerprint
+			return true
+		}
+
+		entry.Placements = append(entry.Placements, PlaceTaskEntry{
+			TaskID:    task.TaskId,
+			NodeID:    node.NodeId,
+			Scheduler: round.scheduler.Name(),
+			PlacedAt:  round.now.Unix(),
+		})
+		reserve(node, task)
+		changed = true
+		return true
+	})
+	return entry, unplaceable
+}
+
+// nodesFingerprint hashes what decides whether a task fits on any of nodes:
+// their identity, labels and resources. Load and usage only decide between
+// nodes that fit.
+func nodesFingerprint(nodes []*pb.Node) uint64 {
+	hash := fnv.New64a()
+	for _, node := range nodes {
+		data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(&pb.Node{
+			NodeId:        node.NodeId,
+			CloudProvider: node.CloudProvider,
+			Region:        node.Region,
+			Labels:        node.Labels,
+			Capacity:      node.Capacity,
+			Allocatable:   node.Allocatable,
+			Allocated:     node.Allocated,
+		})
+		hash.Write(data)
+	}
+	return hash.Sum64()
+}
+
+// placementView is the healthy nodes as a placement round sees them
+type placementView struct {
+	nodes   []*pb.Node
+	byID    map[string]*pb.Node
+	placed  map[string][]*pb.Task // Node ID to the tasks earlier rounds placed on it
+	dropped map[string]bool       // Tasks whose placement this round drops
+	leased  []*pb.Task            // Tasks that may still be evicted
+}
+
+// makeRoom frees a node for task. Placements of lower-priority tasks that
+// have not started are dropped first, as that loses no work; lower-priority
+// ASSIGNED or RUNNING tasks are only evicted when that is not enough. The
+// decisions are added to entry and their resources released from the view.
+// It returns the freed node, or nil if task fits nowhere even so.
+func (v *placementView) makeRoom(entry *PlaceTasksEntry, round placementRound, task *pb.Task) *pb.Node {
+	// The nodes as they would be without their lower-priority placements
+	released := make([]*pb.Node, len(v.nodes))
+	for i, node := range v.nodes {
+		released[i] = proto.Clone(node).(*pb.Node)
+		for _, placed := range v.lowerPlacements(node.NodeId, task) {
+			unreserve(released[i], placed)
+		}
+	}
+
+	if node := round.scheduler.Select(task, released); node != nil {
+		target := v.byID[node.NodeId]
+		for _, placed := range v.lowerPlacements(target.NodeId, task) {
+			if models.Fits(target, task.Resources) {
+				break
+			}
+			v.drop(entry, target, placed, task)
+		}
+		return target
+	}
+
+	plan := scheduler.PlanPreemption(task, released, v.leased)
+	if plan == nil {
+		return nil
+	}
+	target := v.byID[plan.Node.NodeId]
+	for _, placed := range v.lowerPlacements(target.NodeId, task) {
+		v.drop(entry, target, placed, task)
+	}
+
+	evicted := make(map[string]bool, len(plan.Victims))
+	for _, victim := range plan.Victims {
+		entry.Preemptions = append(entry.Preemptions, PreemptTaskEntry{
+			TaskID:       victim.TaskId,
+			FencingToken: victim.FencingToken,
+			PreemptorID:  task.TaskId,
+			PreemptedAt:  round.now.Unix(),
+		})
+		evicted[victim.TaskId] = true
+		unreserve(target, victim)
+		log.Printf("Preempting task %s (priority %d) on node %s for task %s (priority %d)",
+			victim.TaskId, victim.Priority, target.NodeId, task.TaskId, task.Priority)
+	}
+
+	remaining := make([]*pb.Task, 0, len(v.leased)-len(evicted))
+	for _, candidate := range v.leased {
+		if !evicted[candidate.TaskId] {
+			remaining = append(remaining, candidate)
+		}
+	}
+	v.leased = remaining
+	return target
+}
+
+// lowerPlacements returns the tasks earlier rounds placed on nodeID with a
+// lower priority than task, lowest priority and then most recently placed
+// first
+func (v *placementView) lowerPlacements(nodeID string, task *pb.Task) []*pb.Task {
+	var lower []*pb.Task
+	for _, placed := range v.placed[nodeID] {
+		if placed.Priority < task.Priority && !v.dropped[placed.TaskId] {
+			lower = append(lower, placed)
+		}
+	}
+	sort.SliceStable(lower, func(i, j int) bool {
+		if lower[i].Priority != lower[j].Priority {
+			return lower[i].Priority < lower[j].Priority
+		}
+		return lower[i].PlacedAt > lower[j].PlacedAt
+	})
+	return lower
+}
+
+// drop releases the placement of a task on node to make room for preemptor.
+// The task can be placed again later in the round.
+func (v *placementView) drop(entry *PlaceTasksEntry, node *pb.Node, task, preemptor *pb.Task) {
+	entry.Unplacements = append(entry.Unplacements, task.TaskId)
+	v.dropped[task.TaskId] = true
+	unreserve(node, task)
+	log.Printf("Dropping placement of task %s (priority %d) on node %s for task %s (priority %d)",
+		task.TaskId, task.Priority, node.NodeId, preemptor.TaskId, preemptor.Priority)
+}
+
+// reserve counts a task placed on node towards the node's load and allocations
+func reserve(node *pb.Node, task *pb.Task) {
+	node.ActiveTasks++
+	if task.Resources != nil {
+		node.Allocated = models.AddResources(node.Allocated, task.Resources)
+	}
+}
+
+// unreserve releases a task's share of node's load and allocations
+func unreserve(node *pb.Node, task *pb.Task) {
+	node.ActiveTasks--
+	if task.Resources != nil {
+		node.Allocated = models.SubtractResources(node.Allocated, task.Resources)
+	}
+}
@@ -0,0 +1,256 @@
+package raft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ml-raft-control-plane/internal/scheduler"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestFSM_Apply_PlaceTasks(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1", Capacity: &pb.Resources{CpuCores: 4}})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "batch", Priority: -100, Resources: &pb.Resources{CpuCores: 4}})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "urgent", Priority: 1000, Resources: &pb.Resources{CpuCores: 4}})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "done"})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "batch", NodeID: "node-1"}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+	if err := applyAt(t, fsm, 11, LogEntryAssignTask, AssignTaskEntry{TaskID: "done", NodeID: "node-1"}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	// Decisions made stale since the round was planned are skipped
+	if err := applyAt(t, fsm, 12, LogEntryPlaceTasks, PlaceTasksEntry{
+		Preemptions: []PreemptTaskEntry{
+			{TaskID: "batch", FencingToken: 10, PreemptorID: "urgent"},
+			{TaskID: "done", FencingToken: 9, PreemptorID: "urgent"},
+		},
+		Placements: []PlaceTaskEntry{
+			{TaskID: "urgent", NodeID: "node-1", Scheduler: "least_loaded"},
+			{TaskID: "done", NodeID: "node-1", Scheduler: "least_loaded"},
+			{TaskID: "missing", NodeID: "node-1", Scheduler: "least_loaded"},
+		},
+	}); err != nil {
+		t.Fatalf("place returned error: %v", err)
+	}
+
+	batch, _ := fsm.GetTask("batch")
+	if batch.Status != pb.TaskStatus_PENDING {
+		t.Errorf("expected batch preempted back to PENDING, got %s", batch.Status)
+	}
+	done, _ := fsm.GetTask("done")
+	if done.Status != pb.TaskStatus_ASSIGNED || done.PlacedNodeId != "" {
+		t.Errorf("expected done to stay ASSIGNED and unplaced, got %s on %q", done.Status, done.PlacedNodeId)
+	}
+	urgent, _ := fsm.GetTask("urgent")
+	if urgent.PlacedNodeId != "node-1" || urgent.PlacedBy != "least_loaded" {
+		t.Errorf("expected urgent placed on node-1, got %q by %q", urgent.PlacedNodeId, urgent.PlacedBy)
+	}
+}
+
+func TestPlaceTasks_CommitsEachRoundAsOneEntry(t *testing.T) {
+	cluster := setupCluster(t, 1, nil, func(config *ClusterConfig) {
+		config.MaxPlacementsPerRound = 2
+	})
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	for _, entry := range []struct {
+		entryType LogEntryType
+		data      interface{}
+	}{
+		{LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: "task-1", CreatedAt: 1}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: "task-2", CreatedAt: 2}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: "task-3", CreatedAt: 3}},
+	} {
+		data, err := EncodeLogEntry(entry.entryType, entry.data)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+
+	before := cluster.raft.LastIndex()
+	if err := cluster.PlaceTasks(); err != nil {
+		t.Fatalf("PlaceTasks() returned error: %v", err)
+	}
+	if entries := cluster.raft.LastIndex() - before; entries != 1 {
+		t.Errorf("expected the round committed as 1 log entry, got %d", entries)
+	}
+
+	fsm := cluster.GetFSM()
+	for taskID, node := range map[string]string{"task-1": "node-1", "task-2": "node-1", "task-3": ""} {
+		if task, _ := fsm.GetTask(taskID); task.PlacedNodeId != node {
+			t.Errorf("expected %s placed on %q after one round, got %q", taskID, node, task.PlacedNodeId)
+		}
+	}
+
+	if err := cluster.PlaceTasks(); err != nil {
+		t.Fatalf("PlaceTasks() returned error: %v", err)
+	}
+	if task, _ := fsm.GetTask("task-3"); task.PlacedNodeId != "node-1" {
+		t.Errorf("expected task-3 placed by the next round, got %q", task.PlacedNodeId)
+	}
+}
+
+func TestFSM_NextPlacedTask(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1", Capacity: &pb.Resources{CpuCores: 4}})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-2"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "too-big", Priority: 100, Resources: &pb.Resources{CpuCores: 8}})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "elsewhere", Priority: 50})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "stale", Priority: 10})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "next"})
+	applyLog(t, fsm, LogEntryPlaceTasks, PlaceTasksEntry{Placements: []PlaceTaskEntry{
+		{TaskID: "too-big", NodeID: "node-1", PlacedAt: 1000},
+		{TaskID: "elsewhere", NodeID: "node-2", PlacedAt: 1000},
+		{TaskID: "stale", NodeID: "node-1", PlacedAt: 900},
+		{TaskID: "next", NodeID: "node-1", PlacedAt: 1000},
+	}})
+
+	// Placements that expired, belong to other nodes or no longer fit are passed over
+	task, found := fsm.NextPlacedTask("node-1", time.Unix(1030, 0), time.Minute)
+	if !found || task.TaskId != "next" {
+		t.Fatalf("expected next, got %v", task)
+	}
+	if _, found := fsm.NextPlacedTask("node-3", time.Unix(1030, 0), time.Minute); found {
+		t.Error("expected no task for an unknown node")
+	}
+}
+
+// countingScheduler counts the tasks it is asked to place
+type countingScheduler struct {
+	scheduler.LeastLoaded
+	selects int
+}
+
+func (s *countingScheduler) Select(task *pb.Task, nodes []*pb.Node) *pb.Node {
+	s.selects++
+	return s.LeastLoaded.Select(task, nodes)
+}
+
+func TestPlaceTasks_SkipsTasksThatFitNowhere(t *testing.T) {
+	counting := &countingScheduler{}
+	cluster := setupCluster(t, 1, nil, func(config *ClusterConfig) {
+		config.Scheduler = counting
+	})
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	apply := func(entryType LogEntryType, entry interface{}) {
+		t.Helper()
+		data, err := EncodeLogEntry(entryType, entry)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+	apply(LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1", Capacity: &pb.Resources{CpuCores: 4}})
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "big", Resources: &pb.Resources{CpuCores: 8}})
+
+	if err := cluster.PlaceTasks(); err != nil {
+		t.Fatalf("PlaceTasks() returned error: %v", err)
+	}
+	if _, found := cluster.unplaceable["big"]; !found {
+		t.Fatalf("expected big remembered as fitting nowhere, got %v", cluster.unplaceable)
+	}
+
+	// While the nodes are unchanged the task is not checked again
+	selects := counting.selects
+	if err := cluster.PlaceTasks(); err != nil {
+		t.Fatalf("PlaceTasks() returned error: %v", err)
+	}
+	if counting.selects != selects {
+		t.Errorf("expected big skipped, got %d more scheduler calls", counting.selects-selects)
+	}
+	if _, found := cluster.unplaceable["big"]; !found {
+		t.Error("expected big still remembered while the nodes are unchanged")
+	}
+
+	// A node that grows is checked again
+	apply(LogEntryNodeHeartbeat, NodeHeartbeatEntry{NodeID: "node-1", Capacity: &pb.Resources{CpuCores: 16}})
+	if err := cluster.PlaceTasks(); err != nil {
+		t.Fatalf("PlaceTasks() returned error: %v", err)
+	}
+	if task, _ := cluster.GetFSM().GetTask("big"); task.PlacedNodeId != "node-1" {
+		t.Errorf("expected big placed on node-1 once it fits, got %q", task.PlacedNodeId)
+	}
+	if len(cluster.unplaceable) != 0 {
+		t.Errorf("expected no task remembered as fitting nowhere, got %v", cluster.unplaceable)
+	}
+}
+
+func TestPlaceTasks_DropsPlacementsBeforePreempting(t *testing.T) {
+	cluster := setupCluster(t, 1, nil)
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	apply := func(entryType LogEntryType, entry interface{}) {
+		t.Helper()
+		data, err := EncodeLogEntry(entryType, entry)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+	placeTasks := func() {
+		t.Helper()
+		if err := cluster.PlaceTasks(); err != nil {
+			t.Fatalf("PlaceTasks() returned error: %v", err)
+		}
+	}
+	fsm := cluster.GetFSM()
+
+	apply(LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1", Capacity: &pb.Resources{CpuCores: 4}})
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "running", Priority: -100, Resources: &pb.Resources{CpuCores: 2}})
+	apply(LogEntryAssignTask, AssignTaskEntry{TaskID: "running", NodeID: "node-1"})
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "queued", Priority: -100, Resources: &pb.Resources{CpuCores: 2}})
+	placeTasks()
+	if task, _ := fsm.GetTask("queued"); task.PlacedNodeId != "node-1" {
+		t.Fatalf("expected queued placed on node-1, got %q", task.PlacedNodeId)
+	}
+
+	// Dropping the placement that has not started makes enough room
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "urgent", Priority: 100, Resources: &pb.Resources{CpuCores: 2}})
+	placeTasks()
+	if task, _ := fsm.GetTask("urgent"); task.PlacedNodeId != "node-1" {
+		t.Errorf("expected urgent placed on node-1, got %q", task.PlacedNodeId)
+	}
+	if task, _ := fsm.GetTask("queued"); task.PlacedNodeId != "" {
+		t.Errorf("expected the placement of queued dropped, got %q", task.PlacedNodeId)
+	}
+	assertStatus(t, fsm, "running", pb.TaskStatus_ASSIGNED)
+
+	// Running work is only evicted when dropping placements is not enough
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "critical", Priority: 1000, Resources: &pb.Resources{CpuCores: 4}})
+	placeTasks()
+	if task, _ := fsm.GetTask("critical"); task.PlacedNodeId != "node-1" {
+		t.Errorf("expected critical placed on node-1, got %q", task.PlacedNodeId)
+	}
+	if task, _ := fsm.GetTask("urgent"); task.PlacedNodeId != "" {
+		t.Errorf("expected the placement of urgent dropped, got %q", task.PlacedNodeId)
+	}
+	assertStatus(t, fsm, "running", pb.TaskStatus_PENDING)
+}
@@ -0,0 +1,70 @@
+package raft
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+// BufferProgress holds a progress report until the next flush, replacing
+// any earlier report for the same task that has not been committed yet
+func (rc *RaftCluster) BufferProgress(update ProgressUpdate) {
+	rc.progressMu.Lock()
+	defer rc.progressMu.Unlock()
+
+	if rc.pendingProgress == nil {
+		rc.pendingProgress = make(map[string]ProgressUpdate)
+	}
+	rc.pendingProgress[update.TaskID] = update
+}
+
+// RunProgressFlusher periodically commits buffered progress reports as a
+// single log entry, so agents reporting often do not flood the log. It only
+// acts while this node is the leader and returns when ctx is cancelled.
+func (rc *RaftCluster) RunProgressFlusher(ctx context.Context) {
+	interval := rc.config.ProgressFlushInterval
+	if interval <= 0 {
+		interval = defaultProgressFlushInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rc.flushProgress()
+		}
+	}
+}
+
+// flushProgress commits the buffered progress reports. Reports buffered by a
+// node that lost leadership are dropped; agents report again to the new leader.
+func (rc *RaftCluster) flushProgress() {
+	rc.progressMu.Lock()
+	buffered := rc.pendingProgress
+	rc.pendingProgress = nil
+	rc.progressMu.Unlock()
+
+	if len(buffered) == 0 || !rc.IsLeader() {
+		return
+	}
+
+	entry := ReportProgressEntry{Updates: make([]ProgressUpdate, 0, len(buffered))}
+	for _, update := range buffered {
+		entry.Updates = append(entry.Updates, update)
+	}
+	sort.Slice(entry.Updates, func(i, j int) bool { return entry.Updates[i].TaskID < entry.Updates[j].TaskID })
+
+	data, err := EncodeLogEntry(LogEntryReportProgress, entry)
+	if err != nil {
+		log.Printf("Failed to encode progress of %d tasks: %v", len(entry.Updates), err)
+		return
+	}
+	if err := rc.Apply(data, leaderApplyTimeout); err != nil {
+		log.Printf("Failed to apply progress of %d tasks: %v", len(entry.Updates), err)
+	}
+}
@@ -0,0 +1,92 @@
+package raft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestFSM_Apply_ReportProgress(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "train"})
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "eval"})
+	if err := applyAt(t, fsm, 10, LogEntryAssignTask, AssignTaskEntry{TaskID: "train", NodeID: "node-1"}); err != nil {
+		t.Fatalf("assign returned error: %v", err)
+	}
+
+	// Updates for unassigned tasks or earlier assignments are skipped
+	if err := applyAt(t, fsm, 11, LogEntryReportProgress, ReportProgressEntry{Updates: []ProgressUpdate{
+		{TaskID: "eval", FencingToken: 10, PercentComplete: 50},
+		{TaskID: "train", FencingToken: 9, PercentComplete: 90},
+		{TaskID: "train", FencingToken: 10, PercentComplete: 25, Stage: "epoch 1/4", Metrics: map[string]float64{"loss": 0.7}, ReportedAt: 100},
+	}}); err != nil {
+		t.Fatalf("report returned error: %v", err)
+	}
+
+	assertStatus(t, fsm, "eval", pb.TaskStatus_PENDING)
+	assertStatus(t, fsm, "train", pb.TaskStatus_RUNNING)
+	task, _ := fsm.GetTask("train")
+	if progress := task.Progress; progress.PercentComplete != 25 || progress.Stage != "epoch 1/4" ||
+		progress.Metrics["loss"] != 0.7 || progress.ReportedAt != 100 {
+		t.Errorf("unexpected progress %v", progress)
+	}
+
+	// A new attempt starts without the progress of the last one
+	if err := applyAt(t, fsm, 12, LogEntryPreemptTask, PreemptTaskEntry{TaskID: "train", FencingToken: 10}); err != nil {
+		t.Fatalf("preempt returned error: %v", err)
+	}
+	if err := applyAt(t, fsm, 13, LogEntryAssignTask, AssignTaskEntry{TaskID: "train", NodeID: "node-1"}); err != nil {
+		t.Fatalf("reassign returned error: %v", err)
+	}
+	if task, _ := fsm.GetTask("train"); task.Progress != nil {
+		t.Errorf("expected no progress on a new attempt, got %v", task.Progress)
+	}
+}
+
+func TestFlushProgress_CommitsLatestReports(t *testing.T) {
+	cluster := setupCluster(t, 1, nil)
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	for _, entry := range []struct {
+		entryType LogEntryType
+		data      interface{}
+	}{
+		{LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"}},
+		{LogEntryAddTask, AddTaskEntry{TaskID: "task-1"}},
+		{LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"}},
+	} {
+		data, err := EncodeLogEntry(entry.entryType, entry.data)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+	task, _ := cluster.GetFSM().GetTask("task-1")
+
+	// Only the latest buffered report of a task is committed
+	cluster.BufferProgress(ProgressUpdate{TaskID: "task-1", FencingToken: task.FencingToken, PercentComplete: 10})
+	cluster.BufferProgress(ProgressUpdate{TaskID: "task-1", FencingToken: task.FencingToken, PercentComplete: 20, Stage: "shuffle"})
+	if task, _ := cluster.GetFSM().GetTask("task-1"); task.Progress != nil {
+		t.Fatalf("expected buffered progress not to be committed yet, got %v", task.Progress)
+	}
+
+	cluster.flushProgress()
+
+	task, _ = cluster.GetFSM().GetTask("task-1")
+	if task.Status != pb.TaskStatus_RUNNING || task.Progress.GetPercentComplete() != 20 || task.Progress.GetStage() != "shuffle" {
+		t.Errorf("expected RUNNING at 20%% in shuffle, got %s %v", task.Status, task.Progress)
+	}
+	if len(cluster.pendingProgress) != 0 {
+		t.Errorf("expected the buffer to be empty after a flush, got %d reports", len(cluster.pendingProgress))
+	}
+}
@@ -0,0 +1,91 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ml-raft-control-plane/internal/models"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// leaderApplyTimeout bounds each log entry committed by a leader loop
+const leaderApplyTimeout = 5 * time.Second
+
+// RunRetryLoop periodically re-queues FAILED tasks whose backoff has elapsed
+// and dead-letters those that cannot be retried. It only acts while this
+// node is the leader and returns when ctx is cancelled.
+func (rc *RaftCluster) RunRetryLoop(ctx context.Context) {
+	interval := rc.config.RetryInterval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if rc.IsLeader() {
+				rc.retryFailedTasks(now)
+			}
+		}
+	}
+}
+
+// retryFailedTasks commits a retry or dead-letter entry for each FAILED task that is due
+func (rc *RaftCluster) retryFailedTasks(now time.Time) {
+	failed := pb.TaskStatus_FAILED
+	for _, task := range rc.fsm.ListTasks(&failed, 0) {
+		entryType, entry, due := retryAction(task, now)
+		if !due {
+			continue
+		}
+
+		data, err := EncodeLogEntry(entryType, entry)
+		if err != nil {
+			log.Printf("Failed to encode retry decision for task %s: %v", task.TaskId, err)
+			continue
+		}
+		if err := rc.Apply(data, leaderApplyTimeout); err != nil {
+			log.Printf("Failed to apply retry decision for task %s: %v", task.TaskId, err)
+		}
+	}
+}
+
+// retryAction decides whether a FAILED task should be retried or dead-lettered now.
+// Tasks without a retry policy are left FAILED.
+func retryAction(task *pb.Task, now time.Time) (LogEntryType, interface{}, bool) {
+	if task.RetryPolicy == nil || task.LastFailure == nil {
+		return 0, nil, false
+	}
+
+	failure := task.LastFailure
+	if !models.IsRetryable(task.RetryPolicy, failure) {
+		reason := fmt.Sprintf("retries exhausted after %d attempts", failure.Attempt)
+		if failure.Attempt < task.RetryPolicy.MaxAttempts {
+			reason = fmt.Sprintf("error class %q is not retryable", failure.ErrorClass)
+		}
+		return LogEntryDeadLetterTask, DeadLetterTaskEntry{
+			TaskID:         task.TaskId,
+			Attempt:        task.Attempt,
+			Reason:         reason,
+			DeadLetteredAt: now.Unix(),
+		}, true
+	}
+
+	retryAt := time.Unix(failure.FailedAt, 0).Add(models.RetryBackoff(task.RetryPolicy, failure.Attempt))
+	if now.Before(retryAt) {
+		return 0, nil, false
+	}
+
+	return LogEntryRetryTask, RetryTaskEntry{
+		TaskID:    task.TaskId,
+		Attempt:   task.Attempt,
+		RetriedAt: now.Unix(),
+	}, true
+}
@@ -0,0 +1,145 @@
+package raft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ml-raft-control-plane/internal/models"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &pb.RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoffMs:  1000,
+		BackoffMultiplier: 3,
+		MaxBackoffMs:      5000,
+	}
+
+	want := []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, expected := range want {
+		attempt := int32(i + 1)
+		if got := models.RetryBackoff(policy, attempt); got != expected {
+			t.Errorf("attempt %d: expected backoff %s, got %s", attempt, expected, got)
+		}
+	}
+}
+
+func TestRetryAction(t *testing.T) {
+	failedAt := time.Unix(1700000000, 0)
+	policy := &pb.RetryPolicy{
+		MaxAttempts:      3,
+		InitialBackoffMs: 10000,
+		RetryableErrors:  []string{"PREEMPTED", "OOM"},
+	}
+
+	failedTask := func(attempt int32, class string) *pb.Task {
+		return &pb.Task{
+			TaskId:      "task-1",
+			Status:      pb.TaskStatus_FAILED,
+			Attempt:     attempt,
+			RetryPolicy: policy,
+			LastFailure: &pb.TaskFailure{Attempt: attempt, ErrorClass: class, FailedAt: failedAt.Unix()},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		task     *pb.Task
+		now      time.Time
+		wantDue  bool
+		wantType LogEntryType
+	}{
+		{"no policy", &pb.Task{Status: pb.TaskStatus_FAILED, LastFailure: &pb.TaskFailure{}}, failedAt.Add(time.Hour), false, 0},
+		{"backoff pending", failedTask(1, "PREEMPTED"), failedAt.Add(5 * time.Second), false, 0},
+		{"backoff elapsed", failedTask(1, "PREEMPTED"), failedAt.Add(10 * time.Second), true, LogEntryRetryTask},
+		{"attempts exhausted", failedTask(3, "OOM"), failedAt, true, LogEntryDeadLetterTask},
+		{"not retryable", failedTask(1, "INVALID_INPUT"), failedAt, true, LogEntryDeadLetterTask},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entryType, _, due := retryAction(tt.task, tt.now)
+			if due != tt.wantDue {
+				t.Fatalf("expected due=%v, got %v", tt.wantDue, due)
+			}
+			if due && entryType != tt.wantType {
+				t.Errorf("expected entry type %d, got %d", tt.wantType, entryType)
+			}
+		})
+	}
+}
+
+func TestFSM_Apply_RetryTask_RejectsStaleAttempt(t *testing.T) {
+	fsm := setupFSM(t)
+	applyLog(t, fsm, LogEntryAddTask, AddTaskEntry{TaskID: "task-1", RetryPolicy: &pb.RetryPolicy{MaxAttempts: 3}})
+	applyLog(t, fsm, LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"})
+	applyLog(t, fsm, LogEntryFailTask, FailTaskEntry{TaskID: "task-1", ErrorMessage: "spot preemption", FencingToken: 1})
+
+	applyLog(t, fsm, LogEntryRetryTask, RetryTaskEntry{TaskID: "task-1", Attempt: 1})
+	task, _ := fsm.GetTask("task-1")
+	if task.Status != pb.TaskStatus_PENDING || task.AssignedNodeId != "" {
+		t.Fatalf("expected task to be PENDING and unassigned, got %s on %q", task.Status, task.AssignedNodeId)
+	}
+	if task.LastFailure == nil || task.LastFailure.ErrorMessage != "spot preemption" {
+		t.Errorf("expected last failure to be kept across retries, got %v", task.LastFailure)
+	}
+
+	// A duplicate retry decision for the same attempt must not apply twice
+	if err := applyLogErr(t, fsm, LogEntryRetryTask, RetryTaskEntry{TaskID: "task-1", Attempt: 1}); err == nil {
+		t.Error("expected duplicate retry to be rejected")
+	}
+
+	applyLog(t, fsm, LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"})
+	task, _ = fsm.GetTask("task-1")
+	if task.Attempt != 2 {
+		t.Errorf("expected attempt 2 after reassignment, got %d", task.Attempt)
+	}
+}
+
+func TestRetryFailedTasks_RetriesThenDeadLetters(t *testing.T) {
+	cluster := setupCluster(t, 1, nil)
+	if err := cluster.Bootstrap(context.Background(), nil); err != nil {
+		t.Fatalf("Bootstrap() returned error: %v", err)
+	}
+	if err := cluster.WaitForLeader(10 * time.Second); err != nil {
+		t.Fatalf("no leader elected: %v", err)
+	}
+
+	apply := func(entryType LogEntryType, entry interface{}) {
+		t.Helper()
+		data, err := EncodeLogEntry(entryType, entry)
+		if err != nil {
+			t.Fatalf("failed to encode log entry: %v", err)
+		}
+		if err := cluster.Apply(data, 5*time.Second); err != nil {
+			t.Fatalf("Apply() returned error: %v", err)
+		}
+	}
+
+	now := time.Now()
+	apply(LogEntryAddTask, AddTaskEntry{TaskID: "task-1", RetryPolicy: &pb.RetryPolicy{MaxAttempts: 2}})
+	apply(LogEntryRegisterNode, RegisterNodeEntry{NodeID: "node-1"})
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		apply(LogEntryAssignTask, AssignTaskEntry{TaskID: "task-1", NodeID: "node-1"})
+		assigned, _ := cluster.GetFSM().GetTask("task-1")
+		apply(LogEntryFailTask, FailTaskEntry{TaskID: "task-1", ErrorMessage: "OOM", FailedAt: now.Unix(), FencingToken: assigned.FencingToken})
+		cluster.retryFailedTasks(now.Add(time.Minute))
+	}
+
+	task, _ := cluster.GetFSM().GetTask("task-1")
+	if task.Status != pb.TaskStatus_DEAD_LETTER {
+		t.Fatalf("expected task DEAD_LETTER after exhausting retries, got %s", task.Status)
+	}
+	if task.DeadLetterReason == "" {
+		t.Error("expected dead-letter reason to be recorded")
+	}
+
+	deadLetter := pb.TaskStatus_DEAD_LETTER
+	if tasks := cluster.GetFSM().ListTasks(&deadLetter, 0); len(tasks) != 1 {
+		t.Errorf("expected 1 DEAD_LETTER task in ListTasks, got %d", len(tasks))
+	}
+}
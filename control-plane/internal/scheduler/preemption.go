@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"sort"
+
+	"ml-raft-control-plane/internal/models"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// Preemption is a plan to make room for a task on a node by evicting
+// lower-priority tasks
+type Preemption struct {
+	Node    *pb.Node
+	Victims []*pb.Task // In eviction order
+}
+
+// PlanPreemption returns the cheapest way to fit task on one of nodes by
+// evicting ASSIGNED or RUNNING tasks of lower priority, or nil if there is
+// none. leased holds the tasks currently assigned to nodes.
+//
+// On each node victims are taken lowest priority first and, within a
+// priority, most recently started first so the least work is lost. The plan
+// whose highest-priority victim is lowest wins, then the one with the fewest
+// victims, then the lowest node ID.
+func PlanPreemption(task *pb.Task, nodes []*pb.Node, leased []*pb.Task) *Preemption {
+	byNode := make(map[string][]*pb.Task)
+	for _, victim := range leased {
+		// CANCELLING tasks release their resources once the agent stops them
+		if victim.Priority < task.Priority && models.IsLeased(victim) && victim.Status != pb.TaskStatus_CANCELLING {
+			byNode[victim.AssignedNodeId] = append(byNode[victim.AssignedNodeId], victim)
+		}
+	}
+
+	var best *Preemption
+	for _, node := range sortedByID(nodes) {
+		candidates := byNode[node.NodeId]
+		if len(candidates) == 0 || models.Fits(node, task.Resources) {
+			continue
+		}
+		if models.AffinityMismatch(node, task.Affinity) != "" {
+			continue
+		}
+
+		victims := evict(node, task.Resources, candidates)
+		if victims == nil {
+			continue
+		}
+		plan := &Preemption{Node: node, Victims: victims}
+		if best == nil || cheaper(plan, best) {
+			best = plan
+		}
+	}
+	return best
+}
+
+// evict returns the candidates to remove from node, in eviction order, until
+// request fits. It returns nil if evicting every candidate is not enough.
+func evict(node *pb.Node, request *pb.Resources, candidates []*pb.Task) []*pb.Task {
+	ordered := make([]*pb.Task, len(candidates))
+	copy(ordered, candidates)
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.StartedAt != b.StartedAt {
+			return a.StartedAt > b.StartedAt
+		}
+		return a.TaskId < b.TaskId
+	})
+
+	freed := &pb.Node{Capacity: node.Capacity, Allocatable: node.Allocatable, Allocated: node.Allocated}
+	var victims []*pb.Task
+	for _, victim := range ordered {
+		if models.Fits(freed, request) {
+			break
+		}
+		if victim.Resources != nil {
+			freed.Allocated = models.SubtractResources(freed.Allocated, victim.Resources)
+		}
+		victims = append(victims, victim)
+	}
+	if !models.Fits(freed, request) {
+		return nil
+	}
+	return victims
+}
+
+// cheaper reports whether plan a evicts less important work than plan b
+func cheaper(a, b *Preemption) bool {
+	if ha, hb := highestPriority(a.Victims), highestPriority(b.Victims); ha != hb {
+		return ha < hb
+	}
+	return len(a.Victims) < len(b.Victims)
+}
+
+// highestPriority returns the highest priority among tasks
+func highestPriority(tasks []*pb.Task) int32 {
+	highest := tasks[0].Priority
+	for _, task := range tasks[1:] {
+		if task.Priority > highest {
+			highest = task.Priority
+		}
+	}
+	return highest
+}
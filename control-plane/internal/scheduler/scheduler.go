@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ml-raft-control-plane/internal/models"
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// Built-in strategy names accepted in the node configuration
+const (
+	StrategyLeastLoaded = "least_loaded"
+	StrategyBinPacking  = "bin_packing"
+	StrategySpread      = "spread"
+	StrategyRandomOfTwo = "random_of_two"
+)
+
+// Scheduler chooses the node a task should run on. Schedulers only run on
+// the leader; their decisions are committed to the Raft log so followers
+// never recompute placement.
+type Scheduler interface {
+	// Name returns the strategy name recorded with each placement
+	Name() string
+
+	// Select returns the node to place task on, or nil if no node fits.
+	// nodes are healthy candidates whose ActiveTasks and Allocated already
+	// count the tasks placed on them but not yet polled. A node that lacks
+	// the task's requested resources or violates its required affinity is
+	// never selected, and the strategy only chooses among the nodes that
+	// best match the task's preferred affinity.
+	Select(task *pb.Task, nodes []*pb.Node) *pb.Node
+}
+
+// New returns the built-in scheduler for a strategy name. An empty name
+// selects least-loaded placement.
+func New(strategy string) (Scheduler, error) {
+	switch strategy {
+	case "", StrategyLeastLoaded:
+		return LeastLoaded{}, nil
+	case StrategyBinPacking:
+		return BinPacking{}, nil
+	case StrategySpread:
+		return Spread{}, nil
+	case StrategyRandomOfTwo:
+		return NewRandomOfTwo(), nil
+	default:
+		return nil, fmt.Errorf("unknown scheduler strategy %q", strategy)
+	}
+}
+
+// feasible returns the nodes that can run the task and, among those, only
+// the ones with the highest preferred affinity score
+func feasible(task *pb.Task, nodes []*pb.Node) []*pb.Node {
+	var fits []*pb.Node
+	var bestScore int64
+	for _, node := range nodes {
+		if models.UnfitReason(node, task) != "" {
+			continue
+		}
+
+		score := models.PreferenceScore(node, task.Affinity)
+		if len(fits) == 0 || score > bestScore {
+			fits, bestScore = fits[:0], score
+		} else if score < bestScore {
+			continue
+		}
+		fits = append(fits, node)
+	}
+	return fits
+}
+
+// Explain describes why no node can run a task, or returns "" if one can
+func Explain(task *pb.Task, nodes []*pb.Node) string {
+	if len(nodes) == 0 {
+		return "no healthy nodes"
+	}
+
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		reason := models.UnfitReason(node, task)
+		if reason == "" {
+			return ""
+		}
+		counts[reason]++
+	}
+
+	reasons := make([]string, 0, len(counts))
+	for reason, count := range counts {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("0/%d nodes available: %s", len(nodes), strings.Join(reasons, "; "))
+}
+
+// sortedByID returns nodes ordered by node ID so ties break the same way on every call
+func sortedByID(nodes []*pb.Node) []*pb.Node {
+	sorted := make([]*pb.Node, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].NodeId < sorted[j].NodeId })
+	return sorted
+}
+
+// leastLoaded returns the node with the fewest active tasks, breaking ties by node ID
+func leastLoaded(nodes []*pb.Node) *pb.Node {
+	var best *pb.Node
+	for _, node := range sortedByID(nodes) {
+		if best == nil || node.ActiveTasks < best.ActiveTasks {
+			best = node
+		}
+	}
+	return best
+}
@@ -0,0 +1,218 @@
+package scheduler
+
+import (
+	"testing"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+func TestNew_Strategies(t *testing.T) {
+	for _, strategy := range []string{"", StrategyLeastLoaded, StrategyBinPacking, StrategySpread, StrategyRandomOfTwo} {
+		s, err := New(strategy)
+		if err != nil {
+			t.Fatalf("New(%q) returned error: %v", strategy, err)
+		}
+		if strategy != "" && s.Name() != strategy {
+			t.Errorf("New(%q) returned scheduler named %q", strategy, s.Name())
+		}
+	}
+
+	if _, err := New("round_robin"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestLeastLoaded_Select(t *testing.T) {
+	nodes := []*pb.Node{
+		{NodeId: "node-c", ActiveTasks: 1},
+		{NodeId: "node-b", ActiveTasks: 3},
+		{NodeId: "node-a", ActiveTasks: 1},
+	}
+
+	got := LeastLoaded{}.Select(&pb.Task{}, nodes)
+	if got == nil || got.NodeId != "node-a" {
+		t.Errorf("expected node-a (ties broken by ID), got %v", got)
+	}
+	if got := (LeastLoaded{}).Select(&pb.Task{}, nil); got != nil {
+		t.Errorf("expected nil without nodes, got %v", got)
+	}
+}
+
+func TestBinPacking_Select(t *testing.T) {
+	nodes := []*pb.Node{
+		{NodeId: "idle", CpuUsage: 5, MemoryUsage: 10},
+		{NodeId: "busy", CpuUsage: 70, MemoryUsage: 60},
+		{NodeId: "full", CpuUsage: 95, MemoryUsage: 20},
+	}
+
+	got := BinPacking{}.Select(&pb.Task{}, nodes)
+	if got == nil || got.NodeId != "busy" {
+		t.Errorf("expected busy, got %v", got)
+	}
+
+	if got := (BinPacking{}).Select(&pb.Task{}, nodes[2:]); got != nil {
+		t.Errorf("expected no node with headroom, got %v", got)
+	}
+}
+
+func TestSpread_Select(t *testing.T) {
+	nodes := []*pb.Node{
+		{NodeId: "aws-1", CloudProvider: "aws", ActiveTasks: 1},
+		{NodeId: "aws-2", CloudProvider: "aws", ActiveTasks: 0},
+		{NodeId: "gcp-1", CloudProvider: "gcp", ActiveTasks: 2},
+		{NodeId: "gcp-2", CloudProvider: "gcp", ActiveTasks: 1},
+	}
+
+	got := Spread{}.Select(&pb.Task{}, nodes)
+	if got == nil || got.NodeId != "aws-2" {
+		t.Errorf("expected aws-2 in the least loaded cloud, got %v", got)
+	}
+}
+
+func TestRandomOfTwo_Select(t *testing.T) {
+	nodes := []*pb.Node{
+		{NodeId: "node-a", ActiveTasks: 5},
+		{NodeId: "node-b", ActiveTasks: 0},
+		{NodeId: "node-c", ActiveTasks: 5},
+	}
+
+	s := NewRandomOfTwo()
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		counts[s.Select(&pb.Task{}, nodes).NodeId]++
+	}
+
+	// node-b wins whenever it is sampled, which is two thirds of the time
+	if counts["node-b"] < 40 {
+		t.Errorf("expected node-b to win most samples, got %v", counts)
+	}
+}
+
+func TestSchedulers_SkipNodesWithoutResources(t *testing.T) {
+	task := &pb.Task{Resources: &pb.Resources{MemoryMb: 64 * 1024, Accelerators: 1, AcceleratorType: "nvidia-a100"}}
+	nodes := []*pb.Node{
+		{NodeId: "small", Allocatable: &pb.Resources{MemoryMb: 8 * 1024, Accelerators: 1, AcceleratorType: "nvidia-a100"}},
+		{NodeId: "wrong-gpu", Allocatable: &pb.Resources{MemoryMb: 128 * 1024, Accelerators: 4, AcceleratorType: "nvidia-t4"}},
+		{
+			NodeId:      "big",
+			ActiveTasks: 5,
+			Allocatable: &pb.Resources{MemoryMb: 256 * 1024, Accelerators: 8, AcceleratorType: "nvidia-a100"},
+			Allocated:   &pb.Resources{MemoryMb: 128 * 1024, Accelerators: 4},
+		},
+	}
+
+	for _, s := range []Scheduler{LeastLoaded{}, BinPacking{}, Spread{}, NewRandomOfTwo()} {
+		if got := s.Select(task, nodes); got == nil || got.NodeId != "big" {
+			t.Errorf("%s: expected big, got %v", s.Name(), got)
+		}
+		if got := s.Select(task, nodes[:2]); got != nil {
+			t.Errorf("%s: expected no node to fit, got %s", s.Name(), got.NodeId)
+		}
+	}
+}
+
+func TestSchedulers_HonourAffinity(t *testing.T) {
+	nodes := []*pb.Node{
+		{NodeId: "aws-1", CloudProvider: "aws", Region: "us-east-1"},
+		{NodeId: "gcp-1", CloudProvider: "gcp", Region: "us-central1", ActiveTasks: 4},
+		{NodeId: "gcp-2", CloudProvider: "gcp", Region: "europe-west4", Labels: map[string]string{"bucket": "gs://weights"}, ActiveTasks: 9},
+	}
+	task := &pb.Task{Affinity: &pb.Affinity{
+		Required: []*pb.LabelExpression{{Key: "cloud", Operator: pb.LabelOperator_IN, Values: []string{"gcp"}}},
+		Preferred: []*pb.WeightedLabelExpression{
+			{Expression: &pb.LabelExpression{Key: "bucket", Operator: pb.LabelOperator_EXISTS}, Weight: 10},
+		},
+	}}
+
+	// The preferred node wins even though it is the busiest
+	for _, s := range []Scheduler{LeastLoaded{}, Spread{}, NewRandomOfTwo()} {
+		if got := s.Select(task, nodes); got == nil || got.NodeId != "gcp-2" {
+			t.Errorf("%s: expected gcp-2, got %v", s.Name(), got)
+		}
+	}
+
+	task.Affinity.RequiredAnti = []*pb.LabelExpression{{Key: "region", Operator: pb.LabelOperator_IN, Values: []string{"europe-west4"}}}
+	if got := (LeastLoaded{}).Select(task, nodes); got == nil || got.NodeId != "gcp-1" {
+		t.Errorf("expected gcp-1 once europe-west4 is excluded, got %v", got)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	nodes := []*pb.Node{
+		{NodeId: "aws-1", CloudProvider: "aws"},
+		{NodeId: "aws-2", CloudProvider: "aws"},
+		{NodeId: "gcp-1", CloudProvider: "gcp", Allocatable: &pb.Resources{MemoryMb: 1024}},
+	}
+	task := &pb.Task{
+		Resources: &pb.Resources{MemoryMb: 4096},
+		Affinity: &pb.Affinity{
+			Required: []*pb.LabelExpression{{Key: "cloud", Operator: pb.LabelOperator_IN, Values: []string{"gcp"}}},
+		},
+	}
+
+	want := "0/3 nodes available: 1 insufficient resources; 2 does not match cloud in [gcp]"
+	if got := Explain(task, nodes); got != want {
+		t.Errorf("Explain() = %q, want %q", got, want)
+	}
+
+	nodes[2].Allocatable.MemoryMb = 8192
+	if got := Explain(task, nodes); got != "" {
+		t.Errorf("expected no explanation once gcp-1 fits, got %q", got)
+	}
+}
+
+func TestPlanPreemption(t *testing.T) {
+	capacity := &pb.Resources{CpuCores: 8}
+	nodes := []*pb.Node{
+		{NodeId: "node-a", Capacity: capacity, Allocatable: capacity, Allocated: &pb.Resources{CpuCores: 8}},
+		{NodeId: "node-b", Capacity: capacity, Allocatable: capacity, Allocated: &pb.Resources{CpuCores: 8}},
+	}
+	running := func(id, nodeID string, priority int32, startedAt int64, cpu float64) *pb.Task {
+		return &pb.Task{
+			TaskId:         id,
+			Status:         pb.TaskStatus_RUNNING,
+			AssignedNodeId: nodeID,
+			Priority:       priority,
+			StartedAt:      startedAt,
+			Resources:      &pb.Resources{CpuCores: cpu},
+		}
+	}
+	leased := []*pb.Task{
+		running("a-normal", "node-a", 0, 100, 8),
+		running("b-low-old", "node-b", -100, 100, 4),
+		running("b-low-new", "node-b", -100, 200, 4),
+	}
+	task := &pb.Task{TaskId: "urgent", Priority: 1000, Resources: &pb.Resources{CpuCores: 4}}
+
+	plan := PlanPreemption(task, nodes, leased)
+	if plan == nil || plan.Node.NodeId != "node-b" {
+		t.Fatalf("expected to preempt on node-b, got %v", plan)
+	}
+	if len(plan.Victims) != 1 || plan.Victims[0].TaskId != "b-low-new" {
+		t.Errorf("expected only the most recently started low-priority task, got %v", plan.Victims)
+	}
+
+	// Equal or higher priority work is never evicted
+	if plan := PlanPreemption(&pb.Task{Priority: -100, Resources: task.Resources}, nodes, leased); plan != nil {
+		t.Errorf("expected no plan for a low-priority task, got %v", plan)
+	}
+
+	// Evicting everything below the task's priority is still not enough
+	huge := &pb.Task{Priority: 1000, Resources: &pb.Resources{CpuCores: 16}}
+	if plan := PlanPreemption(huge, nodes, leased); plan != nil {
+		t.Errorf("expected no plan for a task larger than any node, got %v", plan)
+	}
+
+	// Required affinity limits the nodes preemption may use
+	pinned := &pb.Task{
+		Priority:  1000,
+		Resources: task.Resources,
+		Affinity: &pb.Affinity{
+			RequiredAnti: []*pb.LabelExpression{{Key: "region", Operator: pb.LabelOperator_IN, Values: []string{"us-east-1"}}},
+		},
+	}
+	nodes[1].Region = "us-east-1"
+	if plan := PlanPreemption(pinned, nodes, leased); plan == nil || plan.Node.NodeId != "node-a" {
+		t.Errorf("expected to preempt on node-a, got %v", plan)
+	}
+}
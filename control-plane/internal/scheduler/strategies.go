@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	pb "ml-raft-control-plane/pkg/proto"
+)
+
+// LeastLoaded places tasks on the node with the fewest active tasks
+type LeastLoaded struct{}
+
+// Name returns the strategy name
+func (LeastLoaded) Name() string { return StrategyLeastLoaded }
+
+// Select returns the node with the fewest active tasks
+func (LeastLoaded) Select(task *pb.Task, nodes []*pb.Node) *pb.Node {
+	return leastLoaded(feasible(task, nodes))
+}
+
+// maxUtilization is the CPU or memory usage, in percent, above which
+// bin-packing stops placing tasks on a node
+const maxUtilization = 90.0
+
+// BinPacking fills the busiest node that still has CPU and memory headroom,
+// leaving other nodes idle. A node is as busy as the higher of its usage
+// reported in the last heartbeat and the share of its allocatable resources
+// already requested by tasks.
+type BinPacking struct{}
+
+// Name returns the strategy name
+func (BinPacking) Name() string { return StrategyBinPacking }
+
+// Select returns the most utilized node below maxUtilization
+func (BinPacking) Select(task *pb.Task, nodes []*pb.Node) *pb.Node {
+	var best *pb.Node
+	for _, node := range sortedByID(feasible(task, nodes)) {
+		if node.CpuUsage >= maxUtilization || node.MemoryUsage >= maxUtilization {
+			continue
+		}
+		if best == nil || utilization(node) > utilization(best) {
+			best = node
+		}
+	}
+	return best
+}
+
+// utilization combines CPU and memory usage into a single packing score
+func utilization(node *pb.Node) float64 {
+	cpu, memory := node.CpuUsage, node.MemoryUsage
+	if allocatable := node.Allocatable; allocatable != nil {
+		if allocatable.CpuCores > 0 {
+			cpu = max(cpu, 100*node.Allocated.GetCpuCores()/allocatable.CpuCores)
+		}
+		if allocatable.MemoryMb > 0 {
+			memory = max(memory, 100*float64(node.Allocated.GetMemoryMb())/float64(allocatable.MemoryMb))
+		}
+	}
+	return cpu + memory
+}
+
+// Spread places tasks in the cloud running the fewest tasks, then on the
+// least-loaded node within that cloud
+type Spread struct{}
+
+// Name returns the strategy name
+func (Spread) Name() string { return StrategySpread }
+
+// Select returns the least-loaded node of the least-loaded cloud
+func (Spread) Select(task *pb.Task, nodes []*pb.Node) *pb.Node {
+	byCloud := make(map[string][]*pb.Node)
+	cloudLoad := make(map[string]int32)
+	for _, node := range feasible(task, nodes) {
+		byCloud[node.CloudProvider] = append(byCloud[node.CloudProvider], node)
+		cloudLoad[node.CloudProvider] += node.ActiveTasks
+	}
+
+	bestCloud, found := "", false
+	for cloud, load := range cloudLoad {
+		if !found || load < cloudLoad[bestCloud] || (load == cloudLoad[bestCloud] && cloud < bestCloud) {
+			bestCloud, found = cloud, true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return leastLoaded(byCloud[bestCloud])
+}
+
+// RandomOfTwo samples two nodes at random and places the task on the less
+// loaded of the pair, avoiding the herding of always picking the global minimum
+type RandomOfTwo struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRandomOfTwo creates a random-of-two scheduler seeded from the clock
+func NewRandomOfTwo() *RandomOfTwo {
+	return &RandomOfTwo{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Name returns the strategy name
+func (*RandomOfTwo) Name() string { return StrategyRandomOfTwo }
+
+// Select returns the less loaded of two randomly chosen nodes
+func (s *RandomOfTwo) Select(task *pb.Task, nodes []*pb.Node) *pb.Node {
+	nodes = feasible(task, nodes)
+	if len(nodes) <= 2 {
+		return leastLoaded(nodes)
+	}
+
+	s.mu.Lock()
+	i := s.rng.Intn(len(nodes))
+	j := s.rng.Intn(len(nodes) - 1)
+	s.mu.Unlock()
+	if j >= i {
+		j++
+	}
+
+	return leastLoaded([]*pb.Node{nodes[i], nodes[j]})
+}
@@ -21,14 +21,70 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type LabelOperator int32
+
+const (
+	LabelOperator_IN             LabelOperator = 0
+	LabelOperator_NOT_IN         LabelOperator = 1 // Also matches nodes without the label
+	LabelOperator_EXISTS         LabelOperator = 2
+	LabelOperator_DOES_NOT_EXIST LabelOperator = 3
+)
+
+// Enum value maps for LabelOperator.
+var (
+	LabelOperator_name = map[int32]string{
+		0: "IN",
+		1: "NOT_IN",
+		2: "EXISTS",
+		3: "DOES_NOT_EXIST",
+	}
+	LabelOperator_value = map[string]int32{
+		"IN":             0,
+		"NOT_IN":         1,
+		"EXISTS":         2,
+		"DOES_NOT_EXIST": 3,
+	}
+)
+
+func (x LabelOperator) Enum() *LabelOperator {
+	p := new(LabelOperator)
+	*p = x
+	return p
+}
+
+func (x LabelOperator) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LabelOperator) Descriptor() protoreflect.EnumDescriptor {
+	return file_raft_proto_enumTypes[0].Descriptor()
+}
+
+func (LabelOperator) Type() protoreflect.EnumType {
+	return &file_raft_proto_enumTypes[0]
+}
+
+func (x LabelOperator) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LabelOperator.Descriptor instead.
+func (LabelOperator) EnumDescriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{0}
+}
+
 type TaskStatus int32
 
 const (
-	TaskStatus_PENDING   TaskStatus = 0
-	TaskStatus_ASSIGNED  TaskStatus = 1
-	TaskStatus_RUNNING   TaskStatus = 2
-	TaskStatus_COMPLETED TaskStatus = 3
-	TaskStatus_FAILED    TaskStatus = 4
+	TaskStatus_PENDING     TaskStatus = 0
+	TaskStatus_ASSIGNED    TaskStatus = 1
+	TaskStatus_RUNNING     TaskStatus = 2
+	TaskStatus_COMPLETED   TaskStatus = 3
+	TaskStatus_FAILED      TaskStatus = 4
+	TaskStatus_DEAD_LETTER TaskStatus = 5 // Retries exhausted or error not retryable
+	TaskStatus_CANCELLED   TaskStatus = 6
+	TaskStatus_BLOCKED     TaskStatus = 7 // Waiting for the tasks in depends_on to complete
+	TaskStatus_CANCELLING  TaskStatus = 8 // Cancelled while assigned; CANCELLED once the agent acknowledges
 )
 
 // Enum value maps for TaskStatus.
@@ -39,13 +95,21 @@ var (
 		2: "RUNNING",
 		3: "COMPLETED",
 		4: "FAILED",
+		5: "DEAD_LETTER",
+		6: "CANCELLED",
+		7: "BLOCKED",
+		8: "CANCELLING",
 	}
 	TaskStatus_value = map[string]int32{
-		"PENDING":   0,
-		"ASSIGNED":  1,
-		"RUNNING":   2,
-		"COMPLETED": 3,
-		"FAILED":    4,
+		"PENDING":     0,
+		"ASSIGNED":    1,
+		"RUNNING":     2,
+		"COMPLETED":   3,
+		"FAILED":      4,
+		"DEAD_LETTER": 5,
+		"CANCELLED":   6,
+		"BLOCKED":     7,
+		"CANCELLING":  8,
 	}
 )
 
@@ -60,11 +124,11 @@ func (x TaskStatus) String() string {
 }
 
 func (TaskStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_raft_proto_enumTypes[0].Descriptor()
+	return file_raft_proto_enumTypes[1].Descriptor()
 }
 
 func (TaskStatus) Type() protoreflect.EnumType {
-	return &file_raft_proto_enumTypes[0]
+	return &file_raft_proto_enumTypes[1]
 }
 
 func (x TaskStatus) Number() protoreflect.EnumNumber {
@@ -73,7 +137,62 @@ func (x TaskStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use TaskStatus.Descriptor instead.
 func (TaskStatus) EnumDescriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{0}
+	return file_raft_proto_rawDescGZIP(), []int{1}
+}
+
+type JobStatus int32
+
+const (
+	JobStatus_JOB_PENDING   JobStatus = 0 // No task has started
+	JobStatus_JOB_RUNNING   JobStatus = 1
+	JobStatus_JOB_SUCCEEDED JobStatus = 2 // Every task COMPLETED
+	JobStatus_JOB_FAILED    JobStatus = 3 // A task failed for good
+	JobStatus_JOB_CANCELLED JobStatus = 4
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0: "JOB_PENDING",
+		1: "JOB_RUNNING",
+		2: "JOB_SUCCEEDED",
+		3: "JOB_FAILED",
+		4: "JOB_CANCELLED",
+	}
+	JobStatus_value = map[string]int32{
+		"JOB_PENDING":   0,
+		"JOB_RUNNING":   1,
+		"JOB_SUCCEEDED": 2,
+		"JOB_FAILED":    3,
+		"JOB_CANCELLED": 4,
+	}
+)
+
+func (x JobStatus) Enum() *JobStatus {
+	p := new(JobStatus)
+	*p = x
+	return p
+}
+
+func (x JobStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_raft_proto_enumTypes[2].Descriptor()
+}
+
+func (JobStatus) Type() protoreflect.EnumType {
+	return &file_raft_proto_enumTypes[2]
+}
+
+func (x JobStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobStatus.Descriptor instead.
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{2}
 }
 
 type NodeStatus int32
@@ -109,11 +228,11 @@ func (x NodeStatus) String() string {
 }
 
 func (NodeStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_raft_proto_enumTypes[1].Descriptor()
+	return file_raft_proto_enumTypes[3].Descriptor()
 }
 
 func (NodeStatus) Type() protoreflect.EnumType {
-	return &file_raft_proto_enumTypes[1]
+	return &file_raft_proto_enumTypes[3]
 }
 
 func (x NodeStatus) Number() protoreflect.EnumNumber {
@@ -122,23 +241,40 @@ func (x NodeStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use NodeStatus.Descriptor instead.
 func (NodeStatus) EnumDescriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{1}
+	return file_raft_proto_rawDescGZIP(), []int{3}
 }
 
 // Task represents a computational task in the system
 type Task struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TaskId         string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	TaskType       string                 `protobuf:"bytes,2,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"` // "matmul", "ingestion", "processing", etc.
-	Status         TaskStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=raftpb.TaskStatus" json:"status,omitempty"`
-	AssignedNodeId string                 `protobuf:"bytes,4,opt,name=assigned_node_id,json=assignedNodeId,proto3" json:"assigned_node_id,omitempty"`
-	TaskData       []byte                 `protobuf:"bytes,5,opt,name=task_data,json=taskData,proto3" json:"task_data,omitempty"` // JSON-encoded task parameters
-	CreatedAt      int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	StartedAt      int64                  `protobuf:"varint,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	CompletedAt    int64                  `protobuf:"varint,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
-	ResultData     string                 `protobuf:"bytes,9,opt,name=result_data,json=resultData,proto3" json:"result_data,omitempty"` // JSON-encoded results
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TaskId           string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TaskType         string                 `protobuf:"bytes,2,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"` // "matmul", "ingestion", "processing", etc.
+	Status           TaskStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=raftpb.TaskStatus" json:"status,omitempty"`
+	AssignedNodeId   string                 `protobuf:"bytes,4,opt,name=assigned_node_id,json=assignedNodeId,proto3" json:"assigned_node_id,omitempty"`
+	TaskData         []byte                 `protobuf:"bytes,5,opt,name=task_data,json=taskData,proto3" json:"task_data,omitempty"` // JSON-encoded task parameters
+	CreatedAt        int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt        int64                  `protobuf:"varint,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt      int64                  `protobuf:"varint,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	ResultData       string                 `protobuf:"bytes,9,opt,name=result_data,json=resultData,proto3" json:"result_data,omitempty"`                      // JSON-encoded results
+	Attempt          int32                  `protobuf:"varint,10,opt,name=attempt,proto3" json:"attempt,omitempty"`                                            // Number of times the task has been assigned
+	LastFailure      *TaskFailure           `protobuf:"bytes,11,opt,name=last_failure,json=lastFailure,proto3" json:"last_failure,omitempty"`                  // Set when the most recent attempt failed
+	RetryPolicy      *RetryPolicy           `protobuf:"bytes,12,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`                  // Unset means a FAILED task is never retried
+	DeadLetterReason string                 `protobuf:"bytes,13,opt,name=dead_letter_reason,json=deadLetterReason,proto3" json:"dead_letter_reason,omitempty"` // Why the task moved to DEAD_LETTER
+	LeaseExpiresAt   int64                  `protobuf:"varint,14,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"`      // When the current assignment lapses unless renewed
+	FencingToken     uint64                 `protobuf:"varint,15,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`              // Identifies the current assignment; results must carry it
+	PlacedNodeId     string                 `protobuf:"bytes,16,opt,name=placed_node_id,json=placedNodeId,proto3" json:"placed_node_id,omitempty"`             // Node the scheduler chose while the task is PENDING
+	PlacedAt         int64                  `protobuf:"varint,17,opt,name=placed_at,json=placedAt,proto3" json:"placed_at,omitempty"`
+	PlacedBy         string                 `protobuf:"bytes,18,opt,name=placed_by,json=placedBy,proto3" json:"placed_by,omitempty"`                   // Scheduler strategy that chose placed_node_id
+	Resources        *Resources             `protobuf:"bytes,19,opt,name=resources,proto3" json:"resources,omitempty"`                                 // Requested resources; unset requests nothing
+	Affinity         *Affinity              `protobuf:"bytes,20,opt,name=affinity,proto3" json:"affinity,omitempty"`                                   // Placement constraints on node labels
+	Priority         int32                  `protobuf:"varint,21,opt,name=priority,proto3" json:"priority,omitempty"`                                  // Higher priorities are dispatched first
+	PriorityClass    string                 `protobuf:"bytes,22,opt,name=priority_class,json=priorityClass,proto3" json:"priority_class,omitempty"`    // Named class the priority came from, if any
+	JobId            string                 `protobuf:"bytes,23,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`                            // Job the task belongs to, if any
+	DependsOn        []string               `protobuf:"bytes,24,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`                // Tasks that must COMPLETE before this one is PENDING
+	Progress         *TaskProgress          `protobuf:"bytes,25,opt,name=progress,proto3" json:"progress,omitempty"`                                   // Latest progress of the current attempt
+	LastPreemption   *TaskFailure           `protobuf:"bytes,26,opt,name=last_preemption,json=lastPreemption,proto3" json:"last_preemption,omitempty"` // Set when the task was last evicted for higher-priority work
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Task) Reset() {
@@ -234,138 +370,151 @@ func (x *Task) GetResultData() string {
 	return ""
 }
 
-// Node represents a worker node in the cluster
-type Node struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
-	CloudProvider string                 `protobuf:"bytes,3,opt,name=cloud_provider,json=cloudProvider,proto3" json:"cloud_provider,omitempty"`
-	Region        string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
-	Status        NodeStatus             `protobuf:"varint,5,opt,name=status,proto3,enum=raftpb.NodeStatus" json:"status,omitempty"`
-	LastHeartbeat int64                  `protobuf:"varint,6,opt,name=last_heartbeat,json=lastHeartbeat,proto3" json:"last_heartbeat,omitempty"`
-	CpuUsage      float64                `protobuf:"fixed64,7,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
-	MemoryUsage   float64                `protobuf:"fixed64,8,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
-	ActiveTasks   int32                  `protobuf:"varint,9,opt,name=active_tasks,json=activeTasks,proto3" json:"active_tasks,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Task) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
 }
 
-func (x *Node) Reset() {
-	*x = Node{}
-	mi := &file_raft_proto_msgTypes[1]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Task) GetLastFailure() *TaskFailure {
+	if x != nil {
+		return x.LastFailure
+	}
+	return nil
 }
 
-func (x *Node) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Task) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
 }
 
-func (*Node) ProtoMessage() {}
+func (x *Task) GetDeadLetterReason() string {
+	if x != nil {
+		return x.DeadLetterReason
+	}
+	return ""
+}
 
-func (x *Node) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[1]
+func (x *Task) GetLeaseExpiresAt() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.LeaseExpiresAt
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use Node.ProtoReflect.Descriptor instead.
-func (*Node) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{1}
+func (x *Task) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
 }
 
-func (x *Node) GetNodeId() string {
+func (x *Task) GetPlacedNodeId() string {
 	if x != nil {
-		return x.NodeId
+		return x.PlacedNodeId
 	}
 	return ""
 }
 
-func (x *Node) GetAddress() string {
+func (x *Task) GetPlacedAt() int64 {
 	if x != nil {
-		return x.Address
+		return x.PlacedAt
 	}
-	return ""
+	return 0
 }
 
-func (x *Node) GetCloudProvider() string {
+func (x *Task) GetPlacedBy() string {
 	if x != nil {
-		return x.CloudProvider
+		return x.PlacedBy
 	}
 	return ""
 }
 
-func (x *Node) GetRegion() string {
+func (x *Task) GetResources() *Resources {
 	if x != nil {
-		return x.Region
+		return x.Resources
 	}
-	return ""
+	return nil
 }
 
-func (x *Node) GetStatus() NodeStatus {
+func (x *Task) GetAffinity() *Affinity {
 	if x != nil {
-		return x.Status
+		return x.Affinity
 	}
-	return NodeStatus_HEALTHY
+	return nil
 }
 
-func (x *Node) GetLastHeartbeat() int64 {
+func (x *Task) GetPriority() int32 {
 	if x != nil {
-		return x.LastHeartbeat
+		return x.Priority
 	}
 	return 0
 }
 
-func (x *Node) GetCpuUsage() float64 {
+func (x *Task) GetPriorityClass() string {
 	if x != nil {
-		return x.CpuUsage
+		return x.PriorityClass
 	}
-	return 0
+	return ""
 }
 
-func (x *Node) GetMemoryUsage() float64 {
+func (x *Task) GetJobId() string {
 	if x != nil {
-		return x.MemoryUsage
+		return x.JobId
 	}
-	return 0
+	return ""
 }
 
-func (x *Node) GetActiveTasks() int32 {
+func (x *Task) GetDependsOn() []string {
 	if x != nil {
-		return x.ActiveTasks
+		return x.DependsOn
 	}
-	return 0
+	return nil
 }
 
-type SubmitTaskRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskType      string                 `protobuf:"bytes,1,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"`
-	TaskData      []byte                 `protobuf:"bytes,2,opt,name=task_data,json=taskData,proto3" json:"task_data,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *Task) GetProgress() *TaskProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
 }
 
-func (x *SubmitTaskRequest) Reset() {
-	*x = SubmitTaskRequest{}
-	mi := &file_raft_proto_msgTypes[2]
+func (x *Task) GetLastPreemption() *TaskFailure {
+	if x != nil {
+		return x.LastPreemption
+	}
+	return nil
+}
+
+// TaskProgress is what an agent last reported about a running task
+type TaskProgress struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PercentComplete float64                `protobuf:"fixed64,1,opt,name=percent_complete,json=percentComplete,proto3" json:"percent_complete,omitempty"`                                    // 0 to 100
+	Stage           string                 `protobuf:"bytes,2,opt,name=stage,proto3" json:"stage,omitempty"`                                                                                 // Free-form, e.g. "epoch 3/10"
+	Metrics         map[string]float64     `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // e.g. "loss", "samples_per_sec"
+	ReportedAt      int64                  `protobuf:"varint,4,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TaskProgress) Reset() {
+	*x = TaskProgress{}
+	mi := &file_raft_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubmitTaskRequest) String() string {
+func (x *TaskProgress) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubmitTaskRequest) ProtoMessage() {}
+func (*TaskProgress) ProtoMessage() {}
 
-func (x *SubmitTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[2]
+func (x *TaskProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -376,49 +525,66 @@ func (x *SubmitTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubmitTaskRequest.ProtoReflect.Descriptor instead.
-func (*SubmitTaskRequest) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use TaskProgress.ProtoReflect.Descriptor instead.
+func (*TaskProgress) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *SubmitTaskRequest) GetTaskType() string {
+func (x *TaskProgress) GetPercentComplete() float64 {
 	if x != nil {
-		return x.TaskType
+		return x.PercentComplete
+	}
+	return 0
+}
+
+func (x *TaskProgress) GetStage() string {
+	if x != nil {
+		return x.Stage
 	}
 	return ""
 }
 
-func (x *SubmitTaskRequest) GetTaskData() []byte {
+func (x *TaskProgress) GetMetrics() map[string]float64 {
 	if x != nil {
-		return x.TaskData
+		return x.Metrics
 	}
 	return nil
 }
 
-type SubmitTaskResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+func (x *TaskProgress) GetReportedAt() int64 {
+	if x != nil {
+		return x.ReportedAt
+	}
+	return 0
+}
+
+// Affinity constrains which nodes a task may be placed on. Node labels
+// include the built-in keys "cloud" and "region".
+type Affinity struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Required      []*LabelExpression         `protobuf:"bytes,1,rep,name=required,proto3" json:"required,omitempty"`                                // Node must match every expression
+	RequiredAnti  []*LabelExpression         `protobuf:"bytes,2,rep,name=required_anti,json=requiredAnti,proto3" json:"required_anti,omitempty"`    // Node must match none of the expressions
+	Preferred     []*WeightedLabelExpression `protobuf:"bytes,3,rep,name=preferred,proto3" json:"preferred,omitempty"`                              // Nodes matching more weight are chosen first
+	PreferredAnti []*WeightedLabelExpression `protobuf:"bytes,4,rep,name=preferred_anti,json=preferredAnti,proto3" json:"preferred_anti,omitempty"` // Matching weight counts against a node
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SubmitTaskResponse) Reset() {
-	*x = SubmitTaskResponse{}
-	mi := &file_raft_proto_msgTypes[3]
+func (x *Affinity) Reset() {
+	*x = Affinity{}
+	mi := &file_raft_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubmitTaskResponse) String() string {
+func (x *Affinity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubmitTaskResponse) ProtoMessage() {}
+func (*Affinity) ProtoMessage() {}
 
-func (x *SubmitTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[3]
+func (x *Affinity) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,54 +595,64 @@ func (x *SubmitTaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubmitTaskResponse.ProtoReflect.Descriptor instead.
-func (*SubmitTaskResponse) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use Affinity.ProtoReflect.Descriptor instead.
+func (*Affinity) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *SubmitTaskResponse) GetTaskId() string {
+func (x *Affinity) GetRequired() []*LabelExpression {
 	if x != nil {
-		return x.TaskId
+		return x.Required
 	}
-	return ""
+	return nil
 }
 
-func (x *SubmitTaskResponse) GetSuccess() bool {
+func (x *Affinity) GetRequiredAnti() []*LabelExpression {
 	if x != nil {
-		return x.Success
+		return x.RequiredAnti
 	}
-	return false
+	return nil
 }
 
-func (x *SubmitTaskResponse) GetErrorMessage() string {
+func (x *Affinity) GetPreferred() []*WeightedLabelExpression {
 	if x != nil {
-		return x.ErrorMessage
+		return x.Preferred
 	}
-	return ""
+	return nil
 }
 
-type GetTaskRequest struct {
+func (x *Affinity) GetPreferredAnti() []*WeightedLabelExpression {
+	if x != nil {
+		return x.PreferredAnti
+	}
+	return nil
+}
+
+// LabelExpression matches a node label against a set of values
+type LabelExpression struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Operator      LabelOperator          `protobuf:"varint,2,opt,name=operator,proto3,enum=raftpb.LabelOperator" json:"operator,omitempty"`
+	Values        []string               `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"` // Required for IN and NOT_IN, empty otherwise
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTaskRequest) Reset() {
-	*x = GetTaskRequest{}
-	mi := &file_raft_proto_msgTypes[4]
+func (x *LabelExpression) Reset() {
+	*x = LabelExpression{}
+	mi := &file_raft_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTaskRequest) String() string {
+func (x *LabelExpression) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTaskRequest) ProtoMessage() {}
+func (*LabelExpression) ProtoMessage() {}
 
-func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[4]
+func (x *LabelExpression) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -487,41 +663,56 @@ func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
-func (*GetTaskRequest) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use LabelExpression.ProtoReflect.Descriptor instead.
+func (*LabelExpression) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *GetTaskRequest) GetTaskId() string {
+func (x *LabelExpression) GetKey() string {
 	if x != nil {
-		return x.TaskId
+		return x.Key
 	}
 	return ""
 }
 
-type GetTaskResponse struct {
+func (x *LabelExpression) GetOperator() LabelOperator {
+	if x != nil {
+		return x.Operator
+	}
+	return LabelOperator_IN
+}
+
+func (x *LabelExpression) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// WeightedLabelExpression is a soft placement preference
+type WeightedLabelExpression struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
-	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	Expression    *LabelExpression       `protobuf:"bytes,1,opt,name=expression,proto3" json:"expression,omitempty"`
+	Weight        int32                  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"` // Must be positive
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTaskResponse) Reset() {
-	*x = GetTaskResponse{}
-	mi := &file_raft_proto_msgTypes[5]
+func (x *WeightedLabelExpression) Reset() {
+	*x = WeightedLabelExpression{}
+	mi := &file_raft_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTaskResponse) String() string {
+func (x *WeightedLabelExpression) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTaskResponse) ProtoMessage() {}
+func (*WeightedLabelExpression) ProtoMessage() {}
 
-func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[5]
+func (x *WeightedLabelExpression) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,48 +723,2080 @@ func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTaskResponse.ProtoReflect.Descriptor instead.
-func (*GetTaskResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use WeightedLabelExpression.ProtoReflect.Descriptor instead.
+func (*WeightedLabelExpression) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WeightedLabelExpression) GetExpression() *LabelExpression {
+	if x != nil {
+		return x.Expression
+	}
+	return nil
+}
+
+func (x *WeightedLabelExpression) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// Resources describes amounts of CPU, memory and accelerators
+type Resources struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CpuCores        float64                `protobuf:"fixed64,1,opt,name=cpu_cores,json=cpuCores,proto3" json:"cpu_cores,omitempty"`
+	MemoryMb        int64                  `protobuf:"varint,2,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	Accelerators    int32                  `protobuf:"varint,3,opt,name=accelerators,proto3" json:"accelerators,omitempty"`
+	AcceleratorType string                 `protobuf:"bytes,4,opt,name=accelerator_type,json=acceleratorType,proto3" json:"accelerator_type,omitempty"` // e.g. "nvidia-a100"; empty in a request matches any type
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Resources) Reset() {
+	*x = Resources{}
+	mi := &file_raft_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resources) ProtoMessage() {}
+
+func (x *Resources) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resources.ProtoReflect.Descriptor instead.
+func (*Resources) Descriptor() ([]byte, []int) {
 	return file_raft_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetTaskResponse) GetTask() *Task {
+func (x *Resources) GetCpuCores() float64 {
+	if x != nil {
+		return x.CpuCores
+	}
+	return 0
+}
+
+func (x *Resources) GetMemoryMb() int64 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *Resources) GetAccelerators() int32 {
+	if x != nil {
+		return x.Accelerators
+	}
+	return 0
+}
+
+func (x *Resources) GetAcceleratorType() string {
+	if x != nil {
+		return x.AcceleratorType
+	}
+	return ""
+}
+
+// TaskLease identifies an assignment held by a node
+type TaskLease struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FencingToken  uint64                 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskLease) Reset() {
+	*x = TaskLease{}
+	mi := &file_raft_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskLease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskLease) ProtoMessage() {}
+
+func (x *TaskLease) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskLease.ProtoReflect.Descriptor instead.
+func (*TaskLease) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaskLease) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskLease) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+// RetryPolicy controls automatic re-queueing of FAILED tasks
+type RetryPolicy struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	MaxAttempts       int32                  `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"` // Total attempts including the first
+	InitialBackoffMs  int64                  `protobuf:"varint,2,opt,name=initial_backoff_ms,json=initialBackoffMs,proto3" json:"initial_backoff_ms,omitempty"`
+	BackoffMultiplier float64                `protobuf:"fixed64,3,opt,name=backoff_multiplier,json=backoffMultiplier,proto3" json:"backoff_multiplier,omitempty"` // Defaults to 2 when unset
+	MaxBackoffMs      int64                  `protobuf:"varint,4,opt,name=max_backoff_ms,json=maxBackoffMs,proto3" json:"max_backoff_ms,omitempty"`               // 0 means no cap
+	RetryableErrors   []string               `protobuf:"bytes,5,rep,name=retryable_errors,json=retryableErrors,proto3" json:"retryable_errors,omitempty"`         // Error classes to retry; empty retries all
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_raft_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetInitialBackoffMs() int64 {
+	if x != nil {
+		return x.InitialBackoffMs
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetBackoffMultiplier() float64 {
+	if x != nil {
+		return x.BackoffMultiplier
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaxBackoffMs() int64 {
+	if x != nil {
+		return x.MaxBackoffMs
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetRetryableErrors() []string {
+	if x != nil {
+		return x.RetryableErrors
+	}
+	return nil
+}
+
+// TaskFailure records why and where a task attempt failed
+type TaskFailure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ErrorMessage  string                 `protobuf:"bytes,1,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	FailedAt      int64                  `protobuf:"varint,2,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	NodeId        string                 `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Attempt       int32                  `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	ErrorClass    string                 `protobuf:"bytes,5,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"` // e.g. "PREEMPTED", "OOM", "NETWORK"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskFailure) Reset() {
+	*x = TaskFailure{}
+	mi := &file_raft_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskFailure) ProtoMessage() {}
+
+func (x *TaskFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskFailure.ProtoReflect.Descriptor instead.
+func (*TaskFailure) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TaskFailure) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *TaskFailure) GetFailedAt() int64 {
+	if x != nil {
+		return x.FailedAt
+	}
+	return 0
+}
+
+func (x *TaskFailure) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *TaskFailure) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *TaskFailure) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+// Job groups the tasks of one workload, such as the map and reduce tasks of
+// a matrix multiplication
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Status        JobStatus              `protobuf:"varint,3,opt,name=status,proto3,enum=raftpb.JobStatus" json:"status,omitempty"` // Derived from the status of the job's tasks
+	TaskIds       []string               `protobuf:"bytes,4,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt   int64                  `protobuf:"varint,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"` // When the job reached SUCCEEDED, FAILED or CANCELLED
+	Progress      *JobProgress           `protobuf:"bytes,7,opt,name=progress,proto3" json:"progress,omitempty"`
+	CancelledAt   int64                  `protobuf:"varint,8,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_raft_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Job) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *Job) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_PENDING
+}
+
+func (x *Job) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Job) GetCompletedAt() int64 {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return 0
+}
+
+func (x *Job) GetProgress() *JobProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *Job) GetCancelledAt() int64 {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return 0
+}
+
+// JobProgress counts a job's tasks by status
+type JobProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Pending       int32                  `protobuf:"varint,2,opt,name=pending,proto3" json:"pending,omitempty"` // PENDING, BLOCKED, or FAILED and awaiting a retry
+	Running       int32                  `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"` // ASSIGNED or RUNNING
+	Completed     int32                  `protobuf:"varint,4,opt,name=completed,proto3" json:"completed,omitempty"`
+	Failed        int32                  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"` // DEAD_LETTER, or FAILED without a retry policy
+	Cancelled     int32                  `protobuf:"varint,6,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobProgress) Reset() {
+	*x = JobProgress{}
+	mi := &file_raft_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobProgress) ProtoMessage() {}
+
+func (x *JobProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobProgress.ProtoReflect.Descriptor instead.
+func (*JobProgress) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *JobProgress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *JobProgress) GetPending() int32 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *JobProgress) GetRunning() int32 {
+	if x != nil {
+		return x.Running
+	}
+	return 0
+}
+
+func (x *JobProgress) GetCompleted() int32 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *JobProgress) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *JobProgress) GetCancelled() int32 {
+	if x != nil {
+		return x.Cancelled
+	}
+	return 0
+}
+
+// Node represents a worker node in the cluster
+type Node struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	NodeId              string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Address             string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	CloudProvider       string                 `protobuf:"bytes,3,opt,name=cloud_provider,json=cloudProvider,proto3" json:"cloud_provider,omitempty"`
+	Region              string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
+	Status              NodeStatus             `protobuf:"varint,5,opt,name=status,proto3,enum=raftpb.NodeStatus" json:"status,omitempty"`
+	LastHeartbeat       int64                  `protobuf:"varint,6,opt,name=last_heartbeat,json=lastHeartbeat,proto3" json:"last_heartbeat,omitempty"`
+	CpuUsage            float64                `protobuf:"fixed64,7,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
+	MemoryUsage         float64                `protobuf:"fixed64,8,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
+	ActiveTasks         int32                  `protobuf:"varint,9,opt,name=active_tasks,json=activeTasks,proto3" json:"active_tasks,omitempty"`                                              // ASSIGNED, RUNNING and CANCELLING tasks, counted by the FSM
+	Capacity            *Resources             `protobuf:"bytes,10,opt,name=capacity,proto3" json:"capacity,omitempty"`                                                                       // Total resources of the machine
+	Allocatable         *Resources             `protobuf:"bytes,11,opt,name=allocatable,proto3" json:"allocatable,omitempty"`                                                                 // Portion of capacity tasks may use; unset means unlimited
+	Allocated           *Resources             `protobuf:"bytes,12,opt,name=allocated,proto3" json:"allocated,omitempty"`                                                                     // Sum of requests of the node's ASSIGNED and RUNNING tasks
+	Labels              map[string]string      `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Free-form labels, e.g. "bucket": "gs://training-data"
+	ReportedActiveTasks int32                  `protobuf:"varint,14,opt,name=reported_active_tasks,json=reportedActiveTasks,proto3" json:"reported_active_tasks,omitempty"`                   // Count the agent last reported; for diagnostics only
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_raft_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Node) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *Node) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Node) GetCloudProvider() string {
+	if x != nil {
+		return x.CloudProvider
+	}
+	return ""
+}
+
+func (x *Node) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Node) GetStatus() NodeStatus {
+	if x != nil {
+		return x.Status
+	}
+	return NodeStatus_HEALTHY
+}
+
+func (x *Node) GetLastHeartbeat() int64 {
+	if x != nil {
+		return x.LastHeartbeat
+	}
+	return 0
+}
+
+func (x *Node) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *Node) GetMemoryUsage() float64 {
+	if x != nil {
+		return x.MemoryUsage
+	}
+	return 0
+}
+
+func (x *Node) GetActiveTasks() int32 {
+	if x != nil {
+		return x.ActiveTasks
+	}
+	return 0
+}
+
+func (x *Node) GetCapacity() *Resources {
+	if x != nil {
+		return x.Capacity
+	}
+	return nil
+}
+
+func (x *Node) GetAllocatable() *Resources {
+	if x != nil {
+		return x.Allocatable
+	}
+	return nil
+}
+
+func (x *Node) GetAllocated() *Resources {
+	if x != nil {
+		return x.Allocated
+	}
+	return nil
+}
+
+func (x *Node) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Node) GetReportedActiveTasks() int32 {
+	if x != nil {
+		return x.ReportedActiveTasks
+	}
+	return 0
+}
+
+// ServerInfo describes a control-plane server in the Raft cluster
+type ServerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ServerId      string                 `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"` // Raft server ID (node_id in the config)
+	RaftAddress   string                 `protobuf:"bytes,2,opt,name=raft_address,json=raftAddress,proto3" json:"raft_address,omitempty"`
+	GrpcAddress   string                 `protobuf:"bytes,3,opt,name=grpc_address,json=grpcAddress,proto3" json:"grpc_address,omitempty"`
+	CloudProvider string                 `protobuf:"bytes,4,opt,name=cloud_provider,json=cloudProvider,proto3" json:"cloud_provider,omitempty"`
+	Region        string                 `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	RegisteredAt  int64                  `protobuf:"varint,6,opt,name=registered_at,json=registeredAt,proto3" json:"registered_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfo) Reset() {
+	*x = ServerInfo{}
+	mi := &file_raft_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfo) ProtoMessage() {}
+
+func (x *ServerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfo.ProtoReflect.Descriptor instead.
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ServerInfo) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetRaftAddress() string {
+	if x != nil {
+		return x.RaftAddress
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetGrpcAddress() string {
+	if x != nil {
+		return x.GrpcAddress
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetCloudProvider() string {
+	if x != nil {
+		return x.CloudProvider
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetRegisteredAt() int64 {
+	if x != nil {
+		return x.RegisteredAt
+	}
+	return 0
+}
+
+type SubmitTaskRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TaskType       string                 `protobuf:"bytes,1,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"`
+	TaskData       []byte                 `protobuf:"bytes,2,opt,name=task_data,json=taskData,proto3" json:"task_data,omitempty"`
+	RetryPolicy    *RetryPolicy           `protobuf:"bytes,3,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	Resources      *Resources             `protobuf:"bytes,4,opt,name=resources,proto3" json:"resources,omitempty"`
+	Affinity       *Affinity              `protobuf:"bytes,5,opt,name=affinity,proto3" json:"affinity,omitempty"`
+	Priority       int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`                                   // Explicit priority; mutually exclusive with priority_class
+	PriorityClass  string                 `protobuf:"bytes,7,opt,name=priority_class,json=priorityClass,proto3" json:"priority_class,omitempty"`     // "low", "normal", "high" or "critical"
+	TaskId         string                 `protobuf:"bytes,8,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`                          // Optional; generated when empty
+	DependsOn      []string               `protobuf:"bytes,9,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`                 // Existing tasks, or tasks of the same job
+	IdempotencyKey string                 `protobuf:"bytes,10,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Optional; retries with the same key return the original task. Not allowed in SubmitJob.
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubmitTaskRequest) Reset() {
+	*x = SubmitTaskRequest{}
+	mi := &file_raft_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTaskRequest) ProtoMessage() {}
+
+func (x *SubmitTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTaskRequest.ProtoReflect.Descriptor instead.
+func (*SubmitTaskRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SubmitTaskRequest) GetTaskType() string {
+	if x != nil {
+		return x.TaskType
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetTaskData() []byte {
+	if x != nil {
+		return x.TaskData
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetResources() *Resources {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetAffinity() *Affinity {
+	if x != nil {
+		return x.Affinity
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *SubmitTaskRequest) GetPriorityClass() string {
+	if x != nil {
+		return x.PriorityClass
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type SubmitTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	LeaderAddress string                 `protobuf:"bytes,4,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitTaskResponse) Reset() {
+	*x = SubmitTaskResponse{}
+	mi := &file_raft_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTaskResponse) ProtoMessage() {}
+
+func (x *SubmitTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTaskResponse.ProtoReflect.Descriptor instead.
+func (*SubmitTaskResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SubmitTaskResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *SubmitTaskResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SubmitTaskResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *SubmitTaskResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
+}
+
+// SubmitTasksRequest adds many tasks in a single log entry; either every
+// task is created or none is
+type SubmitTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*SubmitTaskRequest   `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"` // idempotency_key is not allowed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitTasksRequest) Reset() {
+	*x = SubmitTasksRequest{}
+	mi := &file_raft_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTasksRequest) ProtoMessage() {}
+
+func (x *SubmitTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTasksRequest.ProtoReflect.Descriptor instead.
+func (*SubmitTasksRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SubmitTasksRequest) GetTasks() []*SubmitTaskRequest {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type SubmitTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskIds       []string               `protobuf:"bytes,1,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"` // In the order of the request's tasks
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	LeaderAddress string                 `protobuf:"bytes,4,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitTasksResponse) Reset() {
+	*x = SubmitTasksResponse{}
+	mi := &file_raft_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTasksResponse) ProtoMessage() {}
+
+func (x *SubmitTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTasksResponse.ProtoReflect.Descriptor instead.
+func (*SubmitTasksResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SubmitTasksResponse) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+func (x *SubmitTasksResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SubmitTasksResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *SubmitTasksResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_raft_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type GetTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskResponse) Reset() {
+	*x = GetTaskResponse{}
+	mi := &file_raft_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskResponse) ProtoMessage() {}
+
+func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskResponse.ProtoReflect.Descriptor instead.
+func (*GetTaskResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *GetTaskResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+type ListTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StatusFilter  *TaskStatus            `protobuf:"varint,1,opt,name=status_filter,json=statusFilter,proto3,enum=raftpb.TaskStatus,oneof" json:"status_filter,omitempty"` // Optional filter
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                                                // 0 means no limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_raft_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListTasksRequest) GetStatusFilter() TaskStatus {
+	if x != nil && x.StatusFilter != nil {
+		return *x.StatusFilter
+	}
+	return TaskStatus_PENDING
+}
+
+func (x *ListTasksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	Unschedulable map[string]string      `protobuf:"bytes,2,rep,name=unschedulable,proto3" json:"unschedulable,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // task_id -> why no healthy node can run a PENDING task
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_raft_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *ListTasksResponse) GetUnschedulable() map[string]string {
+	if x != nil {
+		return x.Unschedulable
+	}
+	return nil
+}
+
+type CancelTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskRequest) Reset() {
+	*x = CancelTaskRequest{}
+	mi := &file_raft_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskRequest) ProtoMessage() {}
+
+func (x *CancelTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskRequest.ProtoReflect.Descriptor instead.
+func (*CancelTaskRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CancelTaskRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type CancelTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	Status        TaskStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=raftpb.TaskStatus" json:"status,omitempty"`            // CANCELLED, or CANCELLING until the assigned agent acknowledges
+	LeaderAddress string                 `protobuf:"bytes,3,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskResponse) Reset() {
+	*x = CancelTaskResponse{}
+	mi := &file_raft_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskResponse) ProtoMessage() {}
+
+func (x *CancelTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskResponse.ProtoReflect.Descriptor instead.
+func (*CancelTaskResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelTaskResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *CancelTaskResponse) GetStatus() TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TaskStatus_PENDING
+}
+
+func (x *CancelTaskResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
+}
+
+type SubmitJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Tasks         []*SubmitTaskRequest   `protobuf:"bytes,2,rep,name=tasks,proto3" json:"tasks,omitempty"` // Created together in a single log entry
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobRequest) Reset() {
+	*x = SubmitJobRequest{}
+	mi := &file_raft_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobRequest) ProtoMessage() {}
+
+func (x *SubmitJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobRequest.ProtoReflect.Descriptor instead.
+func (*SubmitJobRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SubmitJobRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetTasks() []*SubmitTaskRequest {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type SubmitJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	TaskIds       []string               `protobuf:"bytes,2,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"` // In the order of the request's tasks
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	LeaderAddress string                 `protobuf:"bytes,5,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobResponse) Reset() {
+	*x = SubmitJobResponse{}
+	mi := &file_raft_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobResponse) ProtoMessage() {}
+
+func (x *SubmitJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobResponse.ProtoReflect.Descriptor instead.
+func (*SubmitJobResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SubmitJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *SubmitJobResponse) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+func (x *SubmitJobResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SubmitJobResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *SubmitJobResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_raft_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type GetJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *Job                   `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobResponse) Reset() {
+	*x = GetJobResponse{}
+	mi := &file_raft_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobResponse) ProtoMessage() {}
+
+func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobResponse.ProtoReflect.Descriptor instead.
+func (*GetJobResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetJobResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *GetJobResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StatusFilter  *JobStatus             `protobuf:"varint,1,opt,name=status_filter,json=statusFilter,proto3,enum=raftpb.JobStatus,oneof" json:"status_filter,omitempty"` // Optional filter
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                                               // 0 means no limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_raft_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListJobsRequest) GetStatusFilter() JobStatus {
+	if x != nil && x.StatusFilter != nil {
+		return *x.StatusFilter
+	}
+	return JobStatus_JOB_PENDING
+}
+
+func (x *ListJobsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_raft_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type CancelJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_raft_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CancelJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type CancelJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	LeaderAddress string                 `protobuf:"bytes,2,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobResponse) Reset() {
+	*x = CancelJobResponse{}
+	mi := &file_raft_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobResponse) ProtoMessage() {}
+
+func (x *CancelJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobResponse.ProtoReflect.Descriptor instead.
+func (*CancelJobResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CancelJobResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *CancelJobResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	CpuUsage      float64                `protobuf:"fixed64,2,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
+	MemoryUsage   float64                `protobuf:"fixed64,3,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
+	ActiveTasks   int32                  `protobuf:"varint,4,opt,name=active_tasks,json=activeTasks,proto3" json:"active_tasks,omitempty"`                                              // Recorded as reported_active_tasks; not used for scheduling
+	HeldLeases    []*TaskLease           `protobuf:"bytes,5,rep,name=held_leases,json=heldLeases,proto3" json:"held_leases,omitempty"`                                                  // Leases to renew
+	Capacity      *Resources             `protobuf:"bytes,6,opt,name=capacity,proto3" json:"capacity,omitempty"`                                                                        // Advertised at registration; unset keeps the last advertised value
+	Allocatable   *Resources             `protobuf:"bytes,7,opt,name=allocatable,proto3" json:"allocatable,omitempty"`                                                                  // Defaults to capacity
+	CloudProvider string                 `protobuf:"bytes,8,opt,name=cloud_provider,json=cloudProvider,proto3" json:"cloud_provider,omitempty"`                                         // Unset keeps the last advertised value
+	Region        string                 `protobuf:"bytes,9,opt,name=region,proto3" json:"region,omitempty"`                                                                            // Unset keeps the last advertised value
+	Labels        map[string]string      `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Replaces the node's labels when set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_raft_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *HeartbeatRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetMemoryUsage() float64 {
+	if x != nil {
+		return x.MemoryUsage
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetActiveTasks() int32 {
+	if x != nil {
+		return x.ActiveTasks
+	}
+	return 0
+}
+
+func (x *HeartbeatRequest) GetHeldLeases() []*TaskLease {
+	if x != nil {
+		return x.HeldLeases
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetCapacity() *Resources {
+	if x != nil {
+		return x.Capacity
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetAllocatable() *Resources {
+	if x != nil {
+		return x.Allocatable
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetCloudProvider() string {
+	if x != nil {
+		return x.CloudProvider
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type HeartbeatResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Acknowledged     bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	LeaderAddress    string                 `protobuf:"bytes,2,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"`            // Redirect to leader if not leader
+	RevokedTaskIds   []string               `protobuf:"bytes,3,rep,name=revoked_task_ids,json=revokedTaskIds,proto3" json:"revoked_task_ids,omitempty"`       // Held tasks the node no longer owns; stop working on them
+	LeaseExpiresAt   int64                  `protobuf:"varint,4,opt,name=lease_expires_at,json=leaseExpiresAt,proto3" json:"lease_expires_at,omitempty"`      // New expiry of the renewed leases
+	PreemptedTaskIds []string               `protobuf:"bytes,5,rep,name=preempted_task_ids,json=preemptedTaskIds,proto3" json:"preempted_task_ids,omitempty"` // Revoked tasks evicted for higher-priority work
+	CancelledTaskIds []string               `protobuf:"bytes,6,rep,name=cancelled_task_ids,json=cancelledTaskIds,proto3" json:"cancelled_task_ids,omitempty"` // Held tasks to stop; acknowledge with final_status CANCELLED
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_raft_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *HeartbeatResponse) GetAcknowledged() bool {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return false
+}
+
+func (x *HeartbeatResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
+}
+
+func (x *HeartbeatResponse) GetRevokedTaskIds() []string {
+	if x != nil {
+		return x.RevokedTaskIds
+	}
+	return nil
+}
+
+func (x *HeartbeatResponse) GetLeaseExpiresAt() int64 {
+	if x != nil {
+		return x.LeaseExpiresAt
+	}
+	return 0
+}
+
+func (x *HeartbeatResponse) GetPreemptedTaskIds() []string {
+	if x != nil {
+		return x.PreemptedTaskIds
+	}
+	return nil
+}
+
+func (x *HeartbeatResponse) GetCancelledTaskIds() []string {
+	if x != nil {
+		return x.CancelledTaskIds
+	}
+	return nil
+}
+
+type PollTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollTaskRequest) Reset() {
+	*x = PollTaskRequest{}
+	mi := &file_raft_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollTaskRequest) ProtoMessage() {}
+
+func (x *PollTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollTaskRequest.ProtoReflect.Descriptor instead.
+func (*PollTaskRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *PollTaskRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+type PollTaskResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Task             *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	HasTask          bool                   `protobuf:"varint,2,opt,name=has_task,json=hasTask,proto3" json:"has_task,omitempty"`
+	LeaderAddress    string                 `protobuf:"bytes,3,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"`            // Redirect to leader if not leader
+	CancelledTaskIds []string               `protobuf:"bytes,4,rep,name=cancelled_task_ids,json=cancelledTaskIds,proto3" json:"cancelled_task_ids,omitempty"` // Tasks of the node to stop; acknowledge with final_status CANCELLED
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *PollTaskResponse) Reset() {
+	*x = PollTaskResponse{}
+	mi := &file_raft_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollTaskResponse) ProtoMessage() {}
+
+func (x *PollTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollTaskResponse.ProtoReflect.Descriptor instead.
+func (*PollTaskResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *PollTaskResponse) GetTask() *Task {
 	if x != nil {
 		return x.Task
 	}
-	return nil
+	return nil
+}
+
+func (x *PollTaskResponse) GetHasTask() bool {
+	if x != nil {
+		return x.HasTask
+	}
+	return false
+}
+
+func (x *PollTaskResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
 }
 
-func (x *GetTaskResponse) GetFound() bool {
+func (x *PollTaskResponse) GetCancelledTaskIds() []string {
 	if x != nil {
-		return x.Found
+		return x.CancelledTaskIds
 	}
-	return false
+	return nil
 }
 
-type ListTasksRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	StatusFilter  TaskStatus             `protobuf:"varint,1,opt,name=status_filter,json=statusFilter,proto3,enum=raftpb.TaskStatus" json:"status_filter,omitempty"` // Optional filter
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type ReportTaskResultRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TaskId         string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FinalStatus    TaskStatus             `protobuf:"varint,2,opt,name=final_status,json=finalStatus,proto3,enum=raftpb.TaskStatus" json:"final_status,omitempty"` // COMPLETED, FAILED, or CANCELLED to acknowledge a cancellation
+	ResultData     string                 `protobuf:"bytes,3,opt,name=result_data,json=resultData,proto3" json:"result_data,omitempty"`
+	ErrorMessage   string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`       // Failure reason when final_status is FAILED
+	ErrorClass     string                 `protobuf:"bytes,5,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"`             // Matched against RetryPolicy.retryable_errors
+	FencingToken   uint64                 `protobuf:"varint,6,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`      // From the assigned Task; stale tokens are rejected
+	IdempotencyKey string                 `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Optional; retries with the same key get the outcome of the first attempt
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ListTasksRequest) Reset() {
-	*x = ListTasksRequest{}
-	mi := &file_raft_proto_msgTypes[6]
+func (x *ReportTaskResultRequest) Reset() {
+	*x = ReportTaskResultRequest{}
+	mi := &file_raft_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTasksRequest) String() string {
+func (x *ReportTaskResultRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTasksRequest) ProtoMessage() {}
+func (*ReportTaskResultRequest) ProtoMessage() {}
 
-func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[6]
+func (x *ReportTaskResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -584,47 +2807,83 @@ func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
-func (*ListTasksRequest) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ReportTaskResultRequest.ProtoReflect.Descriptor instead.
+func (*ReportTaskResultRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *ListTasksRequest) GetStatusFilter() TaskStatus {
+func (x *ReportTaskResultRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ReportTaskResultRequest) GetFinalStatus() TaskStatus {
 	if x != nil {
-		return x.StatusFilter
+		return x.FinalStatus
 	}
 	return TaskStatus_PENDING
 }
 
-func (x *ListTasksRequest) GetLimit() int32 {
+func (x *ReportTaskResultRequest) GetResultData() string {
 	if x != nil {
-		return x.Limit
+		return x.ResultData
+	}
+	return ""
+}
+
+func (x *ReportTaskResultRequest) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *ReportTaskResultRequest) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+func (x *ReportTaskResultRequest) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
 	}
 	return 0
 }
 
-type ListTasksResponse struct {
+func (x *ReportTaskResultRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type ReportTaskResultResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	LeaderAddress string                 `protobuf:"bytes,2,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListTasksResponse) Reset() {
-	*x = ListTasksResponse{}
-	mi := &file_raft_proto_msgTypes[7]
+func (x *ReportTaskResultResponse) Reset() {
+	*x = ReportTaskResultResponse{}
+	mi := &file_raft_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTasksResponse) String() string {
+func (x *ReportTaskResultResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTasksResponse) ProtoMessage() {}
+func (*ReportTaskResultResponse) ProtoMessage() {}
 
-func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[7]
+func (x *ReportTaskResultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -635,43 +2894,51 @@ func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
-func (*ListTasksResponse) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ReportTaskResultResponse.ProtoReflect.Descriptor instead.
+func (*ReportTaskResultResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *ListTasksResponse) GetTasks() []*Task {
+func (x *ReportTaskResultResponse) GetAcknowledged() bool {
 	if x != nil {
-		return x.Tasks
+		return x.Acknowledged
 	}
-	return nil
+	return false
 }
 
-type HeartbeatRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	CpuUsage      float64                `protobuf:"fixed64,2,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
-	MemoryUsage   float64                `protobuf:"fixed64,3,opt,name=memory_usage,json=memoryUsage,proto3" json:"memory_usage,omitempty"`
-	ActiveTasks   int32                  `protobuf:"varint,4,opt,name=active_tasks,json=activeTasks,proto3" json:"active_tasks,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ReportTaskResultResponse) GetLeaderAddress() string {
+	if x != nil {
+		return x.LeaderAddress
+	}
+	return ""
 }
 
-func (x *HeartbeatRequest) Reset() {
-	*x = HeartbeatRequest{}
-	mi := &file_raft_proto_msgTypes[8]
+type ReportTaskProgressRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TaskId          string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FencingToken    uint64                 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`           // From the assigned Task; stale tokens are rejected
+	PercentComplete float64                `protobuf:"fixed64,3,opt,name=percent_complete,json=percentComplete,proto3" json:"percent_complete,omitempty"` // 0 to 100
+	Stage           string                 `protobuf:"bytes,4,opt,name=stage,proto3" json:"stage,omitempty"`
+	Metrics         map[string]float64     `protobuf:"bytes,5,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // Must be finite
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReportTaskProgressRequest) Reset() {
+	*x = ReportTaskProgressRequest{}
+	mi := &file_raft_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatRequest) String() string {
+func (x *ReportTaskProgressRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatRequest) ProtoMessage() {}
+func (*ReportTaskProgressRequest) ProtoMessage() {}
 
-func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[8]
+func (x *ReportTaskProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -682,62 +2949,70 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
-func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ReportTaskProgressRequest.ProtoReflect.Descriptor instead.
+func (*ReportTaskProgressRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *HeartbeatRequest) GetNodeId() string {
+func (x *ReportTaskProgressRequest) GetTaskId() string {
 	if x != nil {
-		return x.NodeId
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *HeartbeatRequest) GetCpuUsage() float64 {
+func (x *ReportTaskProgressRequest) GetFencingToken() uint64 {
 	if x != nil {
-		return x.CpuUsage
+		return x.FencingToken
 	}
 	return 0
 }
 
-func (x *HeartbeatRequest) GetMemoryUsage() float64 {
+func (x *ReportTaskProgressRequest) GetPercentComplete() float64 {
 	if x != nil {
-		return x.MemoryUsage
+		return x.PercentComplete
 	}
 	return 0
 }
 
-func (x *HeartbeatRequest) GetActiveTasks() int32 {
+func (x *ReportTaskProgressRequest) GetStage() string {
 	if x != nil {
-		return x.ActiveTasks
+		return x.Stage
 	}
-	return 0
+	return ""
 }
 
-type HeartbeatResponse struct {
+func (x *ReportTaskProgressRequest) GetMetrics() map[string]float64 {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type ReportTaskProgressResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
 	LeaderAddress string                 `protobuf:"bytes,2,opt,name=leader_address,json=leaderAddress,proto3" json:"leader_address,omitempty"` // Redirect to leader if not leader
+	Cancelling    bool                   `protobuf:"varint,3,opt,name=cancelling,proto3" json:"cancelling,omitempty"`                           // The task was cancelled; stop it and acknowledge with final_status CANCELLED
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HeartbeatResponse) Reset() {
-	*x = HeartbeatResponse{}
-	mi := &file_raft_proto_msgTypes[9]
+func (x *ReportTaskProgressResponse) Reset() {
+	*x = ReportTaskProgressResponse{}
+	mi := &file_raft_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HeartbeatResponse) String() string {
+func (x *ReportTaskProgressResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HeartbeatResponse) ProtoMessage() {}
+func (*ReportTaskProgressResponse) ProtoMessage() {}
 
-func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[9]
+func (x *ReportTaskProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -748,47 +3023,54 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
-func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ReportTaskProgressResponse.ProtoReflect.Descriptor instead.
+func (*ReportTaskProgressResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *HeartbeatResponse) GetAcknowledged() bool {
+func (x *ReportTaskProgressResponse) GetAcknowledged() bool {
 	if x != nil {
 		return x.Acknowledged
 	}
 	return false
 }
 
-func (x *HeartbeatResponse) GetLeaderAddress() string {
+func (x *ReportTaskProgressResponse) GetLeaderAddress() string {
 	if x != nil {
 		return x.LeaderAddress
 	}
 	return ""
 }
 
-type PollTaskRequest struct {
+func (x *ReportTaskProgressResponse) GetCancelling() bool {
+	if x != nil {
+		return x.Cancelling
+	}
+	return false
+}
+
+type RegisterServerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Server        *ServerInfo            `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PollTaskRequest) Reset() {
-	*x = PollTaskRequest{}
-	mi := &file_raft_proto_msgTypes[10]
+func (x *RegisterServerRequest) Reset() {
+	*x = RegisterServerRequest{}
+	mi := &file_raft_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PollTaskRequest) String() string {
+func (x *RegisterServerRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PollTaskRequest) ProtoMessage() {}
+func (*RegisterServerRequest) ProtoMessage() {}
 
-func (x *PollTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[10]
+func (x *RegisterServerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -799,41 +3081,40 @@ func (x *PollTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PollTaskRequest.ProtoReflect.Descriptor instead.
-func (*PollTaskRequest) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RegisterServerRequest.ProtoReflect.Descriptor instead.
+func (*RegisterServerRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *PollTaskRequest) GetNodeId() string {
+func (x *RegisterServerRequest) GetServer() *ServerInfo {
 	if x != nil {
-		return x.NodeId
+		return x.Server
 	}
-	return ""
+	return nil
 }
 
-type PollTaskResponse struct {
+type RegisterServerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
-	HasTask       bool                   `protobuf:"varint,2,opt,name=has_task,json=hasTask,proto3" json:"has_task,omitempty"`
+	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PollTaskResponse) Reset() {
-	*x = PollTaskResponse{}
-	mi := &file_raft_proto_msgTypes[11]
+func (x *RegisterServerResponse) Reset() {
+	*x = RegisterServerResponse{}
+	mi := &file_raft_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PollTaskResponse) String() string {
+func (x *RegisterServerResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PollTaskResponse) ProtoMessage() {}
+func (*RegisterServerResponse) ProtoMessage() {}
 
-func (x *PollTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[11]
+func (x *RegisterServerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -844,49 +3125,39 @@ func (x *PollTaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PollTaskResponse.ProtoReflect.Descriptor instead.
-func (*PollTaskResponse) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{11}
-}
-
-func (x *PollTaskResponse) GetTask() *Task {
-	if x != nil {
-		return x.Task
-	}
-	return nil
+// Deprecated: Use RegisterServerResponse.ProtoReflect.Descriptor instead.
+func (*RegisterServerResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *PollTaskResponse) GetHasTask() bool {
+func (x *RegisterServerResponse) GetAcknowledged() bool {
 	if x != nil {
-		return x.HasTask
+		return x.Acknowledged
 	}
 	return false
 }
 
-type ReportTaskResultRequest struct {
+type GetServerInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	FinalStatus   TaskStatus             `protobuf:"varint,2,opt,name=final_status,json=finalStatus,proto3,enum=raftpb.TaskStatus" json:"final_status,omitempty"`
-	ResultData    string                 `protobuf:"bytes,3,opt,name=result_data,json=resultData,proto3" json:"result_data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReportTaskResultRequest) Reset() {
-	*x = ReportTaskResultRequest{}
-	mi := &file_raft_proto_msgTypes[12]
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_raft_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReportTaskResultRequest) String() string {
+func (x *GetServerInfoRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReportTaskResultRequest) ProtoMessage() {}
+func (*GetServerInfoRequest) ProtoMessage() {}
 
-func (x *ReportTaskResultRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[12]
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -897,54 +3168,33 @@ func (x *ReportTaskResultRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReportTaskResultRequest.ProtoReflect.Descriptor instead.
-func (*ReportTaskResultRequest) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{12}
-}
-
-func (x *ReportTaskResultRequest) GetTaskId() string {
-	if x != nil {
-		return x.TaskId
-	}
-	return ""
-}
-
-func (x *ReportTaskResultRequest) GetFinalStatus() TaskStatus {
-	if x != nil {
-		return x.FinalStatus
-	}
-	return TaskStatus_PENDING
-}
-
-func (x *ReportTaskResultRequest) GetResultData() string {
-	if x != nil {
-		return x.ResultData
-	}
-	return ""
+// Deprecated: Use GetServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{41}
 }
 
-type ReportTaskResultResponse struct {
+type GetServerInfoResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Acknowledged  bool                   `protobuf:"varint,1,opt,name=acknowledged,proto3" json:"acknowledged,omitempty"`
+	Server        *ServerInfo            `protobuf:"bytes,1,opt,name=server,proto3" json:"server,omitempty"` // Metadata of the server answering the request
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReportTaskResultResponse) Reset() {
-	*x = ReportTaskResultResponse{}
-	mi := &file_raft_proto_msgTypes[13]
+func (x *GetServerInfoResponse) Reset() {
+	*x = GetServerInfoResponse{}
+	mi := &file_raft_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReportTaskResultResponse) String() string {
+func (x *GetServerInfoResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReportTaskResultResponse) ProtoMessage() {}
+func (*GetServerInfoResponse) ProtoMessage() {}
 
-func (x *ReportTaskResultResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_raft_proto_msgTypes[13]
+func (x *GetServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_raft_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -955,16 +3205,16 @@ func (x *ReportTaskResultResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReportTaskResultResponse.ProtoReflect.Descriptor instead.
-func (*ReportTaskResultResponse) Descriptor() ([]byte, []int) {
-	return file_raft_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use GetServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_raft_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *ReportTaskResultResponse) GetAcknowledged() bool {
+func (x *GetServerInfoResponse) GetServer() *ServerInfo {
 	if x != nil {
-		return x.Acknowledged
+		return x.Server
 	}
-	return false
+	return nil
 }
 
 var File_raft_proto protoreflect.FileDescriptor
@@ -972,7 +3222,7 @@ var File_raft_proto protoreflect.FileDescriptor
 const file_raft_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"raft.proto\x12\x06raftpb\"\xb1\x02\n" +
+	"raft.proto\x12\x06raftpb\"\xe0\a\n" +
 	"\x04Task\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
 	"\ttask_type\x18\x02 \x01(\tR\btaskType\x12*\n" +
@@ -985,7 +3235,87 @@ const file_raft_proto_rawDesc = "" +
 	"started_at\x18\a \x01(\x03R\tstartedAt\x12!\n" +
 	"\fcompleted_at\x18\b \x01(\x03R\vcompletedAt\x12\x1f\n" +
 	"\vresult_data\x18\t \x01(\tR\n" +
-	"resultData\"\xae\x02\n" +
+	"resultData\x12\x18\n" +
+	"\aattempt\x18\n" +
+	" \x01(\x05R\aattempt\x126\n" +
+	"\flast_failure\x18\v \x01(\v2\x13.raftpb.TaskFailureR\vlastFailure\x126\n" +
+	"\fretry_policy\x18\f \x01(\v2\x13.raftpb.RetryPolicyR\vretryPolicy\x12,\n" +
+	"\x12dead_letter_reason\x18\r \x01(\tR\x10deadLetterReason\x12(\n" +
+	"\x10lease_expires_at\x18\x0e \x01(\x03R\x0eleaseExpiresAt\x12#\n" +
+	"\rfencing_token\x18\x0f \x01(\x04R\ffencingToken\x12$\n" +
+	"\x0eplaced_node_id\x18\x10 \x01(\tR\fplacedNodeId\x12\x1b\n" +
+	"\tplaced_at\x18\x11 \x01(\x03R\bplacedAt\x12\x1b\n" +
+	"\tplaced_by\x18\x12 \x01(\tR\bplacedBy\x12/\n" +
+	"\tresources\x18\x13 \x01(\v2\x11.raftpb.ResourcesR\tresources\x12,\n" +
+	"\baffinity\x18\x14 \x01(\v2\x10.raftpb.AffinityR\baffinity\x12\x1a\n" +
+	"\bpriority\x18\x15 \x01(\x05R\bpriority\x12%\n" +
+	"\x0epriority_class\x18\x16 \x01(\tR\rpriorityClass\x12\x15\n" +
+	"\x06job_id\x18\x17 \x01(\tR\x05jobId\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\x18 \x03(\tR\tdependsOn\x120\n" +
+	"\bprogress\x18\x19 \x01(\v2\x14.raftpb.TaskProgressR\bprogress\x12<\n" +
+	"\x0flast_preemption\x18\x1a \x01(\v2\x13.raftpb.TaskFailureR\x0elastPreemption\"\xe9\x01\n" +
+	"\fTaskProgress\x12)\n" +
+	"\x10percent_complete\x18\x01 \x01(\x01R\x0fpercentComplete\x12\x14\n" +
+	"\x05stage\x18\x02 \x01(\tR\x05stage\x12;\n" +
+	"\ametrics\x18\x03 \x03(\v2!.raftpb.TaskProgress.MetricsEntryR\ametrics\x12\x1f\n" +
+	"\vreported_at\x18\x04 \x01(\x03R\n" +
+	"reportedAt\x1a:\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x84\x02\n" +
+	"\bAffinity\x123\n" +
+	"\brequired\x18\x01 \x03(\v2\x17.raftpb.LabelExpressionR\brequired\x12<\n" +
+	"\rrequired_anti\x18\x02 \x03(\v2\x17.raftpb.LabelExpressionR\frequiredAnti\x12=\n" +
+	"\tpreferred\x18\x03 \x03(\v2\x1f.raftpb.WeightedLabelExpressionR\tpreferred\x12F\n" +
+	"\x0epreferred_anti\x18\x04 \x03(\v2\x1f.raftpb.WeightedLabelExpressionR\rpreferredAnti\"n\n" +
+	"\x0fLabelExpression\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x121\n" +
+	"\boperator\x18\x02 \x01(\x0e2\x15.raftpb.LabelOperatorR\boperator\x12\x16\n" +
+	"\x06values\x18\x03 \x03(\tR\x06values\"j\n" +
+	"\x17WeightedLabelExpression\x127\n" +
+	"\n" +
+	"expression\x18\x01 \x01(\v2\x17.raftpb.LabelExpressionR\n" +
+	"expression\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\"\x94\x01\n" +
+	"\tResources\x12\x1b\n" +
+	"\tcpu_cores\x18\x01 \x01(\x01R\bcpuCores\x12\x1b\n" +
+	"\tmemory_mb\x18\x02 \x01(\x03R\bmemoryMb\x12\"\n" +
+	"\faccelerators\x18\x03 \x01(\x05R\faccelerators\x12)\n" +
+	"\x10accelerator_type\x18\x04 \x01(\tR\x0facceleratorType\"I\n" +
+	"\tTaskLease\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\"\xde\x01\n" +
+	"\vRetryPolicy\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\x05R\vmaxAttempts\x12,\n" +
+	"\x12initial_backoff_ms\x18\x02 \x01(\x03R\x10initialBackoffMs\x12-\n" +
+	"\x12backoff_multiplier\x18\x03 \x01(\x01R\x11backoffMultiplier\x12$\n" +
+	"\x0emax_backoff_ms\x18\x04 \x01(\x03R\fmaxBackoffMs\x12)\n" +
+	"\x10retryable_errors\x18\x05 \x03(\tR\x0fretryableErrors\"\xa3\x01\n" +
+	"\vTaskFailure\x12#\n" +
+	"\rerror_message\x18\x01 \x01(\tR\ferrorMessage\x12\x1b\n" +
+	"\tfailed_at\x18\x02 \x01(\x03R\bfailedAt\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\x05R\aattempt\x12\x1f\n" +
+	"\verror_class\x18\x05 \x01(\tR\n" +
+	"errorClass\"\x8c\x02\n" +
+	"\x03Job\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12)\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x11.raftpb.JobStatusR\x06status\x12\x19\n" +
+	"\btask_ids\x18\x04 \x03(\tR\ataskIds\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12!\n" +
+	"\fcompleted_at\x18\x06 \x01(\x03R\vcompletedAt\x12/\n" +
+	"\bprogress\x18\a \x01(\v2\x13.raftpb.JobProgressR\bprogress\x12!\n" +
+	"\fcancelled_at\x18\b \x01(\x03R\vcancelledAt\"\xab\x01\n" +
+	"\vJobProgress\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x18\n" +
+	"\apending\x18\x02 \x01(\x05R\apending\x12\x18\n" +
+	"\arunning\x18\x03 \x01(\x05R\arunning\x12\x1c\n" +
+	"\tcompleted\x18\x04 \x01(\x05R\tcompleted\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x05R\x06failed\x12\x1c\n" +
+	"\tcancelled\x18\x06 \x01(\x05R\tcancelled\"\xe4\x04\n" +
 	"\x04Node\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x18\n" +
 	"\aaddress\x18\x02 \x01(\tR\aaddress\x12%\n" +
@@ -995,44 +3325,167 @@ const file_raft_proto_rawDesc = "" +
 	"\x0elast_heartbeat\x18\x06 \x01(\x03R\rlastHeartbeat\x12\x1b\n" +
 	"\tcpu_usage\x18\a \x01(\x01R\bcpuUsage\x12!\n" +
 	"\fmemory_usage\x18\b \x01(\x01R\vmemoryUsage\x12!\n" +
-	"\factive_tasks\x18\t \x01(\x05R\vactiveTasks\"M\n" +
+	"\factive_tasks\x18\t \x01(\x05R\vactiveTasks\x12-\n" +
+	"\bcapacity\x18\n" +
+	" \x01(\v2\x11.raftpb.ResourcesR\bcapacity\x123\n" +
+	"\vallocatable\x18\v \x01(\v2\x11.raftpb.ResourcesR\vallocatable\x12/\n" +
+	"\tallocated\x18\f \x01(\v2\x11.raftpb.ResourcesR\tallocated\x120\n" +
+	"\x06labels\x18\r \x03(\v2\x18.raftpb.Node.LabelsEntryR\x06labels\x122\n" +
+	"\x15reported_active_tasks\x18\x0e \x01(\x05R\x13reportedActiveTasks\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd3\x01\n" +
+	"\n" +
+	"ServerInfo\x12\x1b\n" +
+	"\tserver_id\x18\x01 \x01(\tR\bserverId\x12!\n" +
+	"\fraft_address\x18\x02 \x01(\tR\vraftAddress\x12!\n" +
+	"\fgrpc_address\x18\x03 \x01(\tR\vgrpcAddress\x12%\n" +
+	"\x0ecloud_provider\x18\x04 \x01(\tR\rcloudProvider\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12#\n" +
+	"\rregistered_at\x18\x06 \x01(\x03R\fregisteredAt\"\x88\x03\n" +
 	"\x11SubmitTaskRequest\x12\x1b\n" +
 	"\ttask_type\x18\x01 \x01(\tR\btaskType\x12\x1b\n" +
-	"\ttask_data\x18\x02 \x01(\fR\btaskData\"l\n" +
+	"\ttask_data\x18\x02 \x01(\fR\btaskData\x126\n" +
+	"\fretry_policy\x18\x03 \x01(\v2\x13.raftpb.RetryPolicyR\vretryPolicy\x12/\n" +
+	"\tresources\x18\x04 \x01(\v2\x11.raftpb.ResourcesR\tresources\x12,\n" +
+	"\baffinity\x18\x05 \x01(\v2\x10.raftpb.AffinityR\baffinity\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\x12%\n" +
+	"\x0epriority_class\x18\a \x01(\tR\rpriorityClass\x12\x17\n" +
+	"\atask_id\x18\b \x01(\tR\x06taskId\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\t \x03(\tR\tdependsOn\x12'\n" +
+	"\x0fidempotency_key\x18\n" +
+	" \x01(\tR\x0eidempotencyKey\"\x93\x01\n" +
 	"\x12SubmitTaskResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12#\n" +
-	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\")\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\x12%\n" +
+	"\x0eleader_address\x18\x04 \x01(\tR\rleaderAddress\"E\n" +
+	"\x12SubmitTasksRequest\x12/\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x19.raftpb.SubmitTaskRequestR\x05tasks\"\x96\x01\n" +
+	"\x13SubmitTasksResponse\x12\x19\n" +
+	"\btask_ids\x18\x01 \x03(\tR\ataskIds\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\x12%\n" +
+	"\x0eleader_address\x18\x04 \x01(\tR\rleaderAddress\")\n" +
 	"\x0eGetTaskRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\"I\n" +
 	"\x0fGetTaskResponse\x12 \n" +
 	"\x04task\x18\x01 \x01(\v2\f.raftpb.TaskR\x04task\x12\x14\n" +
-	"\x05found\x18\x02 \x01(\bR\x05found\"a\n" +
-	"\x10ListTasksRequest\x127\n" +
-	"\rstatus_filter\x18\x01 \x01(\x0e2\x12.raftpb.TaskStatusR\fstatusFilter\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\"7\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"x\n" +
+	"\x10ListTasksRequest\x12<\n" +
+	"\rstatus_filter\x18\x01 \x01(\x0e2\x12.raftpb.TaskStatusH\x00R\fstatusFilter\x88\x01\x01\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limitB\x10\n" +
+	"\x0e_status_filter\"\xcd\x01\n" +
 	"\x11ListTasksResponse\x12\"\n" +
-	"\x05tasks\x18\x01 \x03(\v2\f.raftpb.TaskR\x05tasks\"\x8e\x01\n" +
+	"\x05tasks\x18\x01 \x03(\v2\f.raftpb.TaskR\x05tasks\x12R\n" +
+	"\runschedulable\x18\x02 \x03(\v2,.raftpb.ListTasksResponse.UnschedulableEntryR\runschedulable\x1a@\n" +
+	"\x12UnschedulableEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\",\n" +
+	"\x11CancelTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x8b\x01\n" +
+	"\x12CancelTaskResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12*\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x12.raftpb.TaskStatusR\x06status\x12%\n" +
+	"\x0eleader_address\x18\x03 \x01(\tR\rleaderAddress\"W\n" +
+	"\x10SubmitJobRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12/\n" +
+	"\x05tasks\x18\x02 \x03(\v2\x19.raftpb.SubmitTaskRequestR\x05tasks\"\xab\x01\n" +
+	"\x11SubmitJobResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\btask_ids\x18\x02 \x03(\tR\ataskIds\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12#\n" +
+	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage\x12%\n" +
+	"\x0eleader_address\x18\x05 \x01(\tR\rleaderAddress\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"E\n" +
+	"\x0eGetJobResponse\x12\x1d\n" +
+	"\x03job\x18\x01 \x01(\v2\v.raftpb.JobR\x03job\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\"v\n" +
+	"\x0fListJobsRequest\x12;\n" +
+	"\rstatus_filter\x18\x01 \x01(\x0e2\x11.raftpb.JobStatusH\x00R\fstatusFilter\x88\x01\x01\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limitB\x10\n" +
+	"\x0e_status_filter\"3\n" +
+	"\x10ListJobsResponse\x12\x1f\n" +
+	"\x04jobs\x18\x01 \x03(\v2\v.raftpb.JobR\x04jobs\")\n" +
+	"\x10CancelJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"^\n" +
+	"\x11CancelJobResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12%\n" +
+	"\x0eleader_address\x18\x02 \x01(\tR\rleaderAddress\"\xde\x03\n" +
 	"\x10HeartbeatRequest\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1b\n" +
 	"\tcpu_usage\x18\x02 \x01(\x01R\bcpuUsage\x12!\n" +
 	"\fmemory_usage\x18\x03 \x01(\x01R\vmemoryUsage\x12!\n" +
-	"\factive_tasks\x18\x04 \x01(\x05R\vactiveTasks\"^\n" +
+	"\factive_tasks\x18\x04 \x01(\x05R\vactiveTasks\x122\n" +
+	"\vheld_leases\x18\x05 \x03(\v2\x11.raftpb.TaskLeaseR\n" +
+	"heldLeases\x12-\n" +
+	"\bcapacity\x18\x06 \x01(\v2\x11.raftpb.ResourcesR\bcapacity\x123\n" +
+	"\vallocatable\x18\a \x01(\v2\x11.raftpb.ResourcesR\vallocatable\x12%\n" +
+	"\x0ecloud_provider\x18\b \x01(\tR\rcloudProvider\x12\x16\n" +
+	"\x06region\x18\t \x01(\tR\x06region\x12<\n" +
+	"\x06labels\x18\n" +
+	" \x03(\v2$.raftpb.HeartbeatRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8e\x02\n" +
 	"\x11HeartbeatResponse\x12\"\n" +
 	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12%\n" +
-	"\x0eleader_address\x18\x02 \x01(\tR\rleaderAddress\"*\n" +
+	"\x0eleader_address\x18\x02 \x01(\tR\rleaderAddress\x12(\n" +
+	"\x10revoked_task_ids\x18\x03 \x03(\tR\x0erevokedTaskIds\x12(\n" +
+	"\x10lease_expires_at\x18\x04 \x01(\x03R\x0eleaseExpiresAt\x12,\n" +
+	"\x12preempted_task_ids\x18\x05 \x03(\tR\x10preemptedTaskIds\x12,\n" +
+	"\x12cancelled_task_ids\x18\x06 \x03(\tR\x10cancelledTaskIds\"*\n" +
 	"\x0fPollTaskRequest\x12\x17\n" +
-	"\anode_id\x18\x01 \x01(\tR\x06nodeId\"O\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\"\xa4\x01\n" +
 	"\x10PollTaskResponse\x12 \n" +
 	"\x04task\x18\x01 \x01(\v2\f.raftpb.TaskR\x04task\x12\x19\n" +
-	"\bhas_task\x18\x02 \x01(\bR\ahasTask\"\x8a\x01\n" +
+	"\bhas_task\x18\x02 \x01(\bR\ahasTask\x12%\n" +
+	"\x0eleader_address\x18\x03 \x01(\tR\rleaderAddress\x12,\n" +
+	"\x12cancelled_task_ids\x18\x04 \x03(\tR\x10cancelledTaskIds\"\x9e\x02\n" +
 	"\x17ReportTaskResultRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x125\n" +
 	"\ffinal_status\x18\x02 \x01(\x0e2\x12.raftpb.TaskStatusR\vfinalStatus\x12\x1f\n" +
 	"\vresult_data\x18\x03 \x01(\tR\n" +
-	"resultData\">\n" +
+	"resultData\x12#\n" +
+	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage\x12\x1f\n" +
+	"\verror_class\x18\x05 \x01(\tR\n" +
+	"errorClass\x12#\n" +
+	"\rfencing_token\x18\x06 \x01(\x04R\ffencingToken\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\"e\n" +
 	"\x18ReportTaskResultResponse\x12\"\n" +
-	"\facknowledged\x18\x01 \x01(\bR\facknowledged*O\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12%\n" +
+	"\x0eleader_address\x18\x02 \x01(\tR\rleaderAddress\"\xa0\x02\n" +
+	"\x19ReportTaskProgressRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\x12)\n" +
+	"\x10percent_complete\x18\x03 \x01(\x01R\x0fpercentComplete\x12\x14\n" +
+	"\x05stage\x18\x04 \x01(\tR\x05stage\x12H\n" +
+	"\ametrics\x18\x05 \x03(\v2..raftpb.ReportTaskProgressRequest.MetricsEntryR\ametrics\x1a:\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x87\x01\n" +
+	"\x1aReportTaskProgressResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\x12%\n" +
+	"\x0eleader_address\x18\x02 \x01(\tR\rleaderAddress\x12\x1e\n" +
+	"\n" +
+	"cancelling\x18\x03 \x01(\bR\n" +
+	"cancelling\"C\n" +
+	"\x15RegisterServerRequest\x12*\n" +
+	"\x06server\x18\x01 \x01(\v2\x12.raftpb.ServerInfoR\x06server\"<\n" +
+	"\x16RegisterServerResponse\x12\"\n" +
+	"\facknowledged\x18\x01 \x01(\bR\facknowledged\"\x16\n" +
+	"\x14GetServerInfoRequest\"C\n" +
+	"\x15GetServerInfoResponse\x12*\n" +
+	"\x06server\x18\x01 \x01(\v2\x12.raftpb.ServerInfoR\x06server*C\n" +
+	"\rLabelOperator\x12\x06\n" +
+	"\x02IN\x10\x00\x12\n" +
+	"\n" +
+	"\x06NOT_IN\x10\x01\x12\n" +
+	"\n" +
+	"\x06EXISTS\x10\x02\x12\x12\n" +
+	"\x0eDOES_NOT_EXIST\x10\x03*\x8c\x01\n" +
 	"\n" +
 	"TaskStatus\x12\v\n" +
 	"\aPENDING\x10\x00\x12\f\n" +
@@ -1040,21 +3493,46 @@ const file_raft_proto_rawDesc = "" +
 	"\aRUNNING\x10\x02\x12\r\n" +
 	"\tCOMPLETED\x10\x03\x12\n" +
 	"\n" +
-	"\x06FAILED\x10\x04*5\n" +
+	"\x06FAILED\x10\x04\x12\x0f\n" +
+	"\vDEAD_LETTER\x10\x05\x12\r\n" +
+	"\tCANCELLED\x10\x06\x12\v\n" +
+	"\aBLOCKED\x10\a\x12\x0e\n" +
+	"\n" +
+	"CANCELLING\x10\b*c\n" +
+	"\tJobStatus\x12\x0f\n" +
+	"\vJOB_PENDING\x10\x00\x12\x0f\n" +
+	"\vJOB_RUNNING\x10\x01\x12\x11\n" +
+	"\rJOB_SUCCEEDED\x10\x02\x12\x0e\n" +
+	"\n" +
+	"JOB_FAILED\x10\x03\x12\x11\n" +
+	"\rJOB_CANCELLED\x10\x04*5\n" +
 	"\n" +
 	"NodeStatus\x12\v\n" +
 	"\aHEALTHY\x10\x00\x12\r\n" +
 	"\tUNHEALTHY\x10\x01\x12\v\n" +
-	"\aUNKNOWN\x10\x022\xd0\x01\n" +
+	"\aUNKNOWN\x10\x022\xdd\x02\n" +
 	"\vTaskService\x12C\n" +
 	"\n" +
-	"SubmitTask\x12\x19.raftpb.SubmitTaskRequest\x1a\x1a.raftpb.SubmitTaskResponse\x12:\n" +
+	"SubmitTask\x12\x19.raftpb.SubmitTaskRequest\x1a\x1a.raftpb.SubmitTaskResponse\x12F\n" +
+	"\vSubmitTasks\x12\x1a.raftpb.SubmitTasksRequest\x1a\x1b.raftpb.SubmitTasksResponse\x12:\n" +
 	"\aGetTask\x12\x16.raftpb.GetTaskRequest\x1a\x17.raftpb.GetTaskResponse\x12@\n" +
-	"\tListTasks\x12\x18.raftpb.ListTasksRequest\x1a\x19.raftpb.ListTasksResponse2\xe5\x01\n" +
+	"\tListTasks\x12\x18.raftpb.ListTasksRequest\x1a\x19.raftpb.ListTasksResponse\x12C\n" +
+	"\n" +
+	"CancelTask\x12\x19.raftpb.CancelTaskRequest\x1a\x1a.raftpb.CancelTaskResponse2\x88\x02\n" +
+	"\n" +
+	"JobService\x12@\n" +
+	"\tSubmitJob\x12\x18.raftpb.SubmitJobRequest\x1a\x19.raftpb.SubmitJobResponse\x127\n" +
+	"\x06GetJob\x12\x15.raftpb.GetJobRequest\x1a\x16.raftpb.GetJobResponse\x12=\n" +
+	"\bListJobs\x12\x17.raftpb.ListJobsRequest\x1a\x18.raftpb.ListJobsResponse\x12@\n" +
+	"\tCancelJob\x12\x18.raftpb.CancelJobRequest\x1a\x19.raftpb.CancelJobResponse2\xc2\x02\n" +
 	"\vNodeService\x12@\n" +
 	"\tHeartbeat\x12\x18.raftpb.HeartbeatRequest\x1a\x19.raftpb.HeartbeatResponse\x12=\n" +
 	"\bPollTask\x12\x17.raftpb.PollTaskRequest\x1a\x18.raftpb.PollTaskResponse\x12U\n" +
-	"\x10ReportTaskResult\x12\x1f.raftpb.ReportTaskResultRequest\x1a .raftpb.ReportTaskResultResponseB9Z7github.com/yourusername/ml-raft-control-plane/pkg/protob\x06proto3"
+	"\x10ReportTaskResult\x12\x1f.raftpb.ReportTaskResultRequest\x1a .raftpb.ReportTaskResultResponse\x12[\n" +
+	"\x12ReportTaskProgress\x12!.raftpb.ReportTaskProgressRequest\x1a\".raftpb.ReportTaskProgressResponse2\xaf\x01\n" +
+	"\x0eClusterService\x12O\n" +
+	"\x0eRegisterServer\x12\x1d.raftpb.RegisterServerRequest\x1a\x1e.raftpb.RegisterServerResponse\x12L\n" +
+	"\rGetServerInfo\x12\x1c.raftpb.GetServerInfoRequest\x1a\x1d.raftpb.GetServerInfoResponseB9Z7github.com/yourusername/ml-raft-control-plane/pkg/protob\x06proto3"
 
 var (
 	file_raft_proto_rawDescOnce sync.Once
@@ -1068,51 +3546,141 @@ func file_raft_proto_rawDescGZIP() []byte {
 	return file_raft_proto_rawDescData
 }
 
-var file_raft_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_raft_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_raft_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_raft_proto_msgTypes = make([]protoimpl.MessageInfo, 48)
 var file_raft_proto_goTypes = []any{
-	(TaskStatus)(0),                  // 0: raftpb.TaskStatus
-	(NodeStatus)(0),                  // 1: raftpb.NodeStatus
-	(*Task)(nil),                     // 2: raftpb.Task
-	(*Node)(nil),                     // 3: raftpb.Node
-	(*SubmitTaskRequest)(nil),        // 4: raftpb.SubmitTaskRequest
-	(*SubmitTaskResponse)(nil),       // 5: raftpb.SubmitTaskResponse
-	(*GetTaskRequest)(nil),           // 6: raftpb.GetTaskRequest
-	(*GetTaskResponse)(nil),          // 7: raftpb.GetTaskResponse
-	(*ListTasksRequest)(nil),         // 8: raftpb.ListTasksRequest
-	(*ListTasksResponse)(nil),        // 9: raftpb.ListTasksResponse
-	(*HeartbeatRequest)(nil),         // 10: raftpb.HeartbeatRequest
-	(*HeartbeatResponse)(nil),        // 11: raftpb.HeartbeatResponse
-	(*PollTaskRequest)(nil),          // 12: raftpb.PollTaskRequest
-	(*PollTaskResponse)(nil),         // 13: raftpb.PollTaskResponse
-	(*ReportTaskResultRequest)(nil),  // 14: raftpb.ReportTaskResultRequest
-	(*ReportTaskResultResponse)(nil), // 15: raftpb.ReportTaskResultResponse
+	(LabelOperator)(0),                 // 0: raftpb.LabelOperator
+	(TaskStatus)(0),                    // 1: raftpb.TaskStatus
+	(JobStatus)(0),                     // 2: raftpb.JobStatus
+	(NodeStatus)(0),                    // 3: raftpb.NodeStatus
+	(*Task)(nil),                       // 4: raftpb.Task
+	(*TaskProgress)(nil),               // 5: raftpb.TaskProgress
+	(*Affinity)(nil),                   // 6: raftpb.Affinity
+	(*LabelExpression)(nil),            // 7: raftpb.LabelExpression
+	(*WeightedLabelExpression)(nil),    // 8: raftpb.WeightedLabelExpression
+	(*Resources)(nil),                  // 9: raftpb.Resources
+	(*TaskLease)(nil),                  // 10: raftpb.TaskLease
+	(*RetryPolicy)(nil),                // 11: raftpb.RetryPolicy
+	(*TaskFailure)(nil),                // 12: raftpb.TaskFailure
+	(*Job)(nil),                        // 13: raftpb.Job
+	(*JobProgress)(nil),                // 14: raftpb.JobProgress
+	(*Node)(nil),                       // 15: raftpb.Node
+	(*ServerInfo)(nil),                 // 16: raftpb.ServerInfo
+	(*SubmitTaskRequest)(nil),          // 17: raftpb.SubmitTaskRequest
+	(*SubmitTaskResponse)(nil),         // 18: raftpb.SubmitTaskResponse
+	(*SubmitTasksRequest)(nil),         // 19: raftpb.SubmitTasksRequest
+	(*SubmitTasksResponse)(nil),        // 20: raftpb.SubmitTasksResponse
+	(*GetTaskRequest)(nil),             // 21: raftpb.GetTaskRequest
+	(*GetTaskResponse)(nil),            // 22: raftpb.GetTaskResponse
+	(*ListTasksRequest)(nil),           // 23: raftpb.ListTasksRequest
+	(*ListTasksResponse)(nil),          // 24: raftpb.ListTasksResponse
+	(*CancelTaskRequest)(nil),          // 25: raftpb.CancelTaskRequest
+	(*CancelTaskResponse)(nil),         // 26: raftpb.CancelTaskResponse
+	(*SubmitJobRequest)(nil),           // 27: raftpb.SubmitJobRequest
+	(*SubmitJobResponse)(nil),          // 28: raftpb.SubmitJobResponse
+	(*GetJobRequest)(nil),              // 29: raftpb.GetJobRequest
+	(*GetJobResponse)(nil),             // 30: raftpb.GetJobResponse
+	(*ListJobsRequest)(nil),            // 31: raftpb.ListJobsRequest
+	(*ListJobsResponse)(nil),           // 32: raftpb.ListJobsResponse
+	(*CancelJobRequest)(nil),           // 33: raftpb.CancelJobRequest
+	(*CancelJobResponse)(nil),          // 34: raftpb.CancelJobResponse
+	(*HeartbeatRequest)(nil),           // 35: raftpb.HeartbeatRequest
+	(*HeartbeatResponse)(nil),          // 36: raftpb.HeartbeatResponse
+	(*PollTaskRequest)(nil),            // 37: raftpb.PollTaskRequest
+	(*PollTaskResponse)(nil),           // 38: raftpb.PollTaskResponse
+	(*ReportTaskResultRequest)(nil),    // 39: raftpb.ReportTaskResultRequest
+	(*ReportTaskResultResponse)(nil),   // 40: raftpb.ReportTaskResultResponse
+	(*ReportTaskProgressRequest)(nil),  // 41: raftpb.ReportTaskProgressRequest
+	(*ReportTaskProgressResponse)(nil), // 42: raftpb.ReportTaskProgressResponse
+	(*RegisterServerRequest)(nil),      // 43: raftpb.RegisterServerRequest
+	(*RegisterServerResponse)(nil),     // 44: raftpb.RegisterServerResponse
+	(*GetServerInfoRequest)(nil),       // 45: raftpb.GetServerInfoRequest
+	(*GetServerInfoResponse)(nil),      // 46: raftpb.GetServerInfoResponse
+	nil,                                // 47: raftpb.TaskProgress.MetricsEntry
+	nil,                                // 48: raftpb.Node.LabelsEntry
+	nil,                                // 49: raftpb.ListTasksResponse.UnschedulableEntry
+	nil,                                // 50: raftpb.HeartbeatRequest.LabelsEntry
+	nil,                                // 51: raftpb.ReportTaskProgressRequest.MetricsEntry
 }
 var file_raft_proto_depIdxs = []int32{
-	0,  // 0: raftpb.Task.status:type_name -> raftpb.TaskStatus
-	1,  // 1: raftpb.Node.status:type_name -> raftpb.NodeStatus
-	2,  // 2: raftpb.GetTaskResponse.task:type_name -> raftpb.Task
-	0,  // 3: raftpb.ListTasksRequest.status_filter:type_name -> raftpb.TaskStatus
-	2,  // 4: raftpb.ListTasksResponse.tasks:type_name -> raftpb.Task
-	2,  // 5: raftpb.PollTaskResponse.task:type_name -> raftpb.Task
-	0,  // 6: raftpb.ReportTaskResultRequest.final_status:type_name -> raftpb.TaskStatus
-	4,  // 7: raftpb.TaskService.SubmitTask:input_type -> raftpb.SubmitTaskRequest
-	6,  // 8: raftpb.TaskService.GetTask:input_type -> raftpb.GetTaskRequest
-	8,  // 9: raftpb.TaskService.ListTasks:input_type -> raftpb.ListTasksRequest
-	10, // 10: raftpb.NodeService.Heartbeat:input_type -> raftpb.HeartbeatRequest
-	12, // 11: raftpb.NodeService.PollTask:input_type -> raftpb.PollTaskRequest
-	14, // 12: raftpb.NodeService.ReportTaskResult:input_type -> raftpb.ReportTaskResultRequest
-	5,  // 13: raftpb.TaskService.SubmitTask:output_type -> raftpb.SubmitTaskResponse
-	7,  // 14: raftpb.TaskService.GetTask:output_type -> raftpb.GetTaskResponse
-	9,  // 15: raftpb.TaskService.ListTasks:output_type -> raftpb.ListTasksResponse
-	11, // 16: raftpb.NodeService.Heartbeat:output_type -> raftpb.HeartbeatResponse
-	13, // 17: raftpb.NodeService.PollTask:output_type -> raftpb.PollTaskResponse
-	15, // 18: raftpb.NodeService.ReportTaskResult:output_type -> raftpb.ReportTaskResultResponse
-	13, // [13:19] is the sub-list for method output_type
-	7,  // [7:13] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	1,  // 0: raftpb.Task.status:type_name -> raftpb.TaskStatus
+	12, // 1: raftpb.Task.last_failure:type_name -> raftpb.TaskFailure
+	11, // 2: raftpb.Task.retry_policy:type_name -> raftpb.RetryPolicy
+	9,  // 3: raftpb.Task.resources:type_name -> raftpb.Resources
+	6,  // 4: raftpb.Task.affinity:type_name -> raftpb.Affinity
+	5,  // 5: raftpb.Task.progress:type_name -> raftpb.TaskProgress
+	12, // 6: raftpb.Task.last_preemption:type_name -> raftpb.TaskFailure
+	47, // 7: raftpb.TaskProgress.metrics:type_name -> raftpb.TaskProgress.MetricsEntry
+	7,  // 8: raftpb.Affinity.required:type_name -> raftpb.LabelExpression
+	7,  // 9: raftpb.Affinity.required_anti:type_name -> raftpb.LabelExpression
+	8,  // 10: raftpb.Affinity.preferred:type_name -> raftpb.WeightedLabelExpression
+	8,  // 11: raftpb.Affinity.preferred_anti:type_name -> raftpb.WeightedLabelExpression
+	0,  // 12: raftpb.LabelExpression.operator:type_name -> raftpb.LabelOperator
+	7,  // 13: raftpb.WeightedLabelExpression.expression:type_name -> raftpb.LabelExpression
+	2,  // 14: raftpb.Job.status:type_name -> raftpb.JobStatus
+	14, // 15: raftpb.Job.progress:type_name -> raftpb.JobProgress
+	3,  // 16: raftpb.Node.status:type_name -> raftpb.NodeStatus
+	9,  // 17: raftpb.Node.capacity:type_name -> raftpb.Resources
+	9,  // 18: raftpb.Node.allocatable:type_name -> raftpb.Resources
+	9,  // 19: raftpb.Node.allocated:type_name -> raftpb.Resources
+	48, // 20: raftpb.Node.labels:type_name -> raftpb.Node.LabelsEntry
+	11, // 21: raftpb.SubmitTaskRequest.retry_policy:type_name -> raftpb.RetryPolicy
+	9,  // 22: raftpb.SubmitTaskRequest.resources:type_name -> raftpb.Resources
+	6,  // 23: raftpb.SubmitTaskRequest.affinity:type_name -> raftpb.Affinity
+	17, // 24: raftpb.SubmitTasksRequest.tasks:type_name -> raftpb.SubmitTaskRequest
+	4,  // 25: raftpb.GetTaskResponse.task:type_name -> raftpb.Task
+	1,  // 26: raftpb.ListTasksRequest.status_filter:type_name -> raftpb.TaskStatus
+	4,  // 27: raftpb.ListTasksResponse.tasks:type_name -> raftpb.Task
+	49, // 28: raftpb.ListTasksResponse.unschedulable:type_name -> raftpb.ListTasksResponse.UnschedulableEntry
+	1,  // 29: raftpb.CancelTaskResponse.status:type_name -> raftpb.TaskStatus
+	17, // 30: raftpb.SubmitJobRequest.tasks:type_name -> raftpb.SubmitTaskRequest
+	13, // 31: raftpb.GetJobResponse.job:type_name -> raftpb.Job
+	2,  // 32: raftpb.ListJobsRequest.status_filter:type_name -> raftpb.JobStatus
+	13, // 33: raftpb.ListJobsResponse.jobs:type_name -> raftpb.Job
+	10, // 34: raftpb.HeartbeatRequest.held_leases:type_name -> raftpb.TaskLease
+	9,  // 35: raftpb.HeartbeatRequest.capacity:type_name -> raftpb.Resources
+	9,  // 36: raftpb.HeartbeatRequest.allocatable:type_name -> raftpb.Resources
+	50, // 37: raftpb.HeartbeatRequest.labels:type_name -> raftpb.HeartbeatRequest.LabelsEntry
+	4,  // 38: raftpb.PollTaskResponse.task:type_name -> raftpb.Task
+	1,  // 39: raftpb.ReportTaskResultRequest.final_status:type_name -> raftpb.TaskStatus
+	51, // 40: raftpb.ReportTaskProgressRequest.metrics:type_name -> raftpb.ReportTaskProgressRequest.MetricsEntry
+	16, // 41: raftpb.RegisterServerRequest.server:type_name -> raftpb.ServerInfo
+	16, // 42: raftpb.GetServerInfoResponse.server:type_name -> raftpb.ServerInfo
+	17, // 43: raftpb.TaskService.SubmitTask:input_type -> raftpb.SubmitTaskRequest
+	19, // 44: raftpb.TaskService.SubmitTasks:input_type -> raftpb.SubmitTasksRequest
+	21, // 45: raftpb.TaskService.GetTask:input_type -> raftpb.GetTaskRequest
+	23, // 46: raftpb.TaskService.ListTasks:input_type -> raftpb.ListTasksRequest
+	25, // 47: raftpb.TaskService.CancelTask:input_type -> raftpb.CancelTaskRequest
+	27, // 48: raftpb.JobService.SubmitJob:input_type -> raftpb.SubmitJobRequest
+	29, // 49: raftpb.JobService.GetJob:input_type -> raftpb.GetJobRequest
+	31, // 50: raftpb.JobService.ListJobs:input_type -> raftpb.ListJobsRequest
+	33, // 51: raftpb.JobService.CancelJob:input_type -> raftpb.CancelJobRequest
+	35, // 52: raftpb.NodeService.Heartbeat:input_type -> raftpb.HeartbeatRequest
+	37, // 53: raftpb.NodeService.PollTask:input_type -> raftpb.PollTaskRequest
+	39, // 54: raftpb.NodeService.ReportTaskResult:input_type -> raftpb.ReportTaskResultRequest
+	41, // 55: raftpb.NodeService.ReportTaskProgress:input_type -> raftpb.ReportTaskProgressRequest
+	43, // 56: raftpb.ClusterService.RegisterServer:input_type -> raftpb.RegisterServerRequest
+	45, // 57: raftpb.ClusterService.GetServerInfo:input_type -> raftpb.GetServerInfoRequest
+	18, // 58: raftpb.TaskService.SubmitTask:output_type -> raftpb.SubmitTaskResponse
+	20, // 59: raftpb.TaskService.SubmitTasks:output_type -> raftpb.SubmitTasksResponse
+	22, // 60: raftpb.TaskService.GetTask:output_type -> raftpb.GetTaskResponse
+	24, // 61: raftpb.TaskService.ListTasks:output_type -> raftpb.ListTasksResponse
+	26, // 62: raftpb.TaskService.CancelTask:output_type -> raftpb.CancelTaskResponse
+	28, // 63: raftpb.JobService.SubmitJob:output_type -> raftpb.SubmitJobResponse
+	30, // 64: raftpb.JobService.GetJob:output_type -> raftpb.GetJobResponse
+	32, // 65: raftpb.JobService.ListJobs:output_type -> raftpb.ListJobsResponse
+	34, // 66: raftpb.JobService.CancelJob:output_type -> raftpb.CancelJobResponse
+	36, // 67: raftpb.NodeService.Heartbeat:output_type -> raftpb.HeartbeatResponse
+	38, // 68: raftpb.NodeService.PollTask:output_type -> raftpb.PollTaskResponse
+	40, // 69: raftpb.NodeService.ReportTaskResult:output_type -> raftpb.ReportTaskResultResponse
+	42, // 70: raftpb.NodeService.ReportTaskProgress:output_type -> raftpb.ReportTaskProgressResponse
+	44, // 71: raftpb.ClusterService.RegisterServer:output_type -> raftpb.RegisterServerResponse
+	46, // 72: raftpb.ClusterService.GetServerInfo:output_type -> raftpb.GetServerInfoResponse
+	58, // [58:73] is the sub-list for method output_type
+	43, // [43:58] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
 }
 
 func init() { file_raft_proto_init() }
@@ -1120,15 +3688,17 @@ func file_raft_proto_init() {
 	if File_raft_proto != nil {
 		return
 	}
+	file_raft_proto_msgTypes[19].OneofWrappers = []any{}
+	file_raft_proto_msgTypes[27].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_raft_proto_rawDesc), len(file_raft_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   14,
+			NumEnums:      4,
+			NumMessages:   48,
 			NumExtensions: 0,
-			NumServices:   2,
+			NumServices:   4,
 		},
 		GoTypes:           file_raft_proto_goTypes,
 		DependencyIndexes: file_raft_proto_depIdxs,
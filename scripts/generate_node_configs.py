@@ -24,11 +24,11 @@ def get_terraform_outputs():
 
     return json.loads(result.stdout)
 
-def generate_node_config(node_id, ip_address, cloud_provider, all_peer_ips, app_port=8080):
+def generate_node_config(node_id, ip_address, cloud_provider, all_nodes, app_port=8080):
     """Generate configuration for a single node"""
 
-    # Remove self from peers
-    peers = [f"{peer_ip}:{app_port}" for peer_ip in all_peer_ips if peer_ip != ip_address]
+    # Remove self from peers; peers are "node_id@ip:port" so bootstrap uses real IDs
+    peers = [f"{peer_id}@{peer_ip}:{app_port}" for peer_id, peer_ip in all_nodes if peer_id != node_id]
 
     config = {
         "node_id": node_id,
@@ -37,7 +37,7 @@ def generate_node_config(node_id, ip_address, cloud_provider, all_peer_ips, app_
         "cloud_provider": cloud_provider,
         "region": "us-east-1" if cloud_provider == "aws" else "us-central1",
         "data_dir": "/var/lib/raft",
-        "bootstrap_expect": 5,  # Total nodes in cluster
+        "bootstrap_expect": len(all_nodes),  # Total nodes in cluster
         "peers": peers,
         "raft": {
             "heartbeat_timeout": "1s",
@@ -60,7 +60,8 @@ def main():
     aws_ips = outputs['aws_instance_public_ips']['value']
     gcp_ips = outputs['gcp_instance_public_ips']['value']
 
-    all_ips = aws_ips + gcp_ips
+    all_nodes = [(f"aws-node-{i}", ip) for i, ip in enumerate(aws_ips, start=1)] + \
+                [(f"gcp-node-{i}", ip) for i, ip in enumerate(gcp_ips, start=1)]
 
     # Create configs directory
     config_dir = Path("config/nodes")
@@ -71,7 +72,7 @@ def main():
     # Generate AWS node configs
     for i, ip in enumerate(aws_ips, start=1):
         node_id = f"aws-node-{i}"
-        config = generate_node_config(node_id, ip, "aws", all_ips)
+        config = generate_node_config(node_id, ip, "aws", all_nodes)
 
         config_file = config_dir / f"{node_id}.json"
         with open(config_file, 'w') as f:
@@ -83,7 +84,7 @@ def main():
     # Generate GCP node configs
     for i, ip in enumerate(gcp_ips, start=1):
         node_id = f"gcp-node-{i}"
-        config = generate_node_config(node_id, ip, "gcp", all_ips)
+        config = generate_node_config(node_id, ip, "gcp", all_nodes)
 
         config_file = config_dir / f"{node_id}.json"
         with open(config_file, 'w') as f: